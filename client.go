@@ -8,19 +8,33 @@
 // Basic usage:
 //
 //	client, err := schwabdev.NewClient(appKey, appSecret, callbackURL, "", "", 0, nil)
-//	streamer := stream.NewStreamer(logger, client.TokenManager(), infoSrc)
+//	streamer := schwabdev.NewStreamer(logger, client.TokenManager(), client.InfoSource())
 package schwabdev
 
 import (
 	"bytes"
+	"cmp"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
+	"net"
 	"net/http"
 	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 )
 
@@ -31,7 +45,57 @@ type Client struct {
 	httpClient   *http.Client
 	baseURL      string
 	logger       *slog.Logger
-	timeout      time.Duration
+
+	// timeout bounds each HTTP attempt (stored as nanoseconds so it can be
+	// changed while requests are in flight). Zero means no per-request limit.
+	timeout atomic.Int64
+
+	// maxAttempts is the total number of attempts made for a request that is
+	// rejected with a retryable status. Values below 2 disable retrying.
+	maxAttempts int
+
+	// networkAttempts is the total number of attempts made for a request that
+	// fails with a transient network error, for the methods listed in
+	// networkRetryMethods. Values below 2 disable retrying.
+	networkAttempts     int
+	networkRetryMethods []string
+
+	// quotesBatchSize is the maximum number of symbols sent in a single
+	// quotes request. Zero means DefaultQuotesBatchSize.
+	quotesBatchSize int
+
+	// rawSymbols disables symbol normalization; see WithSymbolNormalization.
+	rawSymbols bool
+
+	// quoteCache serves repeat Quotes/Quote calls while fresh. Nil when
+	// disabled (the default); see EnableQuoteCache.
+	quoteCache atomic.Pointer[quoteCache]
+
+	// defaultHeaders are sent with every request; see SetDefaultHeaders.
+	defaultHeaders atomic.Pointer[http.Header]
+
+	// limiter paces requests; nil when unlimited (the default). See
+	// SetRateLimit.
+	limiter atomic.Pointer[rateLimiter]
+
+	// observer is told about every completed API call; see SetObserver.
+	observer atomic.Pointer[Observer]
+
+	// succeeded decides which statuses are decoded rather than returned as
+	// an *APIError; nil means below 400. See SetSuccessPredicate.
+	succeeded atomic.Pointer[func(status int) bool]
+
+	// placed remembers PlaceOrderIdempotent keys for IdempotencyWindow.
+	placed placedOrders
+
+	// logUnredacted turns off masking of account identifiers in log lines;
+	// see SetLogRedaction.
+	logUnredacted atomic.Bool
+
+	// accountHashes caches account number → hash. It is filled on the first
+	// AccountHashFor call and replaced by RefreshAccountHashes.
+	hashMu        sync.Mutex
+	accountHashes map[string]string
 }
 
 // NewClient creates a new Client instance for accessing the Schwab API.
@@ -42,12 +106,13 @@ type Client struct {
 //   - callbackURL: OAuth callback URL (must be HTTPS, cannot end with /)
 //   - storagePath: Path to token JSON file (default: ~/.schwabdev/tokens.json)
 //   - encryption: Optional Fernet encryption key for token storage
-//   - timeout: HTTP request timeout (use 0 for DefaultHTTPRequestTimeout)
+//   - timeout: Per-request timeout (use 0 for DefaultHTTPRequestTimeout; see SetRequestTimeout)
 //   - callOnAuth: Optional callback — receives auth URL, returns callback URL after
 //     the user completes the OAuth flow. Pass nil to fall back to stdin prompt.
+//   - opts: Optional ClientOption values (e.g. WithRetry) applied after defaults.
 //
 // Returns *Client and error if validation or initialization fails.
-func NewClient(appKey, appSecret, callbackURL, storagePath, encryption string, timeout time.Duration, callOnAuth func(authURL string) (string, error)) (*Client, error) {
+func NewClient(appKey, appSecret, callbackURL, storagePath, encryption string, timeout time.Duration, callOnAuth func(authURL string) (string, error), opts ...ClientOption) (*Client, error) {
 	// Validate timeout
 	if timeout <= 0 {
 		timeout = DefaultHTTPRequestTimeout
@@ -62,10 +127,11 @@ func NewClient(appKey, appSecret, callbackURL, storagePath, encryption string, t
 		return nil, err
 	}
 
-	// Create HTTP client with timeout
-	httpClient := &http.Client{
-		Timeout: timeout,
-	}
+	// The per-request timeout is applied through the request context rather
+	// than http.Client.Timeout so that SetRequestTimeout can change it later.
+	// The transport is the client's own so CloseIdleConnections does not
+	// affect other users of http.DefaultTransport.
+	httpClient := &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
 
 	// Create Client instance
 	client := &Client{
@@ -73,7 +139,11 @@ func NewClient(appKey, appSecret, callbackURL, storagePath, encryption string, t
 		httpClient:   httpClient,
 		baseURL:      "https://api.schwabapi.com",
 		logger:       logger,
-		timeout:      timeout,
+	}
+	client.timeout.Store(int64(timeout))
+
+	for _, opt := range opts {
+		opt(client)
 	}
 
 	// Ensure tokens are up to date on init
@@ -97,11 +167,7 @@ func (c *Client) Close() error {
 		}
 	}
 
-	if c.httpClient != nil {
-		if transport, ok := c.httpClient.Transport.(*http.Transport); ok {
-			transport.CloseIdleConnections()
-		}
-	}
+	c.CloseIdleConnections()
 
 	if len(errs) > 0 {
 		return errs[0]
@@ -109,6 +175,15 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// CloseIdleConnections closes HTTP connections kept alive for reuse. It is
+// safe to call at any time, including once at shutdown by each service that
+// shares the Client: later requests simply open new connections.
+func (c *Client) CloseIdleConnections() {
+	if c.httpClient != nil {
+		c.httpClient.CloseIdleConnections()
+	}
+}
+
 // UpdateTokens updates the access and refresh tokens if needed.
 // Set forceAccessToken or forceRefreshToken to true to force an update.
 // Returns true if tokens were updated, false otherwise.
@@ -116,10 +191,23 @@ func (c *Client) UpdateTokens(forceAccessToken, forceRefreshToken bool) (bool, e
 	return c.tokenManager.UpdateTokens(forceAccessToken, forceRefreshToken)
 }
 
+// SetRequestTimeout changes the timeout applied to each HTTP attempt made by
+// the API methods. A value of 0 disables the per-request timeout so requests
+// are bounded only by the caller's context. Safe to call concurrently.
+func (c *Client) SetRequestTimeout(d time.Duration) {
+	c.timeout.Store(int64(max(d, 0)))
+}
+
+// RequestTimeout returns the timeout currently applied to each HTTP attempt.
+// Zero means requests rely solely on the caller's context.
+func (c *Client) RequestTimeout() time.Duration {
+	return time.Duration(c.timeout.Load())
+}
+
 // TokenManager returns the underlying TokenManager, which satisfies the
-// stream.TokenProvider interface. Use this to wire the streamer:
+// TokenProvider interface. Use this to wire the streamer:
 //
-//	streamer := stream.NewStreamer(logger, client.TokenManager(), infoSrc)
+//	streamer := schwabdev.NewStreamer(logger, client.TokenManager(), client.InfoSource())
 func (c *Client) TokenManager() *TokenManager {
 	return c.tokenManager
 }
@@ -150,9 +238,176 @@ func (c *Client) authHeader(ctx context.Context) (string, error) {
 	return fmt.Sprintf("Bearer %s", accessToken), nil
 }
 
+// requestIDKey is the context key under which WithRequestID stores the ID.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id as the correlation ID for
+// API calls made with it. The ID is attached to every log line the Client
+// emits for the call and to the error it returns. Calls made without one get
+// a random UUID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the correlation ID set by WithRequestID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// headersKey is the context key under which WithHeaders stores headers.
+type headersKey struct{}
+
+// WithHeaders returns a copy of ctx carrying headers to send with API calls
+// made with it. They are merged over the client's default headers, replacing
+// any default of the same name, and over headers from an outer WithHeaders.
+func WithHeaders(ctx context.Context, headers map[string]string) context.Context {
+	merged := http.Header{}
+	if outer, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		merged = outer.Clone()
+	}
+	for k, v := range headers {
+		merged.Set(k, v)
+	}
+	return context.WithValue(ctx, headersKey{}, merged)
+}
+
+// SetDefaultHeaders replaces the headers sent with every API call, e.g. a
+// custom User-Agent or tracing headers required by a proxy. Per-call headers
+// from WithHeaders take precedence. Authorization, and Content-Type on
+// requests with a body, are always set by the client.
+func (c *Client) SetDefaultHeaders(headers map[string]string) {
+	h := make(http.Header, len(headers))
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	c.defaultHeaders.Store(&h)
+}
+
+// Observer receives one report per API call: the HTTP method, the full
+// request URL, the final response status (0 if no response was received),
+// the total time taken including retries, and the error returned to the
+// caller, if any.
+type Observer func(method, url string, status int, dur time.Duration, err error)
+
+// SetObserver registers fn to be called after every API call, e.g. to record
+// latency and status metrics. It runs synchronously on the calling
+// goroutine, so it should return quickly. A nil fn removes the observer.
+func (c *Client) SetObserver(fn Observer) {
+	if fn == nil {
+		c.observer.Store(nil)
+		return
+	}
+	c.observer.Store(&fn)
+}
+
+// SetSuccessPredicate replaces the test deciding whether a response status
+// is a success, whose body is decoded into the result, or a failure,
+// returned as an error wrapping *APIError. The default treats every status
+// below 400 as a success; a deployment behind a proxy that answers 202 for
+// requests it merely queued might count that as a failure, for example. A
+// 401 still triggers a token refresh and 429 and 503 are still retried
+// before fn is consulted. A nil fn restores the default.
+func (c *Client) SetSuccessPredicate(fn func(status int) bool) {
+	if fn == nil {
+		c.succeeded.Store(nil)
+		return
+	}
+	c.succeeded.Store(&fn)
+}
+
+// isSuccess applies the success predicate to status.
+func (c *Client) isSuccess(status int) bool {
+	if fn := c.succeeded.Load(); fn != nil {
+		return (*fn)(status)
+	}
+	return status < http.StatusBadRequest
+}
+
+// responseHeadersKey is the context key under which WithResponseHeaders
+// stores its recorder.
+type responseHeadersKey struct{}
+
+// ResponseHeaders records the headers of the last response received for API
+// calls made with a context from WithResponseHeaders. It is safe for
+// concurrent use, e.g. by the chunked requests of a large Quotes call.
+type ResponseHeaders struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+// WithResponseHeaders returns a copy of ctx that records the headers of each
+// response to API calls made with it, such as Schwab-Client-CorrelId for
+// support requests, and the recorder to read them from after the call:
+//
+//	ctx, headers := schwabdev.WithResponseHeaders(ctx)
+//	quotes, err := client.Quotes(ctx, "AAPL", nil, nil)
+//	log.Println("correlation ID", headers.CorrelID())
+func WithResponseHeaders(ctx context.Context) (context.Context, *ResponseHeaders) {
+	rh := &ResponseHeaders{}
+	return context.WithValue(ctx, responseHeadersKey{}, rh), rh
+}
+
+// Header returns a copy of the headers of the last response received, or nil
+// if no response has arrived.
+func (r *ResponseHeaders) Header() http.Header {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header.Clone()
+}
+
+// CorrelID returns the Schwab-Client-CorrelId header of the last response.
+func (r *ResponseHeaders) CorrelID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header.Get("Schwab-Client-CorrelId")
+}
+
+func (r *ResponseHeaders) record(h http.Header) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.header = h.Clone()
+}
+
+// setHeaders applies the default and per-call headers to req.
+func (c *Client) setHeaders(ctx context.Context, req *http.Request) {
+	if defaults := c.defaultHeaders.Load(); defaults != nil {
+		for k, v := range *defaults {
+			req.Header[k] = slices.Clone(v)
+		}
+	}
+	if perCall, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		for k, v := range perCall {
+			req.Header[k] = slices.Clone(v)
+		}
+	}
+}
+
+// newRequestID returns a random (version 4) UUID.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// requestLogger returns c.logger annotated with the request ID in ctx. Unless
+// redaction is off, account identifiers in path are masked in everything it
+// logs.
+func (c *Client) requestLogger(ctx context.Context, path string) *slog.Logger {
+	id, _ := RequestIDFromContext(ctx)
+	logger := c.logger
+	if ids := accountIDs(path); len(ids) > 0 && !c.logUnredacted.Load() {
+		logger = slog.New(redactingHandler{logger.Handler(), ids})
+	}
+	return logger.With("request_id", id)
+}
+
 // request is a private method that makes HTTP requests to the Schwab API.
 // It handles token updates, authorization headers, request body marshaling,
-// and response parsing.
+// and response parsing. Every call is tagged with a request ID (see
+// WithRequestID) that appears in its log lines and returned error.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
@@ -161,13 +416,93 @@ func (c *Client) authHeader(ctx context.Context) (string, error) {
 //   - body: Request body (will be marshaled to JSON, can be nil)
 //   - result: Response body destination (will be unmarshaled from JSON, can be nil)
 //
-// Returns the HTTP response and any error that occurred.
+// Returns the HTTP response and any error that occurred. The response body has
+// already been read and closed; resp.Body holds an in-memory copy, so callers
+// never need to close it.
 func (c *Client) request(ctx context.Context, method, path string, body, result any) (*http.Response, error) {
-	return c.doRequest(ctx, method, path, body, result, false)
+	id, ok := RequestIDFromContext(ctx)
+	if !ok {
+		id = newRequestID()
+		ctx = WithRequestID(ctx, id)
+	}
+	logger := c.requestLogger(ctx, path)
+	logger.Debug("Sending request", "method", method, "path", path)
+
+	start := time.Now()
+	resp, err := c.send(ctx, logger, method, path, body, result)
+	if rh, ok := ctx.Value(responseHeadersKey{}).(*ResponseHeaders); ok && resp != nil {
+		rh.record(resp.Header)
+	}
+	if observe := c.observer.Load(); observe != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		(*observe)(method, c.baseURL+path, status, time.Since(start), err)
+	}
+	if err != nil {
+		logger.Warn("Request failed", "method", method, "path", path, "error", err)
+		return resp, fmt.Errorf("request %s: %w", id, err)
+	}
+	return resp, nil
+}
+
+// send performs the request, retrying throttled attempts when enabled.
+func (c *Client) send(ctx context.Context, logger *slog.Logger, method, path string, body, result any) (*http.Response, error) {
+	// Marshal once so the same bytes can be replayed on every attempt.
+	var payload []byte
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = jsonBody
+	}
+
+	for attempt := 1; ; attempt++ {
+		if limiter := c.limiter.Load(); limiter != nil {
+			if err := limiter.wait(ctx); err != nil {
+				return nil, fmt.Errorf("rate limit wait: %w", err)
+			}
+		}
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout := c.RequestTimeout(); timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		resp, err := c.doRequest(attemptCtx, method, path, payload, result, false)
+		cancel()
+
+		var wait time.Duration
+		switch {
+		case attempt < c.maxAttempts && isRetryableStatus(resp):
+			wait = retryAfter(resp.Header.Get("Retry-After"), time.Now())
+			logger.Debug("Request throttled, retrying",
+				"status", resp.StatusCode, "attempt", attempt, "retry_in", wait)
+		case attempt < c.networkAttempts && slices.Contains(c.networkRetryMethods, method) &&
+			ctx.Err() == nil && isTransientError(err):
+			wait = networkBackoff(attempt)
+			logger.Debug("Transient network error, retrying",
+				"error", err, "attempt", attempt, "retry_in", wait)
+		default:
+			return resp, err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			// Waiting would outlive the caller's deadline; surface the error now.
+			return resp, err
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return resp, err
+		}
+	}
 }
 
 // doRequest executes the HTTP request with optional retry on 401 Unauthorized.
-func (c *Client) doRequest(ctx context.Context, method, path string, body, result any, isRetry bool) (*http.Response, error) {
+// body is the already-marshaled JSON payload, or nil for no body.
+func (c *Client) doRequest(ctx context.Context, method, path string, body []byte, result any, isRetry bool) (*http.Response, error) {
 	authHeader, err := c.authHeader(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get auth header: %w", err)
@@ -175,13 +510,11 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body, resul
 
 	fullURL := c.baseURL + path
 
+	// A *bytes.Reader lets NewRequestWithContext set req.GetBody, so the
+	// body is replayed if the server redirects the request.
 	var reqBody io.Reader
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		reqBody = bytes.NewReader(jsonBody)
+		reqBody = bytes.NewReader(body)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
@@ -189,12 +522,16 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body, resul
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	c.setHeaders(ctx, req)
 	req.Header.Set("Authorization", authHeader)
 
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
+	if sent, ok := ctx.Value(requestSentKey{}).(*atomic.Bool); ok && ctx.Err() == nil {
+		sent.Store(true)
+	}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -203,9 +540,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body, resul
 	if resp.StatusCode == http.StatusUnauthorized && !isRetry {
 		resp.Body.Close()
 
-		if c.logger != nil {
-			c.logger.Debug("Received 401 Unauthorized, forcing token refresh and retrying")
-		}
+		c.requestLogger(ctx, path).Debug("Received 401 Unauthorized, forcing token refresh and retrying")
 
 		if _, err := c.tokenManager.UpdateTokens(true, false); err != nil {
 			return nil, fmt.Errorf("failed to refresh token after 401: %w", err)
@@ -214,26 +549,192 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body, resul
 		return c.doRequest(ctx, method, path, body, result, true)
 	}
 
-	if result != nil && resp.Body != nil {
-		defer resp.Body.Close()
+	defer resp.Body.Close()
 
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response body: %w", err)
-		}
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("%w: %w", ErrTruncatedResponse, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	bodyBytes, err = decodeContentEncoding(resp, bodyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 
-		resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+	if !c.isSuccess(resp.StatusCode) {
+		return resp, newAPIError(resp.StatusCode, bodyBytes)
+	}
 
-		if len(bodyBytes) > 0 {
-			if err := json.Unmarshal(bodyBytes, result); err != nil {
-				c.logger.Debug("Failed to unmarshal response body", "error", err, "status", resp.StatusCode)
+	// An empty body, as on a 204, leaves result at its zero value.
+	if result != nil && len(bytes.TrimSpace(bodyBytes)) > 0 {
+		if err := json.Unmarshal(bodyBytes, result); err != nil {
+			if isTruncatedJSON(err, bodyBytes) {
+				return resp, fmt.Errorf("%w: %d bytes", ErrTruncatedResponse, len(bodyBytes))
 			}
+			c.requestLogger(ctx, path).Debug("Failed to unmarshal response body", "error", err, "status", resp.StatusCode)
 		}
 	}
 
 	return resp, nil
 }
 
+// decodeContentEncoding undoes a gzip or deflate Content-Encoding on a
+// response body. net/http only does this itself when it asked for gzip, not
+// when a proxy or a caller's Accept-Encoding header put the encoding there.
+func decodeContentEncoding(resp *http.Response, body []byte) ([]byte, error) {
+	if len(body) == 0 {
+		return body, nil
+	}
+	var r io.Reader
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	case "deflate":
+		// "deflate" should be zlib-wrapped, but some servers send raw DEFLATE.
+		if zr, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+			r = zr
+		} else {
+			r = flate.NewReader(bytes.NewReader(body))
+		}
+	default:
+		return body, nil
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return decoded, nil
+}
+
+// isTruncatedJSON reports whether err, from unmarshaling body, means the
+// document stopped short rather than being malformed part-way through.
+func isTruncatedJSON(err error, body []byte) bool {
+	var syntaxErr *json.SyntaxError
+	return errors.As(err, &syntaxErr) && syntaxErr.Offset >= int64(len(bytes.TrimSpace(body)))
+}
+
+// isRetryableStatus reports whether resp was rejected with a status that is
+// worth retrying after a pause: 429 Too Many Requests or 503 Service Unavailable.
+func isRetryableStatus(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusServiceUnavailable
+}
+
+// isTransientError reports whether err is a network failure that may succeed
+// on a fresh attempt: a timeout, a reset or refused connection, or a
+// connection closed before the response arrived.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// networkBackoff returns the wait before retrying after the given attempt
+// failed with a transient network error: NetworkRetryBaseDelay doubled per
+// attempt, capped at NetworkRetryMaxDelay.
+func networkBackoff(attempt int) time.Duration {
+	return min(NetworkRetryBaseDelay<<(attempt-1), NetworkRetryMaxDelay)
+}
+
+// retryAfter parses a Retry-After header value, which is either a number of
+// seconds or an HTTP-date. It falls back to DefaultRetryAfter when the header
+// is missing or malformed.
+func retryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return DefaultRetryAfter
+	}
+	if secs, err := strconv.Atoi(header); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return DefaultRetryAfter
+}
+
+// newAPIError builds an *APIError from a failed response. The body is decoded
+// as an ErrorResponse, or an array of them, on a best-effort basis; the raw
+// bytes are always kept.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: statusCode,
+		Body:       body,
+		Errors:     decodeErrorResponses(body),
+	}
+
+	if len(apiErr.Errors) > 0 {
+		first := apiErr.Errors[0]
+		apiErr.Code = first.Code
+		apiErr.Message = first.Message
+		if apiErr.Message == "" {
+			apiErr.Message = first.Description
+		}
+	}
+
+	return apiErr
+}
+
+// decodeErrorResponses decodes an error body that is either a single error
+// object or a top-level array of them. It returns nil if the body is neither.
+func decodeErrorResponses(body []byte) []ErrorResponse {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil
+	}
+	if trimmed[0] == '[' {
+		var errs []ErrorResponse
+		if json.Unmarshal(trimmed, &errs) != nil || len(errs) == 0 {
+			return nil
+		}
+		return errs
+	}
+	var errResp ErrorResponse
+	if json.Unmarshal(trimmed, &errResp) != nil {
+		return nil
+	}
+	return []ErrorResponse{errResp}
+}
+
+// isNullBody reports whether a successful response carried no JSON value,
+// i.e. an empty body or a literal null. Schwab answers lookups for unknown
+// IDs this way instead of returning 404.
+func isNullBody(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null"))
+}
+
 // parseParams filters out nil values from params and converts to url.Values.
 // This matches Python's _parse_params() behavior which removes None values.
 //
@@ -241,16 +742,65 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body, resul
 //   - params: Map of parameter names to values (values can be nil)
 //
 // Returns url.Values containing only non-nil parameters converted to strings.
+// Pointer values are dereferenced; nil pointers are treated like nil.
 func (c *Client) parseParams(params map[string]any) url.Values {
 	result := url.Values{}
 	for key, value := range params {
-		if value != nil {
-			result.Set(key, fmt.Sprintf("%v", value))
+		if value == nil {
+			continue
+		}
+		if v := reflect.ValueOf(value); v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				continue
+			}
+			value = v.Elem().Interface()
 		}
+		result.Set(key, fmt.Sprintf("%v", value))
 	}
 	return result
 }
 
+// EncodeSymbol escapes symbol for use as one URL path segment. Slashes in
+// futures ("/ES") and forex ("EUR/USD") symbols become %2F and the padding
+// spaces of option symbols ("AAPL  240809C00095000") become %20, so the
+// symbol never splits into extra path segments. Client methods that put a
+// symbol in the path apply it already. Query parameters are escaped by
+// url.Values and streamer keys are sent verbatim, so neither needs it.
+func EncodeSymbol(symbol string) string {
+	return url.PathEscape(symbol)
+}
+
+// NormalizeSymbol trims surrounding whitespace from symbol and uppercases it,
+// so " brk.b " becomes "BRK.B". Inner spaces, such as the padding of option
+// symbols ("AAPL  240809C00095000"), and the punctuation of futures ("/ES")
+// and indices ("$SPX") are kept.
+func NormalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// symbol applies NormalizeSymbol unless normalization is disabled.
+func (c *Client) symbol(symbol string) string {
+	if c.rawSymbols {
+		return symbol
+	}
+	return NormalizeSymbol(symbol)
+}
+
+// symbolList normalizes each symbol of a comma-separated list, dropping empty
+// entries, unless normalization is disabled.
+func (c *Client) symbolList(list string) string {
+	if c.rawSymbols || list == "" {
+		return list
+	}
+	var out []string
+	for sym := range strings.SplitSeq(list, ",") {
+		if sym = NormalizeSymbol(sym); sym != "" {
+			out = append(out, sym)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
 // formatList converts a list to a comma-separated string.
 // This matches Python's _format_list() behavior exactly:
 //   - Returns empty string if list is nil
@@ -302,12 +852,56 @@ func (c *Client) LinkedAccounts(ctx context.Context) (*LinkedAccountsResponse, e
 	return &result, nil
 }
 
+// AccountHashFor returns the hash value for a plain-text account number, for
+// use with the account-specific endpoints. The LinkedAccounts mapping is
+// fetched on first use and cached for the lifetime of the Client; call
+// RefreshAccountHashes to pick up newly linked accounts.
+//
+// Returns error if the request fails or the account number is not linked.
+func (c *Client) AccountHashFor(ctx context.Context, accountNumber string) (string, error) {
+	c.hashMu.Lock()
+	defer c.hashMu.Unlock()
+
+	if c.accountHashes == nil {
+		if err := c.loadAccountHashes(ctx); err != nil {
+			return "", err
+		}
+	}
+	hash, ok := c.accountHashes[accountNumber]
+	if !ok {
+		return "", fmt.Errorf("failed to get account hash: account %v not found", accountNumber)
+	}
+	return hash, nil
+}
+
+// RefreshAccountHashes refetches LinkedAccounts and replaces the mapping
+// cached by AccountHashFor.
+func (c *Client) RefreshAccountHashes(ctx context.Context) error {
+	c.hashMu.Lock()
+	defer c.hashMu.Unlock()
+	return c.loadAccountHashes(ctx)
+}
+
+// loadAccountHashes fills c.accountHashes. The caller must hold c.hashMu.
+func (c *Client) loadAccountHashes(ctx context.Context) error {
+	accounts, err := c.LinkedAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	hashes := make(map[string]string, len(*accounts))
+	for _, acct := range *accounts {
+		hashes[acct.AccountNumber] = acct.HashValue
+	}
+	c.accountHashes = hashes
+	return nil
+}
+
 // AccountDetailsAll fetches all linked account information for the authenticated user.
 // By default, balances are returned. Use fields="positions" to include positions.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
-//   - fields: Optional fields to return (can be nil, e.g., "positions")
+//   - fields: Optional fields to return (can be nil, e.g., "positions"; see AccountFieldPositions)
 //
 // Returns a pointer to AccountDetailsAllResponse containing account details and aggregated balances.
 func (c *Client) AccountDetailsAll(ctx context.Context, fields *string) ([]AccountDetailsAllResponse, error) {
@@ -355,6 +949,54 @@ func (c *Client) AccountDetails(ctx context.Context, accountHash string, fields
 	return &result, nil
 }
 
+// AccountDetailsFor fetches the details of several specific accounts
+// concurrently, with at most AccountDetailsConcurrency requests in flight, and
+// returns them in the order of hashes. Use it instead of AccountDetailsAll
+// when only some linked accounts are needed.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - hashes: Account hashes from LinkedAccounts() or AccountHashFor()
+//   - fields: Optional data to include (AccountFieldPositions), or ""
+//
+// If some accounts fail, the details of the others are returned together with
+// the joined errors of the failed ones.
+func (c *Client) AccountDetailsFor(ctx context.Context, hashes []string, fields AccountField) ([]AccountDetailsResponse, error) {
+	var fieldsParam *string
+	if fields != "" {
+		f := string(fields)
+		fieldsParam = &f
+	}
+
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, AccountDetailsConcurrency)
+		results = make([]*AccountDetailsResponse, len(hashes))
+		errs    = make([]error, len(hashes))
+	)
+	for i, hash := range hashes {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			resp, err := c.AccountDetails(ctx, hash, fieldsParam)
+			if err != nil {
+				errs[i] = fmt.Errorf("account %s: %w", hash, err)
+				return
+			}
+			results[i] = resp
+		})
+	}
+	wg.Wait()
+
+	details := make([]AccountDetailsResponse, 0, len(hashes))
+	for _, r := range results {
+		if r != nil {
+			details = append(details, *r)
+		}
+	}
+	return details, errors.Join(errs...)
+}
+
 // GetStreamerInfo fetches WebSocket streaming connection details.
 // It retrieves the streamer URL and credentials needed to establish a WebSocket connection.
 //
@@ -380,6 +1022,19 @@ func (c *Client) GetStreamerInfo(ctx context.Context) (*StreamerInfo, error) {
 	return prefs.StreamerInfo[0], nil
 }
 
+// InfoSource returns an InfoSource for NewStreamer that fetches fresh
+// streamer connection details with GetStreamerInfo on every call, so each
+// reconnect logs in with current credentials.
+func (c *Client) InfoSource() InfoSource {
+	return func() (map[string]any, error) {
+		info, err := c.GetStreamerInfo(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		return streamerInfoMap(info), nil
+	}
+}
+
 // timeConvert converts a time value to the specified format.
 // It handles time.Time, string (ISO 8601 date/datetime), and nil inputs.
 // Returns the converted value as string or int64, or nil if input is nil.
@@ -482,16 +1137,138 @@ func (c *Client) AccountOrders(ctx context.Context, accountHash string, fromEnte
 	return &result, nil
 }
 
+// AccountOrdersPaged retrieves the orders of an account entered between start
+// and end by splitting the range into consecutive windows (DefaultOrdersWindow
+// when window is 0) and requesting each in turn, so ranges holding more
+// orders than a single response can carry are fetched in full. Orders
+// returned by more than one window are de-duplicated by OrderID. maxResults
+// and status are passed to every request as in AccountOrders.
+//
+// Returns error if start is after end, or on the first failed window.
+func (c *Client) AccountOrdersPaged(ctx context.Context, accountHash string, start, end time.Time, window time.Duration, maxResults *int, status *string) (*AccountOrdersResponse, error) {
+	orders, err := pageOrders(start, end, window, func(from, to time.Time) ([]Order, error) {
+		page, err := c.AccountOrders(ctx, accountHash, from, to, maxResults, status)
+		if err != nil {
+			return nil, err
+		}
+		return *page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := AccountOrdersResponse(orders)
+	return &result, nil
+}
+
+// pageOrders calls fetch for consecutive windows covering start to end and
+// merges the orders, dropping repeats of an OrderID. It implements
+// AccountOrdersPaged and AccountOrdersAllPaged.
+func pageOrders(start, end time.Time, window time.Duration, fetch func(from, to time.Time) ([]Order, error)) ([]Order, error) {
+	if start.After(end) {
+		return nil, fmt.Errorf("failed to get orders: %w: start %s is after end %s",
+			ErrInvalidParameter, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	if window <= 0 {
+		window = DefaultOrdersWindow
+	}
+
+	var result []Order
+	seen := make(map[int64]bool)
+	for from := start; from.Before(end); from = from.Add(window) {
+		to := from.Add(window)
+		if to.After(end) {
+			to = end
+		}
+		page, err := fetch(from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range page {
+			if o.OrderID != 0 {
+				if seen[o.OrderID] {
+					continue
+				}
+				seen[o.OrderID] = true
+			}
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
+// AccountOrdersByStatuses retrieves the orders of an account that are in any
+// of statuses. Schwab filters on one status per request, so one request per
+// status is made concurrently and the results are merged, without duplicate
+// order IDs, newest first.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - accountHash: Account hash from LinkedAccounts()
+//   - statuses: Order statuses to include; at least one is required
+//   - fromEnteredTime: Start date (time.Time, string in ISO 8601 format, or nil)
+//   - toEnteredTime: End date (time.Time, string in ISO 8601 format, or nil)
+//   - maxResults: Maximum number of results per status (nil for default 3000)
+//
+// If some requests fail, the orders from the others are returned together
+// with the joined errors of the failed ones.
+func (c *Client) AccountOrdersByStatuses(ctx context.Context, accountHash string, statuses []OrderStatus, fromEnteredTime, toEnteredTime any, maxResults *int) (*AccountOrdersResponse, error) {
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("failed to get account orders: %w: no statuses", ErrInvalidParameter)
+	}
+	for _, s := range statuses {
+		if !slices.Contains(orderStatuses, s) {
+			return nil, fmt.Errorf("failed to get account orders: %w: status %q", ErrInvalidParameter, s)
+		}
+	}
+	statuses = slices.Compact(slices.Sorted(slices.Values(statuses)))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		seen = make(map[int64]bool)
+		errs []error
+	)
+	merged := AccountOrdersResponse{}
+	for _, status := range statuses {
+		wg.Go(func() {
+			s := string(status)
+			resp, err := c.AccountOrders(ctx, accountHash, fromEnteredTime, toEnteredTime, maxResults, &s)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("status %s: %w", status, err))
+				return
+			}
+			for _, o := range *resp {
+				if !seen[o.OrderID] {
+					seen[o.OrderID] = true
+					merged = append(merged, o)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	slices.SortStableFunc(merged, func(a, b Order) int {
+		return cmp.Or(cmp.Compare(b.EnteredTime, a.EnteredTime), cmp.Compare(b.OrderID, a.OrderID))
+	})
+	return &merged, errors.Join(errs...)
+}
+
 // PlaceOrder places an order for a specific account.
 // The order ID is returned in the Location header of the response.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
 //   - accountHash: Account hash from LinkedAccounts()
-//   - order: Order request details
+//   - order: Order request details, checked with ValidateOrder before sending
 //
 // Returns PlaceOrderResponse containing the order ID and any error that occurred.
 func (c *Client) PlaceOrder(ctx context.Context, accountHash string, order *OrderRequest) (*PlaceOrderResponse, error) {
+	if err := ValidateOrder(order); err != nil {
+		return nil, fmt.Errorf("failed to place order: %w", err)
+	}
 	path := fmt.Sprintf("/trader/v1/accounts/%s/orders", accountHash)
 
 	resp, err := c.request(ctx, "POST", path, order, nil)
@@ -524,13 +1301,17 @@ func (c *Client) PlaceOrder(ctx context.Context, accountHash string, order *Orde
 //   - orderID: Order ID to retrieve
 //
 // Returns OrderDetailsResponse containing order details.
-// Returns error if the request fails.
+// Returns error if the request fails, or one wrapping ErrNotFound if the
+// order does not exist.
 func (c *Client) OrderDetails(ctx context.Context, accountHash string, orderID any) (*OrderDetailsResponse, error) {
 	var result OrderDetailsResponse
-	_, err := c.request(ctx, "GET", fmt.Sprintf("/trader/v1/accounts/%s/orders/%v", accountHash, orderID), nil, &result)
+	resp, err := c.request(ctx, "GET", fmt.Sprintf("/trader/v1/accounts/%s/orders/%v", accountHash, orderID), nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order details: %w", err)
 	}
+	if isNullBody(resp) {
+		return nil, fmt.Errorf("failed to get order details: %w: order %v", ErrNotFound, orderID)
+	}
 	return &result, nil
 }
 
@@ -552,6 +1333,90 @@ func (c *Client) CancelOrder(ctx context.Context, accountHash string, orderID an
 	return &result, nil
 }
 
+// DeleteWithBody sends a DELETE request with body marshaled as JSON, for
+// operations this package has no method for that take parameters in the
+// body. path is relative to the API base URL (e.g. "/trader/v1/accounts/...")
+// so the access token is never sent to another host; headers are added as
+// with WithHeaders. Like every Client method, a failed status (400 or above
+// by default; see SetSuccessPredicate) is returned as an error wrapping
+// *APIError:
+//
+//	_, err := client.DeleteWithBody(ctx, path, nil, params)
+//	var apiErr *schwabdev.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+//		// already gone
+//	}
+//
+// The returned response's body has already been read; resp.Body holds an
+// in-memory copy.
+func (c *Client) DeleteWithBody(ctx context.Context, path string, headers map[string]string, body any) (*http.Response, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("failed to delete: %w: path %q must start with /", ErrInvalidParameter, path)
+	}
+	if len(headers) > 0 {
+		ctx = WithHeaders(ctx, headers)
+	}
+	resp, err := c.request(ctx, http.MethodDelete, path, body, nil)
+	if err != nil {
+		return resp, fmt.Errorf("failed to delete: %w", err)
+	}
+	return resp, nil
+}
+
+// CancelAllOpenOrders cancels every working order on an account, for use as a
+// panic button that flattens resting orders. It fetches the account's orders
+// from the last OpenOrdersLookback, keeps those in a working status, and
+// cancels them concurrently with at most CancelOrdersConcurrency requests in
+// flight.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - accountHash: Account hash from LinkedAccounts()
+//
+// Returns the IDs of the orders that were cancelled. If some cancels fail, the
+// IDs of the others are returned together with the joined errors of the
+// failed ones.
+func (c *Client) CancelAllOpenOrders(ctx context.Context, accountHash string) (cancelled []string, err error) {
+	now := time.Now()
+	orders, err := c.AccountOrders(ctx, accountHash, now.Add(-OpenOrdersLookback), now, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel open orders: %w", err)
+	}
+
+	var ids []string
+	for _, o := range *orders {
+		if OrderStatus(o.Status).Working() {
+			ids = append(ids, strconv.FormatInt(o.OrderID, 10))
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, CancelOrdersConcurrency)
+		ok   = make([]bool, len(ids))
+		errs = make([]error, len(ids))
+	)
+	for i, id := range ids {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if _, err := c.CancelOrder(ctx, accountHash, id); err != nil {
+				errs[i] = fmt.Errorf("order %s: %w", id, err)
+				return
+			}
+			ok[i] = true
+		})
+	}
+	wg.Wait()
+
+	for i, id := range ids {
+		if ok[i] {
+			cancelled = append(cancelled, id)
+		}
+	}
+	return cancelled, errors.Join(errs...)
+}
+
 // ReplaceOrder replaces an existing order for an account.
 // The existing order will be replaced by the new order. Once replaced, the old order will be canceled and a new order will be created.
 //
@@ -616,6 +1481,23 @@ func (c *Client) AccountOrdersAll(ctx context.Context, fromEnteredTime, toEntere
 	return &result, nil
 }
 
+// AccountOrdersAllPaged is AccountOrdersPaged for the orders of every linked
+// account, paging AccountOrdersAll.
+func (c *Client) AccountOrdersAllPaged(ctx context.Context, start, end time.Time, window time.Duration, maxResults *int, status *string) (*AccountOrdersAllResponse, error) {
+	orders, err := pageOrders(start, end, window, func(from, to time.Time) ([]Order, error) {
+		page, err := c.AccountOrdersAll(ctx, from, to, maxResults, status)
+		if err != nil {
+			return nil, err
+		}
+		return *page, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := AccountOrdersAllResponse(orders)
+	return &result, nil
+}
+
 // PreviewOrder previews an order for a specific account.
 //
 // Parameters:
@@ -642,11 +1524,13 @@ func (c *Client) PreviewOrder(ctx context.Context, accountHash string, order *Pr
 //   - accountHash: Account hash from LinkedAccounts()
 //   - startDate: Start date (time.Time, string in ISO 8601 format, or nil)
 //   - endDate: End date (time.Time, string in ISO 8601 format, or nil)
-//   - types: Transaction type filter (see API documentation for possible values)
+//   - types: A single transaction type, e.g. "TRADE"; passed through unchecked
+//     (see TransactionsOfType for a validated variant)
 //   - symbol: Symbol filter (optional, can be nil)
 //
 // Returns TransactionsResponse containing list of transactions.
-// Returns error if the request fails.
+// Returns error if a date cannot be parsed, startDate is after endDate
+// (wrapping ErrInvalidParameter), or the request fails.
 func (c *Client) Transactions(ctx context.Context, accountHash string, startDate, endDate any, types string, symbol *string) (*TransactionsResponse, error) {
 	// Convert time parameters
 	start, err := c.timeConvert(startDate, TimeFormatISO8601)
@@ -658,6 +1542,12 @@ func (c *Client) Transactions(ctx context.Context, accountHash string, startDate
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert endDate: %w", err)
 	}
+	// Both are in the same fixed-width UTC layout, so string order is time order.
+	if s, ok := start.(string); ok {
+		if e, ok := end.(string); ok && s > e {
+			return nil, fmt.Errorf("failed to get transactions: %w: startDate %s is after endDate %s", ErrInvalidParameter, s, e)
+		}
+	}
 
 	// Build query parameters
 	params := c.parseParams(map[string]any{
@@ -680,6 +1570,72 @@ func (c *Client) Transactions(ctx context.Context, accountHash string, startDate
 	return &result, nil
 }
 
+// TransactionsBetween is Transactions with typed dates. Both are required
+// and sent as ISO 8601 UTC with milliseconds ("2026-10-15T14:30:00.000Z");
+// a zero time or a start after end is rejected with ErrInvalidParameter
+// before any request is made.
+func (c *Client) TransactionsBetween(ctx context.Context, accountHash string, start, end time.Time, types string, symbol *string) (*TransactionsResponse, error) {
+	if start.IsZero() || end.IsZero() {
+		return nil, fmt.Errorf("failed to get transactions: %w: start and end are required", ErrInvalidParameter)
+	}
+	return c.Transactions(ctx, accountHash, start, end, types, symbol)
+}
+
+// TransactionsOfType is TransactionsBetween with a typed transaction type.
+// Schwab takes exactly one type per request, so a comma-separated list or an
+// unknown value is rejected with ErrInvalidParameter before any request is
+// made; use Transactions to pass values this package does not know about.
+func (c *Client) TransactionsOfType(ctx context.Context, accountHash string, start, end time.Time, txnType TransactionType, symbol *string) (*TransactionsResponse, error) {
+	if strings.Contains(string(txnType), ",") {
+		return nil, fmt.Errorf("failed to get transactions: %w: types %q: only one type per request", ErrInvalidParameter, txnType)
+	}
+	if !slices.Contains(transactionTypes, txnType) {
+		return nil, fmt.Errorf("failed to get transactions: %w: types %q", ErrInvalidParameter, txnType)
+	}
+	return c.TransactionsBetween(ctx, accountHash, start, end, string(txnType), symbol)
+}
+
+// TransactionsPaged retrieves transactions between start and end by splitting
+// the range into consecutive windows (DefaultTransactionsWindow when window is
+// 0) and requesting each in turn, so ranges longer than a single response can
+// carry are fetched in full. Transactions returned by more than one window are
+// de-duplicated by TransactionID. types and symbol are passed to every request
+// as in Transactions.
+//
+// Returns error if start is after end, or on the first failed window.
+func (c *Client) TransactionsPaged(ctx context.Context, accountHash string, start, end time.Time, window time.Duration, types string, symbol *string) (*TransactionsResponse, error) {
+	if start.After(end) {
+		return nil, fmt.Errorf("failed to get transactions: %w: start %s is after end %s",
+			ErrInvalidParameter, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	if window <= 0 {
+		window = DefaultTransactionsWindow
+	}
+
+	var result TransactionsResponse
+	seen := make(map[string]bool)
+	for from := start; from.Before(end); from = from.Add(window) {
+		to := from.Add(window)
+		if to.After(end) {
+			to = end
+		}
+		page, err := c.Transactions(ctx, accountHash, from, to, types, symbol)
+		if err != nil {
+			return nil, err
+		}
+		for _, txn := range *page {
+			if txn.TransactionID != "" {
+				if seen[txn.TransactionID] {
+					continue
+				}
+				seen[txn.TransactionID] = true
+			}
+			result = append(result, txn)
+		}
+	}
+	return &result, nil
+}
+
 // TransactionDetails retrieves specific transaction information for a specific account.
 //
 // Parameters:
@@ -688,13 +1644,17 @@ func (c *Client) Transactions(ctx context.Context, accountHash string, startDate
 //   - transactionID: Transaction ID to retrieve
 //
 // Returns TransactionDetailsResponse containing transaction details.
-// Returns error if the request fails.
+// Returns error if the request fails, or one wrapping ErrNotFound if the
+// transaction does not exist.
 func (c *Client) TransactionDetails(ctx context.Context, accountHash string, transactionID any) (*TransactionDetailsResponse, error) {
 	var result TransactionDetailsResponse
-	_, err := c.request(ctx, "GET", fmt.Sprintf("/trader/v1/accounts/%s/transactions/%v", accountHash, transactionID), nil, &result)
+	resp, err := c.request(ctx, "GET", fmt.Sprintf("/trader/v1/accounts/%s/transactions/%v", accountHash, transactionID), nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction details: %w", err)
 	}
+	if isNullBody(resp) {
+		return nil, fmt.Errorf("failed to get transaction details: %w: transaction %v", ErrNotFound, transactionID)
+	}
 	return &result, nil
 }
 
@@ -702,19 +1662,115 @@ func (c *Client) TransactionDetails(ctx context.Context, accountHash string, tra
 // MARKET DATA API METHODS
 // ============================================================================
 
+// QuoteFields joins fields into the comma-separated value expected by the
+// fields parameter of Quotes and Quote, returning ErrInvalidParameter for
+// unknown names. It returns nil, selecting the server default, when fields
+// is empty.
+func QuoteFields(fields ...QuoteField) (*string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		if !slices.Contains(quoteFields, f) {
+			return nil, fmt.Errorf("%w: fields %q", ErrInvalidParameter, f)
+		}
+		names[i] = string(f)
+	}
+	joined := strings.Join(names, ",")
+	return &joined, nil
+}
+
 // Quotes retrieves quotes for a list of tickers.
+// Schwab rejects requests carrying too many symbols, so lists longer than the
+// client's quotes batch size (DefaultQuotesBatchSize unless changed with
+// WithQuotesBatchSize) are split into chunks that are fetched concurrently and
+// merged into a single response. Every chunk carries the same fields and
+// indicative parameters. With EnableQuoteCache, symbols that are still fresh
+// in the cache are served from it and only the rest are requested.
 //
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
 //   - symbols: List of symbols (can be string "AMD,INTC" or []string{"AMD", "INTC"})
-//   - fields: Optional fields to return ("all", "quote", "fundamental")
+//   - fields: Optional fields to return ("all", "quote", "fundamental"); see QuoteFields
 //   - indicative: Whether to get indicative quotes
 //
 // Returns QuotesResponse containing quotes for all symbols.
-// Returns error if the request fails.
+// If some chunks fail, the quotes from the successful chunks are returned
+// together with the joined errors of the failed ones.
 func (c *Client) Quotes(ctx context.Context, symbols any, fields *string, indicative *bool) (*QuotesResponse, error) {
+	list := c.symbolList(c.formatList(symbols))
+	cache := c.quoteCache.Load()
+	if cache == nil || list == "" {
+		return c.fetchQuotes(ctx, list, fields, indicative)
+	}
+
+	hits := make(QuotesResponse)
+	var stale []string
+	for _, sym := range strings.Split(list, ",") {
+		if q, ok := cache.get(cacheKey(sym, fields, indicative)); ok {
+			hits[sym] = q
+		} else {
+			stale = append(stale, sym)
+		}
+	}
+	if len(stale) == 0 {
+		return &hits, nil
+	}
+
+	resp, err := c.fetchQuotes(ctx, strings.Join(stale, ","), fields, indicative)
+	if resp == nil {
+		return nil, err
+	}
+	for sym, q := range *resp {
+		cache.put(cacheKey(sym, fields, indicative), q)
+	}
+	maps.Copy(*resp, hits)
+	return resp, err
+}
+
+// fetchQuotes requests quotes for a comma-separated symbol list, splitting it
+// into concurrent batches of at most quotesBatchSize symbols.
+func (c *Client) fetchQuotes(ctx context.Context, list string, fields *string, indicative *bool) (*QuotesResponse, error) {
+	batchSize := c.quotesBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultQuotesBatchSize
+	}
+
+	all := strings.Split(list, ",")
+	if list == "" || len(all) <= batchSize {
+		return c.quotes(ctx, list, fields, indicative)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		merged = make(QuotesResponse, len(all))
+		errs   []error
+	)
+	for chunk := range slices.Chunk(all, batchSize) {
+		wg.Go(func() {
+			resp, err := c.quotes(ctx, strings.Join(chunk, ","), fields, indicative)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			maps.Copy(merged, *resp)
+		})
+	}
+	wg.Wait()
+
+	return &merged, errors.Join(errs...)
+}
+
+// quotes performs a single GET /marketdata/v1/quotes request for a
+// comma-separated symbol list.
+func (c *Client) quotes(ctx context.Context, symbols string, fields *string, indicative *bool) (*QuotesResponse, error) {
 	params := c.parseParams(map[string]any{
-		"symbols":    c.formatList(symbols),
+		"symbols":    symbols,
 		"fields":     fields,
 		"indicative": indicative,
 	})
@@ -737,16 +1793,26 @@ func (c *Client) Quotes(ctx context.Context, symbols any, fields *string, indica
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
 //   - symbolID: Ticker symbol
-//   - fields: Optional fields to return ("all", "quote", "fundamental")
+//   - fields: Optional fields to return ("all", "quote", "fundamental"); see QuoteFields
+//   - indicative: Whether to get an indicative quote (e.g. for an ETF), or nil
 //
 // Returns QuoteResponse containing quote for the symbol.
 // Returns error if the request fails.
-func (c *Client) Quote(ctx context.Context, symbolID string, fields *string) (*QuoteResponse, error) {
+func (c *Client) Quote(ctx context.Context, symbolID string, fields *string, indicative *bool) (*QuoteResponse, error) {
+	symbolID = c.symbol(symbolID)
+	cache := c.quoteCache.Load()
+	if cache != nil {
+		if q, ok := cache.get(cacheKey(symbolID, fields, indicative)); ok {
+			return (*QuoteResponse)(&q), nil
+		}
+	}
+
 	params := c.parseParams(map[string]any{
-		"fields": fields,
+		"fields":     fields,
+		"indicative": indicative,
 	})
 
-	path := fmt.Sprintf("/marketdata/v1/%s/quotes", url.PathEscape(symbolID))
+	path := fmt.Sprintf("/marketdata/v1/%s/quotes", EncodeSymbol(symbolID))
 	if len(params) > 0 {
 		path += "?" + params.Encode()
 	}
@@ -756,6 +1822,9 @@ func (c *Client) Quote(ctx context.Context, symbolID string, fields *string) (*Q
 	if err != nil {
 		return nil, fmt.Errorf("failed to get quote: %w", err)
 	}
+	if cache != nil {
+		cache.put(cacheKey(symbolID, fields, indicative), Quote(result))
+	}
 	return &result, nil
 }
 
@@ -799,7 +1868,7 @@ func (c *Client) OptionChains(ctx context.Context, symbol string, contractType *
 	}
 
 	params := c.parseParams(map[string]any{
-		"symbol":                 symbol,
+		"symbol":                 c.symbol(symbol),
 		"contractType":           contractType,
 		"strikeCount":            strikeCount,
 		"includeUnderlyingQuote": includeUnderlyingQuote,
@@ -831,6 +1900,33 @@ func (c *Client) OptionChains(ctx context.Context, symbol string, contractType *
 	return &result, nil
 }
 
+// OptionChainsForExpiration is OptionChains for the common case of a single
+// expiration: calls and puts, strategy SINGLE, range NTM and strikeCount
+// strikes around the money, with fromDate and toDate both set to expiration.
+// Use OptionChains for any other combination.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - symbol: Ticker symbol
+//   - expiration: Expiration date as YYYY-MM-DD
+//   - strikeCount: Number of strikes to return, must be positive
+//
+// Returns OptionChainsResponse containing option chain data.
+// Returns error if a parameter is invalid (wrapping ErrInvalidParameter) or
+// the request fails.
+func (c *Client) OptionChainsForExpiration(ctx context.Context, symbol, expiration string, strikeCount int) (*OptionChainsResponse, error) {
+	if _, err := time.Parse(time.DateOnly, expiration); err != nil {
+		return nil, fmt.Errorf("failed to get option chains: %w: expiration %q", ErrInvalidParameter, expiration)
+	}
+	if strikeCount <= 0 {
+		return nil, fmt.Errorf("failed to get option chains: %w: strikeCount %d", ErrInvalidParameter, strikeCount)
+	}
+
+	contractType, strategy, range_ := "ALL", "SINGLE", "NTM"
+	return c.OptionChains(ctx, symbol, &contractType, &strikeCount, nil, &strategy, nil, nil, &range_,
+		expiration, expiration, nil, nil, nil, nil, nil, nil, nil)
+}
+
 // OptionExpirationChain retrieves an option expiration chain for a ticker.
 //
 // Parameters:
@@ -841,7 +1937,7 @@ func (c *Client) OptionChains(ctx context.Context, symbol string, contractType *
 // Returns error if the request fails.
 func (c *Client) OptionExpirationChain(ctx context.Context, symbol string) (*OptionExpirationChainResponse, error) {
 	params := c.parseParams(map[string]any{
-		"symbol": symbol,
+		"symbol": c.symbol(symbol),
 	})
 
 	path := "/marketdata/v1/expirationchain"
@@ -857,6 +1953,42 @@ func (c *Client) OptionExpirationChain(ctx context.Context, symbol string) (*Opt
 	return &result, nil
 }
 
+// ValidatePriceHistory checks a price history parameter combination against
+// the values Schwab accepts, returning ErrInvalidParameter naming the first
+// offending field. Zero values are treated as unset and left to the server
+// defaults.
+//
+// Allowed combinations:
+//   - day: period 1, 2, 3, 4, 5, 10; frequencyType minute
+//   - month: period 1, 2, 3, 6; frequencyType daily, weekly
+//   - year: period 1, 2, 3, 5, 10, 15, 20; frequencyType daily, weekly, monthly
+//   - ytd: period 1; frequencyType daily, weekly
+//   - minute: frequency 1, 5, 10, 15, 30; daily, weekly, monthly: frequency 1
+func ValidatePriceHistory(periodType PeriodType, period int, frequencyType FrequencyType, frequency int) error {
+	if periodType != "" {
+		allowed, ok := priceHistoryPeriods[periodType]
+		if !ok {
+			return fmt.Errorf("%w: periodType %q", ErrInvalidParameter, periodType)
+		}
+		if period != 0 && !slices.Contains(allowed.periods, period) {
+			return fmt.Errorf("%w: period %d not allowed for periodType %q", ErrInvalidParameter, period, periodType)
+		}
+		if frequencyType != "" && !slices.Contains(allowed.frequencyTypes, frequencyType) {
+			return fmt.Errorf("%w: frequencyType %q not allowed for periodType %q", ErrInvalidParameter, frequencyType, periodType)
+		}
+	}
+	if frequencyType != "" {
+		allowed, ok := priceHistoryFrequencies[frequencyType]
+		if !ok {
+			return fmt.Errorf("%w: frequencyType %q", ErrInvalidParameter, frequencyType)
+		}
+		if frequency != 0 && !slices.Contains(allowed, frequency) {
+			return fmt.Errorf("%w: frequency %d not allowed for frequencyType %q", ErrInvalidParameter, frequency, frequencyType)
+		}
+	}
+	return nil
+}
+
 // PriceHistory retrieves price history for a ticker.
 //
 // Parameters:
@@ -872,11 +2004,29 @@ func (c *Client) OptionExpirationChain(ctx context.Context, symbol string) (*Opt
 //   - needPreviousClose: Need previous close
 //
 // Returns PriceHistoryResponse containing candle history.
-// Returns error if the request fails.
+// Returns error if the combination fails ValidatePriceHistory or the request fails.
 func (c *Client) PriceHistory(ctx context.Context, symbol string, periodType *string, period *int,
 	frequencyType *string, frequency *int, startDate, endDate any,
 	needExtendedHoursData, needPreviousClose *bool) (*PriceHistoryResponse, error) {
 
+	var pt, ft string
+	var p, f int
+	if periodType != nil {
+		pt = *periodType
+	}
+	if frequencyType != nil {
+		ft = *frequencyType
+	}
+	if period != nil {
+		p = *period
+	}
+	if frequency != nil {
+		f = *frequency
+	}
+	if err := ValidatePriceHistory(PeriodType(pt), p, FrequencyType(ft), f); err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
 	start, err := c.timeConvert(startDate, TimeFormatEPOCHMS)
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert startDate: %w", err)
@@ -912,6 +2062,99 @@ func (c *Client) PriceHistory(ctx context.Context, symbol string, periodType *st
 	return &result, nil
 }
 
+// PriceHistoryRange retrieves frequencyType/frequency candles for symbol
+// between start and end, however long the range. Schwab caps the span of a
+// single request, so the range is split into consecutive windows of
+// PriceHistoryMinuteWindow for minute candles or PriceHistoryDailyWindow
+// otherwise, fetched concurrently with at most PriceHistoryConcurrency
+// requests in flight. The candles are merged in Datetime order, with null
+// entries and the duplicates returned at window boundaries dropped.
+//
+// Returns error if start is zero or after end, the frequency is invalid
+// (see ValidatePriceHistory), or any window fails. The first failure cancels
+// the windows still pending or in flight; the errors of every window that
+// failed on its own are joined.
+func (c *Client) PriceHistoryRange(ctx context.Context, symbol string, start, end time.Time, frequencyType FrequencyType, frequency int) (*PriceHistoryResponse, error) {
+	if start.IsZero() || start.After(end) {
+		return nil, fmt.Errorf("failed to get price history: %w: start %s must be set and not after end %s",
+			ErrInvalidParameter, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+	// Dated requests still need a periodType compatible with the frequency.
+	periodType, window := PeriodTypeYear, PriceHistoryDailyWindow
+	if frequencyType == FrequencyTypeMinute {
+		periodType, window = PeriodTypeDay, PriceHistoryMinuteWindow
+	}
+	if err := ValidatePriceHistory(periodType, 0, frequencyType, frequency); err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	type span struct{ from, to time.Time }
+	var spans []span
+	for from := start; ; from = from.Add(window) {
+		to := from.Add(window)
+		if !to.Before(end) {
+			spans = append(spans, span{from, end})
+			break
+		}
+		spans = append(spans, span{from, to})
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, PriceHistoryConcurrency)
+		pages = make([]*PriceHistoryResponse, len(spans))
+		errs  = make([]error, len(spans))
+	)
+	pt, ft := string(periodType), string(frequencyType)
+	for i, sp := range spans {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				return
+			}
+			page, err := c.PriceHistory(ctx, symbol, &pt, nil, &ft, &frequency, sp.from, sp.to, nil, nil)
+			if err != nil {
+				// Windows cut short by another window's failure add nothing.
+				if errors.Is(err, context.Canceled) && parent.Err() == nil {
+					return
+				}
+				errs[i] = fmt.Errorf("window %s to %s: %w", sp.from.Format(time.RFC3339), sp.to.Format(time.RFC3339), err)
+				cancel()
+				return
+			}
+			pages[i] = page
+		})
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if err := parent.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+
+	result := &PriceHistoryResponse{Symbol: symbol}
+	for _, page := range pages {
+		for _, c := range page.Candles {
+			if c != nil {
+				result.Candles = append(result.Candles, c)
+			}
+		}
+		if page.Symbol != "" {
+			result.Symbol = page.Symbol
+		}
+	}
+	slices.SortStableFunc(result.Candles, func(a, b *Candle) int { return cmp.Compare(a.Datetime, b.Datetime) })
+	result.Candles = slices.CompactFunc(result.Candles, func(a, b *Candle) bool { return a.Datetime == b.Datetime })
+	result.Empty = len(result.Candles) == 0
+	return result, nil
+}
+
 // Movers retrieves market movers for a specific index.
 //
 // Parameters:
@@ -928,7 +2171,7 @@ func (c *Client) Movers(ctx context.Context, symbol string, sort *string, freque
 		"frequency": frequency,
 	})
 
-	path := fmt.Sprintf("/marketdata/v1/movers/%s", symbol)
+	path := fmt.Sprintf("/marketdata/v1/movers/%s", EncodeSymbol(symbol))
 	if len(params) > 0 {
 		path += "?" + params.Encode()
 	}
@@ -941,6 +2184,38 @@ func (c *Client) Movers(ctx context.Context, symbol string, sort *string, freque
 	return &result, nil
 }
 
+// MoversFor is Movers with typed parameters. Unknown values are rejected with
+// ErrInvalidParameter before any request is made; use Movers to pass values
+// this package does not know about.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - index: Index or market to rank movers within
+//   - sort: Ranking to apply, or "" for the server default
+//   - frequency: Minimum percent change for inclusion
+//
+// Returns MoversResponse containing market movers.
+// Returns error if a parameter is invalid or the request fails.
+func (c *Client) MoversFor(ctx context.Context, index MoverIndex, sort MoverSort, frequency MoverFrequency) (*MoversResponse, error) {
+	if !slices.Contains(moverIndexes, index) {
+		return nil, fmt.Errorf("failed to get movers: %w: index %q", ErrInvalidParameter, index)
+	}
+	if sort != "" && !slices.Contains(moverSorts, sort) {
+		return nil, fmt.Errorf("failed to get movers: %w: sort %q", ErrInvalidParameter, sort)
+	}
+	if !slices.Contains(moverFrequencies, frequency) {
+		return nil, fmt.Errorf("failed to get movers: %w: frequency %d", ErrInvalidParameter, frequency)
+	}
+
+	var sortParam *string
+	if sort != "" {
+		s := string(sort)
+		sortParam = &s
+	}
+	freq := int(frequency)
+	return c.Movers(ctx, string(index), sortParam, &freq)
+}
+
 // MarketHours retrieves market hours for dates in the future across different markets.
 //
 // Parameters:
@@ -1016,8 +2291,14 @@ func (c *Client) MarketHour(ctx context.Context, marketID string, date any) (*Ma
 // Returns InstrumentsResponse containing instrument search results.
 // Returns error if the request fails.
 func (c *Client) Instruments(ctx context.Context, symbols any, projection string) (*InstrumentsResponse, error) {
+	list := c.formatList(symbols)
+	// Only symbol lists are normalized; patterns and description text are
+	// sent as given.
+	if projection == string(ProjectionSymbolSearch) || projection == string(ProjectionFundamental) {
+		list = c.symbolList(list)
+	}
 	params := c.parseParams(map[string]any{
-		"symbol":     c.formatList(symbols),
+		"symbol":     list,
 		"projection": projection,
 	})
 
@@ -1034,6 +2315,30 @@ func (c *Client) Instruments(ctx context.Context, symbols any, projection string
 	return &result, nil
 }
 
+// SearchInstruments is Instruments with a typed projection, which decides
+// what query means: one or more comma-separated symbols for
+// ProjectionSymbolSearch and ProjectionFundamental, a regular expression over
+// symbols or descriptions for ProjectionSymbolRegex and ProjectionDescRegex,
+// and description text for ProjectionDescSearch. An unknown projection is
+// rejected with ErrInvalidParameter before any request is made.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - query: Symbols, text or pattern, depending on projection
+//   - projection: How to interpret query
+//
+// Returns InstrumentsResponse containing instrument search results.
+// Returns error if the projection is invalid or the request fails.
+func (c *Client) SearchInstruments(ctx context.Context, query string, projection InstrumentProjection) (*InstrumentsResponse, error) {
+	if !slices.Contains(instrumentProjections, projection) {
+		return nil, fmt.Errorf("failed to get instruments: %w: projection %q", ErrInvalidParameter, projection)
+	}
+	if query == "" {
+		return nil, fmt.Errorf("failed to get instruments: %w: empty query", ErrInvalidParameter)
+	}
+	return c.Instruments(ctx, query, string(projection))
+}
+
 // InstrumentCUSIP retrieves an instrument for a single CUSIP.
 //
 // Parameters:
@@ -1044,7 +2349,7 @@ func (c *Client) Instruments(ctx context.Context, symbols any, projection string
 // Returns error if the request fails.
 func (c *Client) InstrumentCUSIP(ctx context.Context, cusipID any) (*InstrumentCUSIPResponse, error) {
 	var result InstrumentCUSIPResponse
-	_, err := c.request(ctx, "GET", fmt.Sprintf("/marketdata/v1/instruments/%v", cusipID), nil, &result)
+	_, err := c.request(ctx, "GET", fmt.Sprintf("/marketdata/v1/instruments/%s", EncodeSymbol(fmt.Sprint(cusipID))), nil, &result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get instrument by CUSIP: %w", err)
 	}
@@ -0,0 +1,2393 @@
+package schwabdev_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	schwabdev "github.com/citizenadam/go-schwabapi"
+)
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+const (
+	testAppKey      = "0123456789abcdef0123456789abcdef" // 32 chars
+	testAppSecret   = "0123456789abcdef"                 // 16 chars
+	testCallbackURL = "https://127.0.0.1"
+	testAccessToken = "test-access-token"
+)
+
+// newTestClient returns a Client pointed at srv with a freshly issued token
+// already in storage, so no OAuth traffic happens during the test.
+func newTestClient(t *testing.T, srv *httptest.Server, opts ...schwabdev.ClientOption) *schwabdev.Client {
+	t.Helper()
+	now := time.Now().UTC()
+	return newTestClientWithTokens(t, srv, schwabdev.TokenRecord{
+		AccessTokenIssued:  now,
+		RefreshTokenIssued: now,
+		AccessToken:        testAccessToken,
+		RefreshToken:       "test-refresh-token",
+		ExpiresIn:          1800,
+	}, opts...)
+}
+
+// newTestClientWithTokens is newTestClient with rec in token storage.
+func newTestClientWithTokens(t *testing.T, srv *httptest.Server, rec schwabdev.TokenRecord, opts ...schwabdev.ClientOption) *schwabdev.Client {
+	t.Helper()
+
+	tokenPath := filepath.Join(t.TempDir(), "tokens.json")
+	storage, err := schwabdev.NewFileTokenStorage(tokenPath)
+	if err != nil {
+		t.Fatalf("NewFileTokenStorage: %v", err)
+	}
+	if err := storage.Save(context.Background(), rec); err != nil {
+		t.Fatalf("save tokens: %v", err)
+	}
+
+	opts = append([]schwabdev.ClientOption{schwabdev.WithBaseURL(srv.URL)}, opts...)
+	client, err := schwabdev.NewClient(testAppKey, testAppSecret, testCallbackURL, tokenPath, "", 0, nil, opts...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// ── HTTP transport ────────────────────────────────────────────────────────────
+
+func TestClient_CloseIdleConnections(t *testing.T) {
+	var conns atomic.Int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `[]`)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+	if _, err := client.Movers(ctx, "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers: %v", err)
+	}
+	client.CloseIdleConnections()
+	client.CloseIdleConnections() // repeated calls are harmless
+	if _, err := client.Movers(ctx, "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers after CloseIdleConnections: %v", err)
+	}
+	if got := conns.Load(); got != 2 {
+		t.Errorf("want a fresh connection after CloseIdleConnections, got %d connections", got)
+	}
+}
+
+// countingTransport records the requests it forwards to http.DefaultTransport.
+type countingTransport struct {
+	calls atomic.Int32
+}
+
+func (rt *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.calls.Add(1)
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Instrumented", "yes")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_WithHTTPClient(t *testing.T) {
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Instrumented")
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	rt := &countingTransport{}
+	client := newTestClient(t, srv, schwabdev.WithHTTPClient(&http.Client{Transport: rt}))
+	if _, err := client.Movers(context.Background(), "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers: %v", err)
+	}
+	if rt.calls.Load() != 1 || header != "yes" {
+		t.Errorf("custom transport not used: %d calls, header %q", rt.calls.Load(), header)
+	}
+}
+
+// ── Status handling ───────────────────────────────────────────────────────────
+
+func TestClient_ErrorStatusReturnsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message":"symbol not found"}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	_, err := client.Movers(context.Background(), "$BOGUS", nil, nil)
+	if err == nil {
+		t.Fatal("want error for 404 response")
+	}
+
+	var apiErr *schwabdev.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("want *APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode: want 404, got %d", apiErr.StatusCode)
+	}
+	if apiErr.Message != "symbol not found" {
+		t.Errorf("Message: want %q, got %q", "symbol not found", apiErr.Message)
+	}
+}
+
+func TestClient_ErrorBodyShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantMsgs []string
+		wantCode string
+	}{
+		{"object", `{"message":"bad symbol"}`, []string{"bad symbol"}, ""},
+		{"array", `[{"message":"bad symbol"},{"message":"bad date"}]`, []string{"bad symbol", "bad date"}, ""},
+		{"oauth object", `{"error":"invalid_client","error_description":"unknown key"}`, []string{""}, "invalid_client"},
+		{"not json", `<html>gateway error</html>`, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			client := newTestClient(t, srv)
+			_, err := client.Movers(context.Background(), "$DJI", nil, nil)
+			var apiErr *schwabdev.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("want *APIError, got %T: %v", err, err)
+			}
+			var msgs []string
+			for _, e := range apiErr.Errors {
+				msgs = append(msgs, e.Message)
+			}
+			if !slices.Equal(msgs, tt.wantMsgs) {
+				t.Errorf("Errors messages = %q, want %q", msgs, tt.wantMsgs)
+			}
+			if apiErr.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", apiErr.Code, tt.wantCode)
+			}
+			if len(tt.wantMsgs) > 0 && tt.wantMsgs[0] != "" && apiErr.Message != tt.wantMsgs[0] {
+				t.Errorf("Message = %q, want %q", apiErr.Message, tt.wantMsgs[0])
+			}
+			if string(apiErr.Body) != tt.body {
+				t.Errorf("Body = %q, want raw body", apiErr.Body)
+			}
+		})
+	}
+}
+
+func TestClient_EmptyBodyDecodesToZeroValue(t *testing.T) {
+	for name, write := range map[string]func(http.ResponseWriter){
+		"204":        func(w http.ResponseWriter) { w.WriteHeader(http.StatusNoContent) },
+		"empty 200":  func(w http.ResponseWriter) {},
+		"whitespace": func(w http.ResponseWriter) { io.WriteString(w, "\r\n ") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { write(w) }))
+			defer srv.Close()
+
+			quotes, err := newTestClient(t, srv).Quotes(context.Background(), "AAPL", nil, nil)
+			if err != nil {
+				t.Fatalf("Quotes: %v", err)
+			}
+			if quotes == nil || len(*quotes) != 0 {
+				t.Errorf("quotes = %v, want empty", quotes)
+			}
+		})
+	}
+}
+
+func TestClient_TruncatedBody(t *testing.T) {
+	for name, write := range map[string]func(http.ResponseWriter){
+		"cut JSON": func(w http.ResponseWriter) { io.WriteString(w, `{"AAPL":{"symbol":"AA`) },
+		"short Content-Length": func(w http.ResponseWriter) {
+			w.Header().Set("Content-Length", "100")
+			io.WriteString(w, `{"AAPL":{}}`)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { write(w) }))
+			defer srv.Close()
+
+			_, err := newTestClient(t, srv).Quotes(context.Background(), "AAPL", nil, nil)
+			if !errors.Is(err, schwabdev.ErrTruncatedResponse) {
+				t.Fatalf("want ErrTruncatedResponse, got %v", err)
+			}
+		})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"AAPL":{"symbol":oops}}`)
+	}))
+	defer srv.Close()
+	if _, err := newTestClient(t, srv).Quotes(context.Background(), "AAPL", nil, nil); errors.Is(err, schwabdev.ErrTruncatedResponse) {
+		t.Errorf("malformed body reported as truncated: %v", err)
+	}
+}
+
+func TestClient_SetSuccessPredicate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		io.WriteString(w, `{"AAPL":{"symbol":"AAPL"}}`)
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv)
+
+	quotes, err := client.Quotes(context.Background(), "AAPL", nil, nil)
+	if err != nil || len(*quotes) != 1 {
+		t.Fatalf("default predicate: quotes = %v, err = %v", quotes, err)
+	}
+
+	client.SetSuccessPredicate(func(status int) bool { return status == http.StatusOK || status == http.StatusNoContent })
+	_, err = client.Quotes(context.Background(), "AAPL", nil, nil)
+	var apiErr *schwabdev.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusAccepted {
+		t.Fatalf("202 with custom predicate: want *APIError with status 202, got %v", err)
+	}
+	if !strings.Contains(string(apiErr.Body), "AAPL") {
+		t.Errorf("APIError.Body = %q, want the response body", apiErr.Body)
+	}
+
+	client.SetSuccessPredicate(nil)
+	if _, err := client.Quotes(context.Background(), "AAPL", nil, nil); err != nil {
+		t.Errorf("after restoring the default: %v", err)
+	}
+}
+
+// ── Retry ─────────────────────────────────────────────────────────────────────
+
+func TestClient_WithRetry_429ThenOK(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, `[{"symbol":"AAPL","lastPrice":190.5}]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv, schwabdev.WithRetry(3))
+	resp, err := client.Movers(context.Background(), "$SPX", nil, nil)
+	if err != nil {
+		t.Fatalf("Movers: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("want 2 calls, got %d", got)
+	}
+	if len(*resp) != 1 || (*resp)[0].Symbol != "AAPL" {
+		t.Errorf("unexpected movers: %+v", *resp)
+	}
+}
+
+func TestClient_WithoutRetry_429IsReturned(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	_, err := client.Movers(context.Background(), "$SPX", nil, nil)
+
+	var apiErr *schwabdev.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("want 429 *APIError, got %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("want 1 call, got %d", got)
+	}
+}
+
+func TestClient_WithRetry_ReplaysBody(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Location", "/trader/v1/accounts/HASH/orders/12345")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv, schwabdev.WithRetry(2))
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	resp, err := client.PlaceOrder(context.Background(), "HASH", order)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if resp.OrderID != "12345" {
+		t.Errorf("OrderID: want 12345, got %s", resp.OrderID)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("want 2 requests, got %d", len(bodies))
+	}
+	if bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("retried body differs: %q vs %q", bodies[0], bodies[1])
+	}
+}
+
+func TestClient_RedirectReplaysBody(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if r.URL.Path == "/trader/v1/accounts/HASH/orders" {
+			http.Redirect(w, r, "/trader/v1/accounts/HASH/orders/moved", http.StatusTemporaryRedirect)
+			return
+		}
+		w.Header().Set("Location", "/trader/v1/accounts/HASH/orders/12345")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	if _, err := client.PlaceOrder(context.Background(), "HASH", order); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("want 2 requests, got %d", len(bodies))
+	}
+	if bodies[0] == "" || bodies[0] != bodies[1] {
+		t.Errorf("redirected body differs: %q vs %q", bodies[0], bodies[1])
+	}
+}
+
+func TestClient_WithRetry_RespectsDeadline(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv, schwabdev.WithRetry(5))
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Movers(ctx, "$SPX", nil, nil)
+	if err == nil {
+		t.Fatal("want error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("client slept past the deadline check: %v", elapsed)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("want 1 call, got %d", got)
+	}
+}
+
+// dropFirstServer closes the connection without responding to the first
+// request and answers later ones with body (plus a Location header so
+// PlaceOrder succeeds).
+func dropFirstServer(t *testing.T, calls *atomic.Int32, body string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Errorf("hijack: %v", err)
+				return
+			}
+			conn.Close()
+			return
+		}
+		w.Header().Set("Location", "/trader/v1/accounts/HASH/orders/1")
+		io.WriteString(w, body)
+	}))
+}
+
+func TestClient_WithNetworkRetry_RetriesGET(t *testing.T) {
+	var calls atomic.Int32
+	srv := dropFirstServer(t, &calls, `[{"symbol":"AAPL"}]`)
+	defer srv.Close()
+
+	client := newTestClient(t, srv, schwabdev.WithNetworkRetry(3))
+	resp, err := client.Movers(context.Background(), "$SPX", nil, nil)
+	if err != nil {
+		t.Fatalf("Movers: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("want 2 calls, got %d", got)
+	}
+	if len(*resp) != 1 {
+		t.Errorf("unexpected movers: %+v", *resp)
+	}
+}
+
+func TestClient_WithNetworkRetry_SkipsPOSTByDefault(t *testing.T) {
+	var calls atomic.Int32
+	srv := dropFirstServer(t, &calls, "")
+	defer srv.Close()
+
+	client := newTestClient(t, srv, schwabdev.WithNetworkRetry(3))
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	if _, err := client.PlaceOrder(context.Background(), "HASH", order); err == nil {
+		t.Fatal("want error from dropped connection")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("POST must not be retried by default, got %d calls", got)
+	}
+}
+
+func TestClient_WithNetworkRetry_OptInPOST(t *testing.T) {
+	var calls atomic.Int32
+	srv := dropFirstServer(t, &calls, "")
+	defer srv.Close()
+
+	client := newTestClient(t, srv, schwabdev.WithNetworkRetry(2, "GET", "post"))
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	if _, err := client.PlaceOrder(context.Background(), "HASH", order); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("want 2 calls, got %d", got)
+	}
+}
+
+// ── Timeouts ──────────────────────────────────────────────────────────────────
+
+// slowServer responds after delay unless the request context ends first.
+func slowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+			io.WriteString(w, `[]`)
+		case <-r.Context().Done():
+		}
+	}))
+}
+
+func TestClient_RequestTimeout_Default(t *testing.T) {
+	srv := slowServer(0)
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	if got := client.RequestTimeout(); got != schwabdev.DefaultHTTPRequestTimeout {
+		t.Errorf("RequestTimeout: want %v, got %v", schwabdev.DefaultHTTPRequestTimeout, got)
+	}
+}
+
+func TestClient_SetRequestTimeout_Expires(t *testing.T) {
+	srv := slowServer(2 * time.Second)
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	client.SetRequestTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := client.Movers(context.Background(), "$SPX", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("request took %v, want ~50ms", elapsed)
+	}
+}
+
+func TestClient_SetRequestTimeout_ZeroUsesCallerContext(t *testing.T) {
+	srv := slowServer(100 * time.Millisecond)
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	client.SetRequestTimeout(0)
+
+	// Without a caller deadline the slow response succeeds.
+	if _, err := client.Movers(context.Background(), "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers without deadline: %v", err)
+	}
+
+	// The caller's deadline is still honoured.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Movers(ctx, "$SPX", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// ── Quotes batching ───────────────────────────────────────────────────────────
+
+// quotesServer answers /marketdata/v1/quotes with one quote per requested
+// symbol and fails any chunk containing a symbol in fail.
+func quotesServer(t *testing.T, calls *atomic.Int32, fail string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		q := r.URL.Query()
+		if q.Get("fields") != "quote" || q.Get("indicative") != "true" {
+			t.Errorf("chunk lost params: fields=%q indicative=%q", q.Get("fields"), q.Get("indicative"))
+		}
+		resp := map[string]schwabdev.Quote{}
+		for _, sym := range strings.Split(q.Get("symbols"), ",") {
+			if sym == fail {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			resp[sym] = schwabdev.Quote{Symbol: sym}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestClient_Quotes_ChunksLargeSymbolLists(t *testing.T) {
+	var calls atomic.Int32
+	srv := quotesServer(t, &calls, "")
+	defer srv.Close()
+
+	symbols := make([]string, 1200)
+	for i := range symbols {
+		symbols[i] = fmt.Sprintf("SYM%d", i)
+	}
+
+	client := newTestClient(t, srv)
+	fields, indicative := "quote", true
+	resp, err := client.Quotes(context.Background(), symbols, &fields, &indicative)
+	if err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("want 3 requests, got %d", got)
+	}
+	if len(*resp) != len(symbols) {
+		t.Fatalf("want %d quotes, got %d", len(symbols), len(*resp))
+	}
+	for _, sym := range symbols {
+		if (*resp)[sym].Symbol != sym {
+			t.Errorf("%s missing from merged response", sym)
+		}
+	}
+}
+
+func TestClient_Quotes_PartialFailure(t *testing.T) {
+	var calls atomic.Int32
+	srv := quotesServer(t, &calls, "BAD")
+	defer srv.Close()
+
+	client := newTestClient(t, srv, schwabdev.WithQuotesBatchSize(2))
+	fields, indicative := "quote", true
+	resp, err := client.Quotes(context.Background(), "AAPL,MSFT,BAD,IBM", &fields, &indicative)
+
+	var apiErr *schwabdev.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("want 500 *APIError, got %v", err)
+	}
+	if resp == nil || len(*resp) != 2 {
+		t.Fatalf("want partial result with 2 quotes, got %v", resp)
+	}
+	if _, ok := (*resp)["AAPL"]; !ok {
+		t.Error("AAPL missing from partial result")
+	}
+}
+
+func TestQuoteFields(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	fields, err := schwabdev.QuoteFields(schwabdev.QuoteFieldQuote, schwabdev.QuoteFieldFundamental)
+	if err != nil {
+		t.Fatalf("QuoteFields: %v", err)
+	}
+	client := newTestClient(t, srv)
+	if _, err := client.Quotes(context.Background(), "AAPL", fields, nil); err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	if query != "fields=quote%2Cfundamental&symbols=AAPL" {
+		t.Errorf("query: got %s", query)
+	}
+
+	if fields, err := schwabdev.QuoteFields(); fields != nil || err != nil {
+		t.Errorf("no fields: want (nil, nil), got (%v, %v)", fields, err)
+	}
+	if _, err := schwabdev.QuoteFields(schwabdev.QuoteFieldQuote, "fundamentals"); !errors.Is(err, schwabdev.ErrInvalidParameter) ||
+		!strings.Contains(err.Error(), "fundamentals") {
+		t.Errorf("want ErrInvalidParameter naming fundamentals, got %v", err)
+	}
+}
+
+func TestClient_Quote_Indicative(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	indicative := true
+	if _, err := client.Quote(context.Background(), "SPY", nil, &indicative); err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if query != "indicative=true" {
+		t.Errorf("query: got %q, want indicative=true", query)
+	}
+
+	if _, err := client.Quote(context.Background(), "SPY", nil, nil); err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if query != "" {
+		t.Errorf("query without indicative: got %q", query)
+	}
+}
+
+// ── Symbol encoding ───────────────────────────────────────────────────────────
+
+func TestEncodeSymbol(t *testing.T) {
+	tests := []struct {
+		symbol, want string
+	}{
+		{"AAPL", "AAPL"},
+		{"BRK.B", "BRK.B"},
+		{"$SPX", "$SPX"},
+		{"AAPL  240809C00095000", "AAPL%20%20240809C00095000"},
+		{"/ES", "%2FES"},
+		{"EUR/USD", "EUR%2FUSD"},
+	}
+	for _, tt := range tests {
+		if got := schwabdev.EncodeSymbol(tt.symbol); got != tt.want {
+			t.Errorf("EncodeSymbol(%q) = %q, want %q", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestClient_QuoteEncodesSymbolPath(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	for symbol, want := range map[string]string{
+		"/ES":                   "/marketdata/v1/%2FES/quotes",
+		"EUR/USD":               "/marketdata/v1/EUR%2FUSD/quotes",
+		"AAPL  240809C00095000": "/marketdata/v1/AAPL%20%20240809C00095000/quotes",
+	} {
+		if _, err := client.Quote(context.Background(), symbol, nil, nil); err != nil {
+			t.Fatalf("Quote(%q): %v", symbol, err)
+		}
+		if path != want {
+			t.Errorf("Quote(%q) requested %s, want %s", symbol, path, want)
+		}
+	}
+}
+
+func TestNormalizeSymbol(t *testing.T) {
+	tests := []struct {
+		symbol, want string
+	}{
+		{"aapl", "AAPL"},
+		{" BRK.b ", "BRK.B"},
+		{"$spx", "$SPX"},
+		{"/esz24", "/ESZ24"},
+		{"AAPL  240809C00095000", "AAPL  240809C00095000"},
+		{" aapl  240809c00095000\n", "AAPL  240809C00095000"},
+	}
+	for _, tt := range tests {
+		if got := schwabdev.NormalizeSymbol(tt.symbol); got != tt.want {
+			t.Errorf("NormalizeSymbol(%q) = %q, want %q", tt.symbol, got, tt.want)
+		}
+	}
+}
+
+func TestClient_NormalizesSymbols(t *testing.T) {
+	var requests []*url.URL
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL)
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := newTestClient(t, srv)
+	if _, err := client.Quotes(ctx, []string{" aapl", "brk.b ", ""}, nil, nil); err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	if _, err := client.Quote(ctx, "aapl  240809c00095000", nil, nil); err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if _, err := client.OptionExpirationChain(ctx, " spy"); err != nil {
+		t.Fatalf("OptionExpirationChain: %v", err)
+	}
+	if got := requests[0].Query().Get("symbols"); got != "AAPL,BRK.B" {
+		t.Errorf("Quotes symbols = %q, want AAPL,BRK.B", got)
+	}
+	if got := requests[1].EscapedPath(); got != "/marketdata/v1/AAPL%20%20240809C00095000/quotes" {
+		t.Errorf("Quote path = %q", got)
+	}
+	if got := requests[2].Query().Get("symbol"); got != "SPY" {
+		t.Errorf("OptionExpirationChain symbol = %q, want SPY", got)
+	}
+
+	raw := newTestClient(t, srv, schwabdev.WithSymbolNormalization(false))
+	if _, err := raw.Quotes(ctx, "aapl", nil, nil); err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	if got := requests[3].Query().Get("symbols"); got != "aapl" {
+		t.Errorf("symbols with normalization off = %q, want aapl", got)
+	}
+}
+
+// ── Quote cache ───────────────────────────────────────────────────────────────
+
+// cachingQuotesServer answers quotes requests with one quote per symbol and
+// records the symbols list of every request.
+func cachingQuotesServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		symbols := r.URL.Query().Get("symbols")
+		mu.Lock()
+		requests = append(requests, symbols)
+		mu.Unlock()
+		resp := map[string]schwabdev.Quote{}
+		for _, sym := range strings.Split(symbols, ",") {
+			resp[sym] = schwabdev.Quote{Symbol: sym}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestClient_QuoteCacheServesFreshSymbols(t *testing.T) {
+	srv, requests := cachingQuotesServer(t)
+	client := newTestClient(t, srv)
+	client.EnableQuoteCache(time.Minute)
+	ctx := context.Background()
+
+	if _, err := client.Quotes(ctx, "AAPL,MSFT", nil, nil); err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	resp, err := client.Quotes(ctx, "AAPL,MSFT", nil, nil)
+	if err != nil {
+		t.Fatalf("Quotes (cached): %v", err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("want 1 request within TTL, got %v", *requests)
+	}
+	if (*resp)["MSFT"].Symbol != "MSFT" {
+		t.Errorf("cached response missing MSFT: %v", resp)
+	}
+
+	// Only the uncached symbol is fetched.
+	resp, err = client.Quotes(ctx, "AAPL,IBM", nil, nil)
+	if err != nil {
+		t.Fatalf("Quotes (partial): %v", err)
+	}
+	if got := (*requests)[len(*requests)-1]; got != "IBM" {
+		t.Errorf("want only IBM requested, got %q", got)
+	}
+	if len(*resp) != 2 {
+		t.Errorf("want AAPL and IBM, got %v", resp)
+	}
+
+	// Indicative quotes are cached separately.
+	indicative := true
+	if _, err := client.Quotes(ctx, "AAPL", nil, &indicative); err != nil {
+		t.Fatalf("Quotes (indicative): %v", err)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("want indicative request to miss the cache, got %v", *requests)
+	}
+}
+
+func TestClient_QuoteCacheExpires(t *testing.T) {
+	srv, requests := cachingQuotesServer(t)
+	client := newTestClient(t, srv)
+	client.EnableQuoteCache(20 * time.Millisecond)
+
+	for range 2 {
+		if _, err := client.Quotes(context.Background(), "AAPL", nil, nil); err != nil {
+			t.Fatalf("Quotes: %v", err)
+		}
+		time.Sleep(30 * time.Millisecond)
+	}
+	if len(*requests) != 2 {
+		t.Errorf("want a refetch after expiry, got %v", *requests)
+	}
+}
+
+// ── Account hashes ────────────────────────────────────────────────────────────
+
+func TestClient_AccountHashFor(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path != "/trader/v1/accounts/accountNumbers" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		io.WriteString(w, `[{"accountNumber":"12345678","hashValue":"HASH-A"},`+
+			`{"accountNumber":"87654321","hashValue":"HASH-B"}]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+
+	hash, err := client.AccountHashFor(ctx, "87654321")
+	if err != nil {
+		t.Fatalf("AccountHashFor: %v", err)
+	}
+	if hash != "HASH-B" {
+		t.Errorf("want HASH-B, got %s", hash)
+	}
+
+	// Second lookup is served from the cache.
+	if hash, err := client.AccountHashFor(ctx, "12345678"); err != nil || hash != "HASH-A" {
+		t.Errorf("cached lookup: got %q, %v", hash, err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("want 1 request, got %d", got)
+	}
+
+	if _, err := client.AccountHashFor(ctx, "00000000"); err == nil {
+		t.Error("want error for unlinked account")
+	}
+
+	if err := client.RefreshAccountHashes(ctx); err != nil {
+		t.Fatalf("RefreshAccountHashes: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("want refresh to refetch, got %d requests", got)
+	}
+}
+
+func TestClient_AccountDetailsForFetchesConcurrently(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		if r.URL.Query().Get("fields") != "positions" {
+			t.Errorf("fields: want positions, got %q", r.URL.Query().Get("fields"))
+		}
+		hash := strings.TrimPrefix(r.URL.Path, "/trader/v1/accounts/")
+		if hash == "bad" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"securitiesAccount":{"accountNumber":"acct-%s"}}`, hash)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	details, err := client.AccountDetailsFor(context.Background(), []string{"h1", "h2", "h3"}, schwabdev.AccountFieldPositions)
+	if err != nil {
+		t.Fatalf("AccountDetailsFor: %v", err)
+	}
+	var got []string
+	for _, d := range details {
+		got = append(got, d.SecuritiesAccount.AccountNumber)
+	}
+	if strings.Join(got, ",") != "acct-h1,acct-h2,acct-h3" {
+		t.Errorf("want details in hash order, got %v", got)
+	}
+	if p := peak.Load(); p < 2 {
+		t.Errorf("want concurrent requests, peak in flight was %d", p)
+	}
+
+	details, err = client.AccountDetailsFor(context.Background(), []string{"h1", "bad"}, schwabdev.AccountFieldPositions)
+	if err == nil || !strings.Contains(err.Error(), "account bad") {
+		t.Errorf("want error naming the failed hash, got %v", err)
+	}
+	if len(details) != 1 {
+		t.Errorf("want the successful account alongside the error, got %d", len(details))
+	}
+}
+
+// ── Orders ────────────────────────────────────────────────────────────────────
+
+func TestClient_PlaceOrder_SendsChildOrders(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Location", "/trader/v1/accounts/HASH/orders/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	take, _ := schwabdev.EquitySell("AAPL", 1).Limit("200.00").Build()
+	stop, _ := schwabdev.EquitySell("AAPL", 1).Stop("185.00").Build()
+
+	client := newTestClient(t, srv)
+	if _, err := client.PlaceOrder(context.Background(), "HASH", schwabdev.OneCancelsOther(take, stop)); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	children, _ := body["childOrderStrategies"].([]any)
+	if body["orderStrategyType"] != "OCO" || len(children) != 2 {
+		t.Errorf("unexpected request body: %v", body)
+	}
+}
+
+func TestClient_PlaceOrder_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"message":"insufficient buying power"}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	_, err := client.PlaceOrder(context.Background(), "HASH", order)
+	var apiErr *schwabdev.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.Message != "insufficient buying power" {
+		t.Errorf("want APIError 400 with message, got %v", err)
+	}
+}
+
+func TestClient_PlaceOrderIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if r.Header.Get("X-Fail") != "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("/trader/v1/accounts/HASH/orders/%d", 1000+n))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+
+	first, err := client.PlaceOrderIdempotent(ctx, "HASH", order, "order-1")
+	if err != nil || first.OrderID != "1001" {
+		t.Fatalf("first: %+v, %v", first, err)
+	}
+	again, err := client.PlaceOrderIdempotent(ctx, "HASH", order, "order-1")
+	if err != nil || again.OrderID != "1001" {
+		t.Errorf("duplicate: %+v, %v; want the first result", again, err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("duplicate key sent %d requests, want 1", n)
+	}
+
+	second, err := client.PlaceOrderIdempotent(ctx, "HASH", order, "order-2")
+	if err != nil || second.OrderID != "1002" {
+		t.Errorf("new key: %+v, %v", second, err)
+	}
+
+	// A failure is replayed too, since the order may have reached Schwab.
+	failCtx := schwabdev.WithHeaders(ctx, map[string]string{"X-Fail": "1"})
+	_, firstErr := client.PlaceOrderIdempotent(failCtx, "HASH", order, "order-3")
+	_, againErr := client.PlaceOrderIdempotent(ctx, "HASH", order, "order-3")
+	if firstErr == nil || againErr != firstErr {
+		t.Errorf("duplicate of a failed key: got %v, want %v", againErr, firstErr)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("got %d requests, want 3", n)
+	}
+
+	if _, err := client.PlaceOrderIdempotent(ctx, "HASH", order, ""); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("empty key: want ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestClient_PlaceOrderIdempotent_ForgetsUnsentFailures(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Location", fmt.Sprintf("/trader/v1/accounts/HASH/orders/%d", 1000+n))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+
+	// A rejected order never leaves the client, so its key is released.
+	if _, err := client.PlaceOrderIdempotent(ctx, "HASH", &schwabdev.OrderRequest{}, "invalid"); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Fatalf("invalid order: want ErrInvalidParameter, got %v", err)
+	}
+	if resp, err := client.PlaceOrderIdempotent(ctx, "HASH", order, "invalid"); err != nil || resp.OrderID != "1001" {
+		t.Errorf("retry after invalid order: %+v, %v", resp, err)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.PlaceOrderIdempotent(cancelled, "HASH", order, "cancelled"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled context: want context.Canceled, got %v", err)
+	}
+	if resp, err := client.PlaceOrderIdempotent(ctx, "HASH", order, "cancelled"); err != nil || resp.OrderID != "1002" {
+		t.Errorf("retry after cancelled context: %+v, %v", resp, err)
+	}
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("got %d requests, want 2", n)
+	}
+}
+
+func TestClient_PlaceOrderIdempotent_Concurrent(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("Location", "/trader/v1/accounts/HASH/orders/42")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	var wg sync.WaitGroup
+	ids := make([]string, 5)
+	for i := range ids {
+		wg.Go(func() {
+			resp, err := client.PlaceOrderIdempotent(context.Background(), "HASH", order, "same")
+			if err != nil {
+				t.Errorf("PlaceOrderIdempotent: %v", err)
+				return
+			}
+			ids[i] = resp.OrderID
+		})
+	}
+	for calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("concurrent duplicates sent %d requests, want 1", n)
+	}
+	for i, id := range ids {
+		if id != "42" {
+			t.Errorf("caller %d got order %q, want 42", i, id)
+		}
+	}
+}
+
+func TestClient_PreviewOrder_DecodesResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/trader/v1/accounts/HASH/previewOrder" {
+			t.Errorf("path: %s", r.URL.Path)
+		}
+		io.WriteString(w, `{"orderId": 0, "orderValidationResult": {"rejects": [
+			{"activityMessage": "Market is closed", "originalSeverity": "REJECT"}
+		]}}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	order, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	resp, err := client.PreviewOrder(context.Background(), "HASH", (*schwabdev.PreviewOrderRequest)(order))
+	if err != nil {
+		t.Fatalf("PreviewOrder: %v", err)
+	}
+	if resp.OrderValidationResult == nil || len(resp.OrderValidationResult.Rejects) != 1 ||
+		resp.OrderValidationResult.Rejects[0].ActivityMessage != "Market is closed" {
+		t.Errorf("unexpected preview: %+v", resp)
+	}
+}
+
+func TestClient_PlaceOrder_RejectsInvalidOrder(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	for _, order := range []*schwabdev.OrderRequest{nil, {}} {
+		if _, err := client.PlaceOrder(context.Background(), "HASH", order); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+			t.Errorf("PlaceOrder(%+v): want ErrInvalidParameter, got %v", order, err)
+		}
+	}
+	if got := calls.Load(); got != 0 {
+		t.Errorf("invalid orders reached the server %d times", got)
+	}
+}
+
+func TestClient_PlaceOrder_SendsSchwabDefaultedFields(t *testing.T) {
+	var bodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		w.Header().Set("Location", "/trader/v1/accounts/HASH/orders/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	// Orders with a long-dated duration, or no session and duration at all,
+	// must still reach Schwab.
+	for _, duration := range []string{"END_OF_WEEK", "END_OF_MONTH", "NEXT_END_OF_MONTH", ""} {
+		order, _ := schwabdev.EquityBuy("AAPL", 1).Limit("190.00").Build()
+		order.Session, order.Duration = "", duration
+		if _, err := client.PlaceOrder(context.Background(), "HASH", order); err != nil {
+			t.Errorf("PlaceOrder with duration %q: %v", duration, err)
+		}
+	}
+	if len(bodies) != 4 {
+		t.Fatalf("want 4 orders sent, got %d", len(bodies))
+	}
+	if got := bodies[0]["duration"]; got != "END_OF_WEEK" {
+		t.Errorf("duration: want END_OF_WEEK, got %v", got)
+	}
+}
+
+func TestClient_DeleteWithBody(t *testing.T) {
+	var (
+		gotMethod, gotPath, gotType, gotTrace string
+		gotBody                               []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		gotType, gotTrace = r.Header.Get("Content-Type"), r.Header.Get("X-Trace-Id")
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	body := map[string]any{"orderIds": []int64{1001, 1002}}
+	resp, err := client.DeleteWithBody(context.Background(), "/trader/v1/accounts/HASH/orders",
+		map[string]string{"X-Trace-Id": "cancel-1"}, body)
+	if err != nil {
+		t.Fatalf("DeleteWithBody: %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", resp.StatusCode)
+	}
+	if gotMethod != http.MethodDelete || gotPath != "/trader/v1/accounts/HASH/orders" {
+		t.Errorf("request = %s %s", gotMethod, gotPath)
+	}
+	if string(gotBody) != `{"orderIds":[1001,1002]}` || gotType != "application/json" {
+		t.Errorf("body = %s (Content-Type %q)", gotBody, gotType)
+	}
+	if gotTrace != "cancel-1" {
+		t.Errorf("X-Trace-Id = %q", gotTrace)
+	}
+
+	gotMethod = ""
+	if _, err := client.DeleteWithBody(context.Background(), "https://evil.example/steal", nil, nil); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("absolute URL: want ErrInvalidParameter, got %v", err)
+	}
+	if gotMethod != "" {
+		t.Error("absolute URL reached the server")
+	}
+}
+
+func TestClient_DeleteNotFoundIsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, `{"message":"Order not found"}`)
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv)
+
+	for name, call := range map[string]func() error{
+		"DeleteWithBody": func() error {
+			_, err := client.DeleteWithBody(context.Background(), "/trader/v1/accounts/HASH/orders/42", nil, map[string]int{"id": 42})
+			return err
+		},
+		"CancelOrder": func() error {
+			_, err := client.CancelOrder(context.Background(), "HASH", 42)
+			return err
+		},
+	} {
+		var apiErr *schwabdev.APIError
+		if err := call(); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Message != "Order not found" {
+			t.Errorf("%s: want *APIError with status 404, got %v", name, err)
+		}
+	}
+}
+
+func TestClient_DetailsNotFound(t *testing.T) {
+	for _, body := range []string{"null", ""} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, body)
+		}))
+		client := newTestClient(t, srv)
+
+		if _, err := client.OrderDetails(context.Background(), "HASH", 42); !errors.Is(err, schwabdev.ErrNotFound) {
+			t.Errorf("OrderDetails with body %q: want ErrNotFound, got %v", body, err)
+		}
+		if _, err := client.TransactionDetails(context.Background(), "HASH", 42); !errors.Is(err, schwabdev.ErrNotFound) {
+			t.Errorf("TransactionDetails with body %q: want ErrNotFound, got %v", body, err)
+		}
+		srv.Close()
+	}
+}
+
+func TestClient_CancelAllOpenOrders(t *testing.T) {
+	var (
+		mu        sync.Mutex
+		cancelled []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/trader/v1/accounts/HASH/orders":
+			if r.URL.Query().Get("fromEnteredTime") == "" || r.URL.Query().Get("toEnteredTime") == "" {
+				t.Errorf("missing date range: %s", r.URL.RawQuery)
+			}
+			io.WriteString(w, `[
+				{"orderId": 1, "status": "WORKING"},
+				{"orderId": 2, "status": "FILLED"},
+				{"orderId": 3, "status": "QUEUED"},
+				{"orderId": 4, "status": "CANCELED"},
+				{"orderId": 5, "status": "PENDING_ACTIVATION"},
+				{"orderId": 6, "status": "WORKING"}
+			]`)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/trader/v1/accounts/HASH/orders/"):
+			id := strings.TrimPrefix(r.URL.Path, "/trader/v1/accounts/HASH/orders/")
+			if id == "6" {
+				w.WriteHeader(http.StatusBadRequest)
+				io.WriteString(w, `{"message":"order not cancelable"}`)
+				return
+			}
+			mu.Lock()
+			cancelled = append(cancelled, id)
+			mu.Unlock()
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	got, err := client.CancelAllOpenOrders(context.Background(), "HASH")
+	if err == nil || !strings.Contains(err.Error(), "order 6") {
+		t.Errorf("want an error naming order 6, got %v", err)
+	}
+	if want := []string{"1", "3", "5"}; !slices.Equal(got, want) {
+		t.Errorf("cancelled = %v, want %v", got, want)
+	}
+	slices.Sort(cancelled)
+	if want := []string{"1", "3", "5"}; !slices.Equal(cancelled, want) {
+		t.Errorf("server saw cancels for %v, want %v", cancelled, want)
+	}
+}
+
+func TestOrderStatus_Working(t *testing.T) {
+	working := []schwabdev.OrderStatus{
+		schwabdev.OrderStatusAwaitingParentOrder, schwabdev.OrderStatusAwaitingCondition,
+		schwabdev.OrderStatusAwaitingStopCondition, schwabdev.OrderStatusAwaitingManualReview,
+		schwabdev.OrderStatusAccepted, schwabdev.OrderStatusAwaitingUROut, schwabdev.OrderStatusPendingActivation,
+		schwabdev.OrderStatusQueued, schwabdev.OrderStatusWorking, schwabdev.OrderStatusNew,
+		schwabdev.OrderStatusAwaitingReleaseTime, schwabdev.OrderStatusPendingAcknowledgement,
+		schwabdev.OrderStatusPendingRecall,
+	}
+	done := []schwabdev.OrderStatus{
+		schwabdev.OrderStatusRejected, schwabdev.OrderStatusPendingCancel, schwabdev.OrderStatusCanceled,
+		schwabdev.OrderStatusPendingReplace, schwabdev.OrderStatusReplaced, schwabdev.OrderStatusFilled,
+		schwabdev.OrderStatusExpired, schwabdev.OrderStatusUnknown,
+	}
+	for _, s := range working {
+		if !s.Working() {
+			t.Errorf("%s: want working", s)
+		}
+	}
+	for _, s := range done {
+		if s.Working() {
+			t.Errorf("%s: want not working", s)
+		}
+	}
+	if schwabdev.OrderStatus("FILLED") != schwabdev.OrderStatusFilled {
+		t.Error("OrderStatusFilled does not match the API value")
+	}
+}
+
+func TestClient_AccountOrdersByStatuses(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		statuses []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := r.URL.Query().Get("status")
+		mu.Lock()
+		statuses = append(statuses, status)
+		mu.Unlock()
+		switch status {
+		case "WORKING":
+			io.WriteString(w, `[
+				{"orderId": 3, "status": "WORKING", "enteredTime": "2024-06-03T14:00:00+0000"},
+				{"orderId": 1, "status": "WORKING", "enteredTime": "2024-06-01T14:00:00+0000"}
+			]`)
+		case "FILLED":
+			// Order 1 filled between the two requests and shows up in both.
+			io.WriteString(w, `[
+				{"orderId": 2, "status": "FILLED", "enteredTime": "2024-06-02T14:00:00+0000"},
+				{"orderId": 1, "status": "FILLED", "enteredTime": "2024-06-01T14:00:00+0000"}
+			]`)
+		default:
+			io.WriteString(w, `[]`)
+		}
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	resp, err := client.AccountOrdersByStatuses(context.Background(), "HASH",
+		[]schwabdev.OrderStatus{schwabdev.OrderStatusWorking, schwabdev.OrderStatusFilled, schwabdev.OrderStatusWorking,
+			schwabdev.OrderStatusCanceled}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("AccountOrdersByStatuses: %v", err)
+	}
+
+	var ids []int64
+	for _, o := range *resp {
+		ids = append(ids, o.OrderID)
+	}
+	if !slices.Equal(ids, []int64{3, 2, 1}) {
+		t.Errorf("order IDs = %v, want [3 2 1]", ids)
+	}
+	slices.Sort(statuses)
+	if !slices.Equal(statuses, []string{"CANCELED", "FILLED", "WORKING"}) {
+		t.Errorf("requested statuses = %v", statuses)
+	}
+
+	for _, bad := range [][]schwabdev.OrderStatus{nil, {"DONE"}} {
+		if _, err := client.AccountOrdersByStatuses(context.Background(), "HASH", bad, nil, nil, nil); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+			t.Errorf("statuses %v: want ErrInvalidParameter, got %v", bad, err)
+		}
+	}
+}
+
+func TestClient_AccountOrdersPaged(t *testing.T) {
+	// Each window returns its own orders; order 2 straddles two windows.
+	pages := map[string]string{
+		"2024-01-01T00:00:00.000Z": `[{"orderId":1,"status":"FILLED"},{"orderId":2,"status":"WORKING"}]`,
+		"2024-01-31T00:00:00.000Z": `[{"orderId":2,"status":"WORKING"},{"orderId":3,"status":"CANCELED"}]`,
+		"2024-03-01T00:00:00.000Z": `[{"orderId":4,"status":"FILLED"}]`,
+	}
+	var windows []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		windows = append(windows, r.URL.Path+" "+q.Get("fromEnteredTime")+"/"+q.Get("toEnteredTime"))
+		io.WriteString(w, pages[q.Get("fromEnteredTime")])
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	resp, err := client.AccountOrdersPaged(context.Background(), "HASH", start, end, 0, nil, nil)
+	if err != nil {
+		t.Fatalf("AccountOrdersPaged: %v", err)
+	}
+
+	wantWindows := []string{
+		"/trader/v1/accounts/HASH/orders 2024-01-01T00:00:00.000Z/2024-01-31T00:00:00.000Z",
+		"/trader/v1/accounts/HASH/orders 2024-01-31T00:00:00.000Z/2024-03-01T00:00:00.000Z",
+		"/trader/v1/accounts/HASH/orders 2024-03-01T00:00:00.000Z/2024-03-15T00:00:00.000Z",
+	}
+	if strings.Join(windows, "\n") != strings.Join(wantWindows, "\n") {
+		t.Errorf("windows:\n got %v\nwant %v", windows, wantWindows)
+	}
+
+	var ids []string
+	for _, o := range *resp {
+		ids = append(ids, strconv.FormatInt(o.OrderID, 10))
+	}
+	if got := strings.Join(ids, ","); got != "1,2,3,4" {
+		t.Errorf("want 1,2,3,4, got %s", got)
+	}
+}
+
+func TestClient_AccountOrdersAllPaged(t *testing.T) {
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		io.WriteString(w, `[{"orderId":7,"status":"FILLED"}]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resp, err := client.AccountOrdersAllPaged(context.Background(), start, start.AddDate(0, 0, 20), 7*24*time.Hour, nil, nil)
+	if err != nil {
+		t.Fatalf("AccountOrdersAllPaged: %v", err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("want 3 windows, got %d", len(paths))
+	}
+	for _, p := range paths {
+		if p != "/trader/v1/orders" {
+			t.Errorf("want /trader/v1/orders, got %s", p)
+		}
+	}
+	if len(*resp) != 1 || (*resp)[0].OrderID != 7 {
+		t.Errorf("want one de-duplicated order 7, got %+v", *resp)
+	}
+}
+
+func TestClient_AccountOrdersPaged_StopsOnError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := client.AccountOrdersPaged(context.Background(), "HASH", start, start.AddDate(0, 6, 0), 0, nil, nil)
+	if err == nil {
+		t.Fatal("want error from failed window")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("want 2 requests before stopping, got %d", got)
+	}
+
+	_, err = client.AccountOrdersPaged(context.Background(), "HASH", start, start.AddDate(0, -1, 0), 0, nil, nil)
+	if !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("start after end: want ErrInvalidParameter, got %v", err)
+	}
+}
+
+// ── Transactions ──────────────────────────────────────────────────────────────
+
+func TestClient_TransactionsPaged(t *testing.T) {
+	// Each window returns its own transactions; T2 straddles two windows.
+	pages := map[string]string{
+		"2024-01-01T00:00:00.000Z": `[{"transactionId":"T1","type":"TRADE"},{"transactionId":"T2","type":"TRADE"}]`,
+		"2024-01-31T00:00:00.000Z": `[{"transactionId":"T2","type":"TRADE"},{"transactionId":"T3","type":"DIVIDEND_OR_INTEREST"}]`,
+		"2024-03-01T00:00:00.000Z": `[{"transactionId":"T4","type":"TRADE"}]`,
+	}
+	var windows []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		windows = append(windows, q.Get("startDate")+"/"+q.Get("endDate"))
+		io.WriteString(w, pages[q.Get("startDate")])
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	resp, err := client.TransactionsPaged(context.Background(), "HASH", start, end, 0, "TRADE", nil)
+	if err != nil {
+		t.Fatalf("TransactionsPaged: %v", err)
+	}
+
+	wantWindows := []string{
+		"2024-01-01T00:00:00.000Z/2024-01-31T00:00:00.000Z",
+		"2024-01-31T00:00:00.000Z/2024-03-01T00:00:00.000Z",
+		"2024-03-01T00:00:00.000Z/2024-03-15T00:00:00.000Z",
+	}
+	if strings.Join(windows, " ") != strings.Join(wantWindows, " ") {
+		t.Errorf("windows:\n got %v\nwant %v", windows, wantWindows)
+	}
+
+	var ids []string
+	for _, txn := range *resp {
+		ids = append(ids, txn.TransactionID)
+	}
+	if got := strings.Join(ids, ","); got != "T1,T2,T3,T4" {
+		t.Errorf("want T1,T2,T3,T4, got %s", got)
+	}
+}
+
+func TestClient_TransactionsPaged_StopsOnError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := client.TransactionsPaged(context.Background(), "HASH", start, start.AddDate(0, 6, 0), 0, "TRADE", nil)
+	if err == nil {
+		t.Fatal("want error from failed window")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("want 2 requests before stopping, got %d", got)
+	}
+}
+
+// ── Token refresh ─────────────────────────────────────────────────────────────
+
+// oauthServer issues "fresh-token" for every refresh grant and counts them.
+func oauthServer(t *testing.T, grants *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		grants.Add(1)
+		io.WriteString(w, `{"access_token":"fresh-token","refresh_token":"test-refresh-token","expires_in":1800}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// preferencesServer answers userPreference only for the fresh token and
+// rejects anything else with 401.
+func preferencesServer(t *testing.T, calls *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.Header.Get("Authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, `{"streamerInfo":[{"streamerSocketUrl":"wss://streamer.example","schwabClientCustomerId":"C1"}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClient_GetStreamerInfo_RefreshesAndRetriesOn401(t *testing.T) {
+	var grants, calls atomic.Int32
+	client := newTestClient(t, preferencesServer(t, &calls), schwabdev.WithTokenURL(oauthServer(t, &grants).URL))
+
+	info, err := client.GetStreamerInfo(context.Background())
+	if err != nil {
+		t.Fatalf("GetStreamerInfo: %v", err)
+	}
+	if info.StreamerURL != "wss://streamer.example" {
+		t.Errorf("unexpected streamer info: %+v", info)
+	}
+	if grants.Load() != 1 || calls.Load() != 2 {
+		t.Errorf("want one refresh and one retry, got %d refreshes and %d calls", grants.Load(), calls.Load())
+	}
+}
+
+func TestClient_GetStreamerInfo_RefreshesProactively(t *testing.T) {
+	var grants, calls atomic.Int32
+	// The access token is already inside AccessTokenRefreshThreshold.
+	client := newTestClientWithTokens(t, preferencesServer(t, &calls), schwabdev.TokenRecord{
+		AccessTokenIssued:  time.Now().UTC(),
+		RefreshTokenIssued: time.Now().UTC(),
+		AccessToken:        testAccessToken,
+		RefreshToken:       "test-refresh-token",
+		ExpiresIn:          int(schwabdev.AccessTokenRefreshThreshold.Seconds()) - 10,
+	}, schwabdev.WithTokenURL(oauthServer(t, &grants).URL))
+
+	if _, err := client.GetStreamerInfo(context.Background()); err != nil {
+		t.Fatalf("GetStreamerInfo: %v", err)
+	}
+	if grants.Load() != 1 || calls.Load() != 1 {
+		t.Errorf("want the token refreshed before the only call, got %d refreshes and %d calls", grants.Load(), calls.Load())
+	}
+}
+
+func TestClient_TransactionsBetween_FormatsDates(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	est := time.FixedZone("EST", -5*60*60)
+	start := time.Date(2026, 1, 2, 9, 30, 15, 123456789, est)
+	end := time.Date(2026, 1, 31, 16, 0, 0, 0, est)
+	if _, err := client.TransactionsBetween(context.Background(), "HASH", start, end, "TRADE", nil); err != nil {
+		t.Fatalf("TransactionsBetween: %v", err)
+	}
+	if got := query.Get("startDate"); got != "2026-01-02T14:30:15.123Z" {
+		t.Errorf("startDate = %s", got)
+	}
+	if got := query.Get("endDate"); got != "2026-01-31T21:00:00.000Z" {
+		t.Errorf("endDate = %s", got)
+	}
+}
+
+func TestClient_Transactions_RejectsReversedRange(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, -1, 0)
+
+	if _, err := client.TransactionsBetween(ctx, "HASH", from, to, "TRADE", nil); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("TransactionsBetween: want ErrInvalidParameter, got %v", err)
+	}
+	if _, err := client.TransactionsBetween(ctx, "HASH", time.Time{}, to, "TRADE", nil); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("TransactionsBetween zero start: want ErrInvalidParameter, got %v", err)
+	}
+	if _, err := client.Transactions(ctx, "HASH", "2026-03-01", "2026-02-01T00:00:00Z", "TRADE", nil); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("Transactions with strings: want ErrInvalidParameter, got %v", err)
+	}
+	if _, err := client.TransactionsPaged(ctx, "HASH", from, to, 0, "TRADE", nil); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("TransactionsPaged: want ErrInvalidParameter, got %v", err)
+	}
+	if _, err := client.Transactions(ctx, "HASH", "invalid-date", to, "TRADE", nil); err == nil {
+		t.Error("Transactions: want error for unparseable date")
+	}
+	if got := calls.Load(); got != 0 {
+		t.Errorf("invalid ranges reached the server %d times", got)
+	}
+}
+
+func TestClient_TransactionsOfType(t *testing.T) {
+	var queries []url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	ctx := context.Background()
+	from := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	if _, err := client.TransactionsOfType(ctx, "HASH", from, to, schwabdev.TransactionTypeDividendOrInterest, nil); err != nil {
+		t.Fatalf("TransactionsOfType: %v", err)
+	}
+	if len(queries) != 1 || queries[0].Get("types") != "DIVIDEND_OR_INTEREST" {
+		t.Fatalf("queries = %v", queries)
+	}
+
+	for _, txnType := range []schwabdev.TransactionType{"TRADE,DIVIDEND", "DIVIDEND", "trade", ""} {
+		_, err := client.TransactionsOfType(ctx, "HASH", from, to, txnType, nil)
+		if !errors.Is(err, schwabdev.ErrInvalidParameter) || !strings.Contains(err.Error(), "types") {
+			t.Errorf("%q: want ErrInvalidParameter naming types, got %v", txnType, err)
+		}
+	}
+	if len(queries) != 1 {
+		t.Errorf("invalid types reached the server: %v", queries[1:])
+	}
+
+	// Transactions passes unknown values through unchecked.
+	if _, err := client.Transactions(ctx, "HASH", from, to, "NEW_TYPE", nil); err != nil {
+		t.Fatalf("Transactions: %v", err)
+	}
+	if got := queries[len(queries)-1].Get("types"); got != "NEW_TYPE" {
+		t.Errorf("raw types = %q, want NEW_TYPE", got)
+	}
+}
+
+// ── Request IDs ───────────────────────────────────────────────────────────────
+
+func TestClient_RequestIDInLogsAndErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := newTestClient(t, srv, schwabdev.WithLogger(logger))
+
+	ctx := schwabdev.WithRequestID(context.Background(), "req-42")
+	_, err := client.Movers(ctx, "$SPX", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "req-42") {
+		t.Fatalf("want error mentioning req-42, got %v", err)
+	}
+
+	var sent, failed bool
+	for line := range strings.Lines(logs.String()) {
+		var entry map[string]any
+		if json.Unmarshal([]byte(line), &entry) != nil || entry["request_id"] != "req-42" {
+			continue
+		}
+		switch entry["msg"] {
+		case "Sending request":
+			sent = true
+		case "Request failed":
+			failed = true
+		}
+	}
+	if !sent || !failed {
+		t.Errorf("want request and failure lines tagged req-42, got:\n%s", logs.String())
+	}
+}
+
+func TestClient_GeneratesRequestID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	_, err1 := client.Movers(context.Background(), "$SPX", nil, nil)
+	_, err2 := client.Movers(context.Background(), "$SPX", nil, nil)
+	if err1 == nil || err2 == nil || err1.Error() == err2.Error() {
+		t.Errorf("want distinct generated IDs, got %v / %v", err1, err2)
+	}
+}
+
+// ── Headers ───────────────────────────────────────────────────────────────────
+
+func TestClient_DefaultAndPerCallHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	client.SetDefaultHeaders(map[string]string{
+		"User-Agent":    "my-dashboard/1.0",
+		"X-Trace-Id":    "default-trace",
+		"Authorization": "Bearer hijacked",
+	})
+
+	if _, err := client.Movers(context.Background(), "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers: %v", err)
+	}
+	if got.Get("User-Agent") != "my-dashboard/1.0" || got.Get("X-Trace-Id") != "default-trace" {
+		t.Errorf("default headers missing: %v", got)
+	}
+	if got.Get("Authorization") != "Bearer "+testAccessToken {
+		t.Errorf("Authorization overridden: %q", got.Get("Authorization"))
+	}
+
+	ctx := schwabdev.WithHeaders(context.Background(), map[string]string{"x-trace-id": "call-trace"})
+	if _, err := client.Movers(ctx, "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers: %v", err)
+	}
+	if got.Get("X-Trace-Id") != "call-trace" || got.Get("User-Agent") != "my-dashboard/1.0" {
+		t.Errorf("per-call header should override default only: %v", got)
+	}
+}
+
+func TestClient_WithResponseHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Schwab-Client-CorrelId", "corr-"+r.URL.Query().Get("symbols"))
+		if r.URL.Query().Get("symbols") == "BAD" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	ctx, headers := schwabdev.WithResponseHeaders(context.Background())
+	if headers.Header() != nil || headers.CorrelID() != "" {
+		t.Errorf("headers before any call = %v", headers.Header())
+	}
+	if _, err := client.Quotes(ctx, "AAPL", nil, nil); err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	if got := headers.CorrelID(); got != "corr-AAPL" {
+		t.Errorf("CorrelID = %q, want corr-AAPL", got)
+	}
+	if got := headers.Header().Get("Content-Type"); got == "" {
+		t.Errorf("Header() missing Content-Type: %v", headers.Header())
+	}
+
+	if _, err := client.Quotes(ctx, "BAD", nil, nil); err == nil {
+		t.Fatal("want an error for BAD")
+	}
+	if got := headers.CorrelID(); got != "corr-BAD" {
+		t.Errorf("CorrelID after failure = %q, want corr-BAD", got)
+	}
+}
+
+func TestClient_DecodesCompressedResponses(t *testing.T) {
+	const body = `{"AAPL":{"symbol":"AAPL","assetMainType":"EQUITY"}}`
+	compress := map[string]func(io.Writer) io.WriteCloser{
+		"gzip":    func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) },
+		"deflate": func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) },
+		"raw deflate": func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		},
+	}
+	for name, newWriter := range compress {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("Content-Encoding", strings.TrimPrefix(name, "raw "))
+				zw := newWriter(w)
+				io.WriteString(zw, body)
+				zw.Close()
+			}))
+			defer srv.Close()
+
+			client := newTestClient(t, srv)
+			// A caller-set Accept-Encoding stops net/http from decompressing.
+			client.SetDefaultHeaders(map[string]string{"Accept-Encoding": "gzip, deflate"})
+			quotes, err := client.Quotes(context.Background(), "AAPL", nil, nil)
+			if err != nil {
+				t.Fatalf("Quotes: %v", err)
+			}
+			if q, ok := (*quotes)["AAPL"]; !ok || q.Symbol != "AAPL" {
+				t.Errorf("quotes = %+v", quotes)
+			}
+		})
+	}
+}
+
+// ── Logging ───────────────────────────────────────────────────────────────────
+
+func TestClient_InfoLevelLogsOnlyFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("symbols") == "BAD" {
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{"message":"bad symbol"}`)
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	client := newTestClient(t, srv, schwabdev.WithLogger(logger))
+
+	if _, err := client.Quotes(context.Background(), "AAPL", nil, nil); err != nil {
+		t.Fatalf("Quotes: %v", err)
+	}
+	if out := buf.String(); out != "" {
+		t.Errorf("successful request logged at Info:\n%s", out)
+	}
+
+	if _, err := client.Quotes(context.Background(), "BAD", nil, nil); err == nil {
+		t.Fatal("want an error for BAD")
+	}
+	if out := buf.String(); !strings.Contains(out, "Request failed") || !strings.Contains(out, "level=WARN") {
+		t.Errorf("failed request not logged at Warn:\n%s", out)
+	}
+}
+
+func TestClient_LogRedactsAccountHashes(t *testing.T) {
+	const hash = "E5B0C2F1A7D94C3B8E6F0A1B2C3D4E5F6A7B8C9D0E1F2A3B4C5D6E7F8A9B5A2F"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, `{"message":"account %s is restricted"}`, hash)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := newTestClient(t, srv, schwabdev.WithLogger(logger))
+
+	_, err := client.AccountDetails(context.Background(), hash, nil)
+	if err == nil {
+		t.Fatal("want an error")
+	}
+	out := buf.String()
+	if strings.Contains(out, hash) {
+		t.Errorf("full account hash logged:\n%s", out)
+	}
+	if !strings.Contains(out, "/trader/v1/accounts/****5A2F") || !strings.Contains(out, "account ****5A2F is restricted") {
+		t.Errorf("masked hash missing from path or error:\n%s", out)
+	}
+	if !strings.Contains(err.Error(), hash) {
+		t.Errorf("returned error should not be redacted: %v", err)
+	}
+
+	buf.Reset()
+	client.SetLogRedaction(false)
+	client.AccountDetails(context.Background(), hash, nil)
+	if !strings.Contains(buf.String(), hash) {
+		t.Errorf("full hash should be logged with redaction off:\n%s", buf.String())
+	}
+
+	for id, want := range map[string]string{"12345678": "****5678", "1234": "****", "": "****"} {
+		if got := schwabdev.MaskAccountID(id); got != want {
+			t.Errorf("MaskAccountID(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+// ── Observer ──────────────────────────────────────────────────────────────────
+
+func TestClient_SetObserver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "$BOGUS") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, `{"screeners":[]}`)
+	}))
+	defer srv.Close()
+
+	type report struct {
+		method, url string
+		status      int
+		dur         time.Duration
+		err         error
+	}
+	var reports []report
+	client := newTestClient(t, srv)
+	client.SetObserver(func(method, url string, status int, dur time.Duration, err error) {
+		reports = append(reports, report{method, url, status, dur, err})
+	})
+
+	if _, err := client.Movers(context.Background(), "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers: %v", err)
+	}
+	client.Movers(context.Background(), "$BOGUS", nil, nil)
+	client.SetObserver(nil)
+	client.Movers(context.Background(), "$DJI", nil, nil)
+
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2: %+v", len(reports), reports)
+	}
+	ok := reports[0]
+	if ok.method != http.MethodGet || ok.url != srv.URL+"/marketdata/v1/movers/$SPX" || ok.status != http.StatusOK || ok.dur <= 0 || ok.err != nil {
+		t.Errorf("success report = %+v", ok)
+	}
+	failed := reports[1]
+	var apiErr *schwabdev.APIError
+	if failed.status != http.StatusNotFound || !errors.As(failed.err, &apiErr) {
+		t.Errorf("failure report = %+v", failed)
+	}
+}
+
+// ── Rate limiting ─────────────────────────────────────────────────────────────
+
+func TestClient_SetRateLimit_SpacesRequests(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		times []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	client.SetRateLimit(20, 1)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			if _, err := client.Movers(context.Background(), "$SPX", nil, nil); err != nil {
+				t.Errorf("Movers: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(times) != 4 {
+		t.Fatalf("requests = %d, want 4", len(times))
+	}
+	slices.SortFunc(times, time.Time.Compare)
+	// 4 requests at 20/s with a burst of 1 need three 50ms waits.
+	if span := times[3].Sub(times[0]); span < 130*time.Millisecond {
+		t.Errorf("4 requests spanned %v, want >= ~150ms", span)
+	}
+}
+
+func TestClient_SetRateLimit_RespectsContext(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	client.SetRateLimit(0.1, 1)
+	if _, err := client.Movers(context.Background(), "$SPX", nil, nil); err != nil {
+		t.Fatalf("first Movers: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Movers(ctx, "$SPX", nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v after the deadline", elapsed)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("server calls = %d, want 1", n)
+	}
+
+	client.SetRateLimit(0, 0)
+	if _, err := client.Movers(context.Background(), "$SPX", nil, nil); err != nil {
+		t.Fatalf("Movers after removing limit: %v", err)
+	}
+}
+
+// ── Parameter validation ──────────────────────────────────────────────────────
+
+func TestClient_MoversForRejectsUnknownValues(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	for name, call := range map[string]func() error{
+		"index": func() error {
+			_, err := client.MoversFor(context.Background(), "$BOGUS", "", schwabdev.MoverFrequency0)
+			return err
+		},
+		"sort": func() error {
+			_, err := client.MoversFor(context.Background(), schwabdev.MoverIndexSPX, "SIDEWAYS", schwabdev.MoverFrequency0)
+			return err
+		},
+		"frequency": func() error {
+			_, err := client.MoversFor(context.Background(), schwabdev.MoverIndexSPX, "", 7)
+			return err
+		},
+	} {
+		if err := call(); !errors.Is(err, schwabdev.ErrInvalidParameter) || !strings.Contains(err.Error(), name) {
+			t.Errorf("%s: want ErrInvalidParameter naming %s, got %v", name, name, err)
+		}
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("want no HTTP calls, got %d", n)
+	}
+}
+
+func TestClient_MoversForSendsTypedParams(t *testing.T) {
+	var gotPath, gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		io.WriteString(w, `{"screeners":[]}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	if _, err := client.MoversFor(context.Background(), schwabdev.MoverIndexDJI, schwabdev.MoverSortPercentChangeDown, schwabdev.MoverFrequency5); err != nil {
+		t.Fatalf("MoversFor: %v", err)
+	}
+	if gotPath != "/marketdata/v1/movers/$DJI" || gotQuery != "frequency=5&sort=PERCENT_CHANGE_DOWN" {
+		t.Errorf("got %s?%s", gotPath, gotQuery)
+	}
+}
+
+func TestClient_OptionChainsForExpiration(t *testing.T) {
+	var calls atomic.Int32
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		query = r.URL.Query()
+		io.WriteString(w, `{"symbol":"SPY","status":"SUCCESS"}`)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	if _, err := client.OptionChainsForExpiration(context.Background(), "spy", "2024-08-16", 10); err != nil {
+		t.Fatalf("OptionChainsForExpiration: %v", err)
+	}
+	want := url.Values{
+		"symbol":       {"SPY"},
+		"contractType": {"ALL"},
+		"strategy":     {"SINGLE"},
+		"range":        {"NTM"},
+		"strikeCount":  {"10"},
+		"fromDate":     {"2024-08-16"},
+		"toDate":       {"2024-08-16"},
+	}
+	if query.Encode() != want.Encode() {
+		t.Errorf("query = %s, want %s", query.Encode(), want.Encode())
+	}
+
+	for _, tt := range []struct {
+		expiration  string
+		strikeCount int
+		field       string
+	}{
+		{"08/16/2024", 10, "expiration"},
+		{"", 10, "expiration"},
+		{"2024-08-16", 0, "strikeCount"},
+	} {
+		_, err := client.OptionChainsForExpiration(context.Background(), "SPY", tt.expiration, tt.strikeCount)
+		if !errors.Is(err, schwabdev.ErrInvalidParameter) || !strings.Contains(err.Error(), tt.field) {
+			t.Errorf("(%q, %d): want ErrInvalidParameter naming %s, got %v", tt.expiration, tt.strikeCount, tt.field, err)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("want 1 HTTP call, got %d", n)
+	}
+}
+
+func TestClient_SearchInstruments(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		io.WriteString(w, `[]`)
+	}))
+	defer srv.Close()
+	client := newTestClient(t, srv)
+
+	tests := []struct {
+		query      string
+		projection schwabdev.InstrumentProjection
+		want       string
+	}{
+		{"AAP.*", schwabdev.ProjectionSymbolRegex, "projection=symbol-regex&symbol=AAP.%2A"},
+		{"AAPL,MSFT", schwabdev.ProjectionFundamental, "projection=fundamental&symbol=AAPL%2CMSFT"},
+		{"Apple Inc", schwabdev.ProjectionDescSearch, "projection=desc-search&symbol=Apple+Inc"},
+	}
+	for _, tt := range tests {
+		if _, err := client.SearchInstruments(context.Background(), tt.query, tt.projection); err != nil {
+			t.Fatalf("SearchInstruments(%q, %s): %v", tt.query, tt.projection, err)
+		}
+		if query != tt.want {
+			t.Errorf("SearchInstruments(%q, %s) query = %s, want %s", tt.query, tt.projection, query, tt.want)
+		}
+	}
+
+	query = ""
+	if _, err := client.SearchInstruments(context.Background(), "AAPL", "symbol"); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("unknown projection: want ErrInvalidParameter, got %v", err)
+	}
+	if _, err := client.SearchInstruments(context.Background(), "", schwabdev.ProjectionSymbolSearch); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("empty query: want ErrInvalidParameter, got %v", err)
+	}
+	if query != "" {
+		t.Errorf("invalid searches reached the server: %s", query)
+	}
+}
+
+func TestValidatePriceHistory(t *testing.T) {
+	tests := []struct {
+		name          string
+		periodType    schwabdev.PeriodType
+		period        int
+		frequencyType schwabdev.FrequencyType
+		frequency     int
+		field         string // offending field, "" if valid
+	}{
+		{"day minute", schwabdev.PeriodTypeDay, 10, schwabdev.FrequencyTypeMinute, 5, ""},
+		{"year monthly", schwabdev.PeriodTypeYear, 20, schwabdev.FrequencyTypeMonthly, 1, ""},
+		{"ytd weekly", schwabdev.PeriodTypeYTD, 1, schwabdev.FrequencyTypeWeekly, 1, ""},
+		{"all unset", "", 0, "", 0, ""},
+		{"frequency only", "", 0, schwabdev.FrequencyTypeDaily, 1, ""},
+		{"unknown periodType", "week", 1, "", 0, "periodType"},
+		{"day with daily", schwabdev.PeriodTypeDay, 1, schwabdev.FrequencyTypeDaily, 1, "frequencyType"},
+		{"month period 4", schwabdev.PeriodTypeMonth, 4, schwabdev.FrequencyTypeDaily, 1, "period"},
+		{"ytd monthly", schwabdev.PeriodTypeYTD, 1, schwabdev.FrequencyTypeMonthly, 1, "frequencyType"},
+		{"minute 2", schwabdev.PeriodTypeDay, 1, schwabdev.FrequencyTypeMinute, 2, "frequency"},
+		{"weekly 5", schwabdev.PeriodTypeMonth, 1, schwabdev.FrequencyTypeWeekly, 5, "frequency"},
+		{"unknown frequencyType", "", 0, "hourly", 1, "frequencyType"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schwabdev.ValidatePriceHistory(tt.periodType, tt.period, tt.frequencyType, tt.frequency)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("want valid, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, schwabdev.ErrInvalidParameter) || !strings.Contains(err.Error(), ": "+tt.field+" ") {
+				t.Fatalf("want ErrInvalidParameter naming %s, got %v", tt.field, err)
+			}
+		})
+	}
+}
+
+func TestClient_PriceHistoryRange(t *testing.T) {
+	var (
+		mu               sync.Mutex
+		windows          [][2]int64
+		inFlight, peak   int
+		badPeriodOrFreqs []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		from, _ := strconv.ParseInt(q.Get("startDate"), 10, 64)
+		to, _ := strconv.ParseInt(q.Get("endDate"), 10, 64)
+		mu.Lock()
+		windows = append(windows, [2]int64{from, to})
+		if q.Get("periodType") != "day" || q.Get("frequencyType") != "minute" || q.Get("frequency") != "5" {
+			badPeriodOrFreqs = append(badPeriodOrFreqs, r.URL.RawQuery)
+		}
+		inFlight++
+		peak = max(peak, inFlight)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		// Newest first, with both window bounds and a null entry, so merging
+		// must skip nils, sort and drop the candles shared by adjacent windows.
+		fmt.Fprintf(w, `{"symbol":"AAPL","empty":false,"candles":[{"close":2,"datetime":%d},null,{"close":1,"datetime":%d}]}`, to, from)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(65 * 24 * time.Hour)
+	resp, err := client.PriceHistoryRange(context.Background(), "AAPL", start, end, schwabdev.FrequencyTypeMinute, 5)
+	if err != nil {
+		t.Fatalf("PriceHistoryRange: %v", err)
+	}
+
+	// 65 days of minute candles take seven 10-day windows.
+	if len(windows) != 7 {
+		t.Fatalf("requests = %d, want 7: %v", len(windows), windows)
+	}
+	if peak > schwabdev.PriceHistoryConcurrency {
+		t.Errorf("%d requests in flight, want at most %d", peak, schwabdev.PriceHistoryConcurrency)
+	}
+	if len(badPeriodOrFreqs) != 0 {
+		t.Errorf("unexpected parameters: %v", badPeriodOrFreqs)
+	}
+	var want []int64
+	for d := 0; d < 65; d += 10 {
+		want = append(want, start.Add(time.Duration(d)*24*time.Hour).UnixMilli())
+	}
+	want = append(want, end.UnixMilli())
+	var got []int64
+	for _, c := range resp.Candles {
+		got = append(got, c.Datetime)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("candle times = %v, want %v", got, want)
+	}
+	if resp.Symbol != "AAPL" || resp.Empty {
+		t.Errorf("response = %+v", resp)
+	}
+
+	windows = nil
+	if _, err := client.PriceHistoryRange(context.Background(), "AAPL", end, start, schwabdev.FrequencyTypeDaily, 1); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("reversed range: want ErrInvalidParameter, got %v", err)
+	}
+	if _, err := client.PriceHistoryRange(context.Background(), "AAPL", start, end, schwabdev.FrequencyTypeMinute, 2); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("minute frequency 2: want ErrInvalidParameter, got %v", err)
+	}
+	if len(windows) != 0 {
+		t.Errorf("invalid ranges reached the server: %v", windows)
+	}
+}
+
+func TestClient_PriceHistoryRangeReportsWindowError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("periodType") != "year" {
+			t.Errorf("periodType = %q, want year", r.URL.Query().Get("periodType"))
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	resp, err := client.PriceHistoryRange(context.Background(), "AAPL", start, start.AddDate(30, 0, 0), schwabdev.FrequencyTypeDaily, 1)
+	var apiErr *schwabdev.APIError
+	if resp != nil || !errors.As(err, &apiErr) {
+		t.Fatalf("want nil response and *APIError, got %v, %v", resp, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled windows should not be reported: %v", err)
+	}
+}
+
+func TestClient_PriceHistoryRangeCancelsOnWindowError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		// Hold the other windows until the failure cancels them.
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+			t.Error("in-flight window was not cancelled")
+		}
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := client.PriceHistoryRange(context.Background(), "AAPL", start, start.Add(65*24*time.Hour), schwabdev.FrequencyTypeMinute, 5)
+	var apiErr *schwabdev.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("want *APIError with status 500, got %v", err)
+	}
+	if n := strings.Count(err.Error(), "window "); n != 1 {
+		t.Errorf("want only the failed window reported, got %d: %v", n, err)
+	}
+	// Seven windows, but those still queued behind the semaphore are skipped.
+	if n := calls.Load(); n > schwabdev.PriceHistoryConcurrency {
+		t.Errorf("%d windows requested, want at most %d", n, schwabdev.PriceHistoryConcurrency)
+	}
+}
+
+func TestClient_PriceHistoryRejectsInvalidCombination(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(t, srv)
+	periodType, frequencyType := "day", "monthly"
+	_, err := client.PriceHistory(context.Background(), "AAPL", &periodType, nil, &frequencyType, nil, nil, nil, nil, nil)
+	if !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Fatalf("want ErrInvalidParameter, got %v", err)
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("want no HTTP calls, got %d", n)
+	}
+}
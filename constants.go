@@ -9,6 +9,73 @@ const (
 
 	// OAuthTokenRequestTimeout is the timeout for OAuth token request operations
 	OAuthTokenRequestTimeout = 30 * time.Second
+
+	// DefaultRetryAfter is the wait before retrying a throttled request when
+	// the response carries no usable Retry-After header
+	DefaultRetryAfter = 1 * time.Second
+
+	// NetworkRetryBaseDelay is the wait before the first retry of a request
+	// that failed with a transient network error; it doubles per attempt
+	NetworkRetryBaseDelay = 200 * time.Millisecond
+
+	// NetworkRetryMaxDelay caps the wait between network-error retries
+	NetworkRetryMaxDelay = 5 * time.Second
+)
+
+// Account Constants
+const (
+	// DefaultTransactionsWindow is the date range covered by each request
+	// TransactionsPaged makes
+	DefaultTransactionsWindow = 30 * 24 * time.Hour
+
+	// DefaultOrdersWindow is the date range covered by each request
+	// AccountOrdersPaged and AccountOrdersAllPaged make
+	DefaultOrdersWindow = 30 * 24 * time.Hour
+
+	// AccountDetailsConcurrency is the maximum number of account details
+	// requests AccountDetailsFor has in flight at once
+	AccountDetailsConcurrency = 4
+
+	// CancelOrdersConcurrency is the maximum number of cancel requests
+	// CancelAllOpenOrders has in flight at once
+	CancelOrdersConcurrency = 4
+
+	// OpenOrdersLookback is how far back CancelAllOpenOrders looks for
+	// working orders; Schwab serves at most 60 days of order history
+	OpenOrdersLookback = 60 * 24 * time.Hour
+
+	// IdempotencyWindow is how long PlaceOrderIdempotent remembers a key and
+	// answers repeats of it with the first result instead of resending
+	IdempotencyWindow = 10 * time.Minute
+)
+
+// Market Data Constants
+const (
+	// DefaultQuotesBatchSize is the maximum number of symbols sent in a single
+	// quotes request before Quotes splits the list into concurrent chunks
+	DefaultQuotesBatchSize = 500
+
+	// PriceHistoryMinuteWindow is the span of each minute-candle request
+	// PriceHistoryRange makes
+	PriceHistoryMinuteWindow = 10 * 24 * time.Hour
+
+	// PriceHistoryDailyWindow is the span of each daily, weekly or monthly
+	// candle request PriceHistoryRange makes
+	PriceHistoryDailyWindow = 20 * 365 * 24 * time.Hour
+
+	// PriceHistoryConcurrency is the maximum number of window requests
+	// PriceHistoryRange has in flight at once
+	PriceHistoryConcurrency = 4
+)
+
+// OAuth Endpoint Constants
+const (
+	// OAuthAuthorizeURL is the Schwab OAuth authorization (consent) endpoint
+	OAuthAuthorizeURL = "https://api.schwabapi.com/v1/oauth/authorize"
+
+	// OAuthTokenURL is the Schwab OAuth token endpoint used for both the
+	// authorization-code and refresh-token grants
+	OAuthTokenURL = "https://api.schwabapi.com/v1/oauth/token"
 )
 
 // Token Management Constants
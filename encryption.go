@@ -1,6 +1,7 @@
 package schwabdev
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/fernet/fernet-go"
@@ -27,7 +28,9 @@ func Encrypt(plaintext string, key *fernet.Key) (string, error) {
 
 // Decrypt decrypts ciphertext using the provided Fernet key.
 // If ciphertext doesn't have "enc:" prefix, returns it unchanged.
-// If ciphertext has prefix but key is nil, returns error.
+// If ciphertext has prefix but key is nil, returns ErrDecryptionFailed.
+// If the key does not match the one used to encrypt, returns an error matching
+// both ErrDecryptionFailed and ErrWrongEncryptionKey.
 // Otherwise, removes prefix and decrypts.
 func Decrypt(ciphertext string, key *fernet.Key) (string, error) {
 	// Not encrypted - return as-is
@@ -44,7 +47,7 @@ func Decrypt(ciphertext string, key *fernet.Key) (string, error) {
 	token := ciphertext[len(EncryptionPrefix):]
 	message := fernet.VerifyAndDecrypt([]byte(token), 0, []*fernet.Key{key})
 	if message == nil {
-		return "", ErrDecryptionFailed
+		return "", fmt.Errorf("%w: %w", ErrDecryptionFailed, ErrWrongEncryptionKey)
 	}
 
 	return string(message), nil
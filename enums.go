@@ -1,5 +1,7 @@
 package schwabdev
 
+import "slices"
+
 // TimeFormat represents the different time format options for API responses.
 // These formats match the Python implementation for cross-language compatibility.
 type TimeFormat string
@@ -14,3 +16,314 @@ const (
 func (tf TimeFormat) String() string {
 	return string(tf)
 }
+
+// OrderInstruction is the action taken by an order leg.
+type OrderInstruction string
+
+const (
+	InstructionBuy         OrderInstruction = "BUY"
+	InstructionSell        OrderInstruction = "SELL"
+	InstructionSellShort   OrderInstruction = "SELL_SHORT"
+	InstructionBuyToCover  OrderInstruction = "BUY_TO_COVER"
+	InstructionBuyToOpen   OrderInstruction = "BUY_TO_OPEN"
+	InstructionBuyToClose  OrderInstruction = "BUY_TO_CLOSE"
+	InstructionSellToOpen  OrderInstruction = "SELL_TO_OPEN"
+	InstructionSellToClose OrderInstruction = "SELL_TO_CLOSE"
+)
+
+var orderInstructions = []OrderInstruction{
+	InstructionBuy, InstructionSell, InstructionSellShort, InstructionBuyToCover,
+	InstructionBuyToOpen, InstructionBuyToClose, InstructionSellToOpen, InstructionSellToClose,
+}
+
+// OrderType is the pricing type of an order.
+type OrderType string
+
+const (
+	OrderTypeMarket    OrderType = "MARKET"
+	OrderTypeLimit     OrderType = "LIMIT"
+	OrderTypeStop      OrderType = "STOP"
+	OrderTypeStopLimit OrderType = "STOP_LIMIT"
+)
+
+// OrderDuration is how long an order stays working.
+type OrderDuration string
+
+const (
+	DurationDay               OrderDuration = "DAY"
+	DurationGoodTillCancel    OrderDuration = "GOOD_TILL_CANCEL"
+	DurationFillOrKill        OrderDuration = "FILL_OR_KILL"
+	DurationImmediateOrCancel OrderDuration = "IMMEDIATE_OR_CANCEL"
+	DurationEndOfWeek         OrderDuration = "END_OF_WEEK"
+	DurationEndOfMonth        OrderDuration = "END_OF_MONTH"
+	DurationNextEndOfMonth    OrderDuration = "NEXT_END_OF_MONTH"
+)
+
+var orderDurations = []OrderDuration{
+	DurationDay, DurationGoodTillCancel, DurationFillOrKill, DurationImmediateOrCancel,
+	DurationEndOfWeek, DurationEndOfMonth, DurationNextEndOfMonth,
+}
+
+// OrderSession is the trading session an order is eligible for.
+type OrderSession string
+
+const (
+	SessionNormal   OrderSession = "NORMAL"
+	SessionAM       OrderSession = "AM"
+	SessionPM       OrderSession = "PM"
+	SessionSeamless OrderSession = "SEAMLESS"
+)
+
+var orderSessions = []OrderSession{SessionNormal, SessionAM, SessionPM, SessionSeamless}
+
+// OrderStatus is the lifecycle state of an order.
+type OrderStatus string
+
+const (
+	OrderStatusAwaitingParentOrder    OrderStatus = "AWAITING_PARENT_ORDER"
+	OrderStatusAwaitingCondition      OrderStatus = "AWAITING_CONDITION"
+	OrderStatusAwaitingStopCondition  OrderStatus = "AWAITING_STOP_CONDITION"
+	OrderStatusAwaitingManualReview   OrderStatus = "AWAITING_MANUAL_REVIEW"
+	OrderStatusAccepted               OrderStatus = "ACCEPTED"
+	OrderStatusAwaitingUROut          OrderStatus = "AWAITING_UR_OUT"
+	OrderStatusPendingActivation      OrderStatus = "PENDING_ACTIVATION"
+	OrderStatusQueued                 OrderStatus = "QUEUED"
+	OrderStatusWorking                OrderStatus = "WORKING"
+	OrderStatusRejected               OrderStatus = "REJECTED"
+	OrderStatusPendingCancel          OrderStatus = "PENDING_CANCEL"
+	OrderStatusCanceled               OrderStatus = "CANCELED"
+	OrderStatusPendingReplace         OrderStatus = "PENDING_REPLACE"
+	OrderStatusReplaced               OrderStatus = "REPLACED"
+	OrderStatusFilled                 OrderStatus = "FILLED"
+	OrderStatusExpired                OrderStatus = "EXPIRED"
+	OrderStatusNew                    OrderStatus = "NEW"
+	OrderStatusAwaitingReleaseTime    OrderStatus = "AWAITING_RELEASE_TIME"
+	OrderStatusPendingAcknowledgement OrderStatus = "PENDING_ACKNOWLEDGEMENT"
+	OrderStatusPendingRecall          OrderStatus = "PENDING_RECALL"
+	OrderStatusUnknown                OrderStatus = "UNKNOWN"
+)
+
+var orderStatuses = []OrderStatus{
+	OrderStatusAwaitingParentOrder, OrderStatusAwaitingCondition, OrderStatusAwaitingStopCondition,
+	OrderStatusAwaitingManualReview, OrderStatusAccepted, OrderStatusAwaitingUROut, OrderStatusPendingActivation,
+	OrderStatusQueued, OrderStatusWorking, OrderStatusRejected, OrderStatusPendingCancel, OrderStatusCanceled,
+	OrderStatusPendingReplace, OrderStatusReplaced, OrderStatusFilled, OrderStatusExpired, OrderStatusNew,
+	OrderStatusAwaitingReleaseTime, OrderStatusPendingAcknowledgement, OrderStatusPendingRecall, OrderStatusUnknown,
+}
+
+// workingOrderStatuses are the order statuses that can still be cancelled.
+var workingOrderStatuses = []OrderStatus{
+	OrderStatusAwaitingParentOrder, OrderStatusAwaitingCondition, OrderStatusAwaitingStopCondition,
+	OrderStatusAwaitingManualReview, OrderStatusAccepted, OrderStatusAwaitingUROut, OrderStatusPendingActivation,
+	OrderStatusQueued, OrderStatusWorking, OrderStatusNew, OrderStatusAwaitingReleaseTime,
+	OrderStatusPendingAcknowledgement, OrderStatusPendingRecall,
+}
+
+// Working reports whether an order in status s is still live and can be
+// cancelled.
+func (s OrderStatus) Working() bool {
+	return slices.Contains(workingOrderStatuses, s)
+}
+
+// OrderStrategyType is how an order relates to other orders.
+type OrderStrategyType string
+
+const (
+	OrderStrategySingle  OrderStrategyType = "SINGLE"
+	OrderStrategyOCO     OrderStrategyType = "OCO"
+	OrderStrategyTrigger OrderStrategyType = "TRIGGER"
+)
+
+// AssetType is the asset type of an order instrument.
+type AssetType string
+
+const (
+	AssetTypeEquity AssetType = "EQUITY"
+	AssetTypeOption AssetType = "OPTION"
+)
+
+// AccountField selects optional data returned with account details.
+type AccountField string
+
+const (
+	AccountFieldPositions AccountField = "positions"
+)
+
+// MoverIndex is the index or market a movers request ranks symbols within.
+type MoverIndex string
+
+const (
+	MoverIndexDJI        MoverIndex = "$DJI"
+	MoverIndexCOMPX      MoverIndex = "$COMPX"
+	MoverIndexSPX        MoverIndex = "$SPX"
+	MoverIndexNYSE       MoverIndex = "NYSE"
+	MoverIndexNASDAQ     MoverIndex = "NASDAQ"
+	MoverIndexOTCBB      MoverIndex = "OTCBB"
+	MoverIndexIndexAll   MoverIndex = "INDEX_ALL"
+	MoverIndexEquityAll  MoverIndex = "EQUITY_ALL"
+	MoverIndexOptionAll  MoverIndex = "OPTION_ALL"
+	MoverIndexOptionPut  MoverIndex = "OPTION_PUT"
+	MoverIndexOptionCall MoverIndex = "OPTION_CALL"
+)
+
+var moverIndexes = []MoverIndex{
+	MoverIndexDJI, MoverIndexCOMPX, MoverIndexSPX, MoverIndexNYSE, MoverIndexNASDAQ, MoverIndexOTCBB,
+	MoverIndexIndexAll, MoverIndexEquityAll, MoverIndexOptionAll, MoverIndexOptionPut, MoverIndexOptionCall,
+}
+
+// MoverSort is the ranking applied to movers. The percent-change sorts carry
+// the direction that older APIs exposed as separate direction/change values.
+type MoverSort string
+
+const (
+	MoverSortVolume            MoverSort = "VOLUME"
+	MoverSortTrades            MoverSort = "TRADES"
+	MoverSortPercentChangeUp   MoverSort = "PERCENT_CHANGE_UP"
+	MoverSortPercentChangeDown MoverSort = "PERCENT_CHANGE_DOWN"
+)
+
+var moverSorts = []MoverSort{
+	MoverSortVolume, MoverSortTrades, MoverSortPercentChangeUp, MoverSortPercentChangeDown,
+}
+
+// MoverFrequency is the minimum percent change, in whole percent, a symbol
+// must have moved to be included in movers.
+type MoverFrequency int
+
+const (
+	MoverFrequency0  MoverFrequency = 0
+	MoverFrequency1  MoverFrequency = 1
+	MoverFrequency5  MoverFrequency = 5
+	MoverFrequency10 MoverFrequency = 10
+	MoverFrequency30 MoverFrequency = 30
+	MoverFrequency60 MoverFrequency = 60
+)
+
+var moverFrequencies = []MoverFrequency{
+	MoverFrequency0, MoverFrequency1, MoverFrequency5, MoverFrequency10, MoverFrequency30, MoverFrequency60,
+}
+
+// TransactionType selects the kind of transactions the transactions endpoint
+// returns. Schwab accepts one type per request.
+type TransactionType string
+
+const (
+	TransactionTypeTrade              TransactionType = "TRADE"
+	TransactionTypeReceiveAndDeliver  TransactionType = "RECEIVE_AND_DELIVER"
+	TransactionTypeDividendOrInterest TransactionType = "DIVIDEND_OR_INTEREST"
+	TransactionTypeACHReceipt         TransactionType = "ACH_RECEIPT"
+	TransactionTypeACHDisbursement    TransactionType = "ACH_DISBURSEMENT"
+	TransactionTypeCashReceipt        TransactionType = "CASH_RECEIPT"
+	TransactionTypeCashDisbursement   TransactionType = "CASH_DISBURSEMENT"
+	TransactionTypeElectronicFund     TransactionType = "ELECTRONIC_FUND"
+	TransactionTypeWireOut            TransactionType = "WIRE_OUT"
+	TransactionTypeWireIn             TransactionType = "WIRE_IN"
+	TransactionTypeJournal            TransactionType = "JOURNAL"
+	TransactionTypeMemorandum         TransactionType = "MEMORANDUM"
+	TransactionTypeMarginCall         TransactionType = "MARGIN_CALL"
+	TransactionTypeMoneyMarket        TransactionType = "MONEY_MARKET"
+	TransactionTypeSMAAdjustment      TransactionType = "SMA_ADJUSTMENT"
+)
+
+var transactionTypes = []TransactionType{
+	TransactionTypeTrade, TransactionTypeReceiveAndDeliver, TransactionTypeDividendOrInterest,
+	TransactionTypeACHReceipt, TransactionTypeACHDisbursement, TransactionTypeCashReceipt,
+	TransactionTypeCashDisbursement, TransactionTypeElectronicFund, TransactionTypeWireOut,
+	TransactionTypeWireIn, TransactionTypeJournal, TransactionTypeMemorandum,
+	TransactionTypeMarginCall, TransactionTypeMoneyMarket, TransactionTypeSMAAdjustment,
+}
+
+// PeriodType is the unit of the period a price history request covers.
+type PeriodType string
+
+const (
+	PeriodTypeDay   PeriodType = "day"
+	PeriodTypeMonth PeriodType = "month"
+	PeriodTypeYear  PeriodType = "year"
+	PeriodTypeYTD   PeriodType = "ytd"
+)
+
+// FrequencyType is the unit of the candle size in a price history request.
+type FrequencyType string
+
+const (
+	FrequencyTypeMinute  FrequencyType = "minute"
+	FrequencyTypeDaily   FrequencyType = "daily"
+	FrequencyTypeWeekly  FrequencyType = "weekly"
+	FrequencyTypeMonthly FrequencyType = "monthly"
+)
+
+// priceHistoryPeriods lists the periods and frequency types Schwab accepts
+// for each period type.
+var priceHistoryPeriods = map[PeriodType]struct {
+	periods        []int
+	frequencyTypes []FrequencyType
+}{
+	PeriodTypeDay:   {[]int{1, 2, 3, 4, 5, 10}, []FrequencyType{FrequencyTypeMinute}},
+	PeriodTypeMonth: {[]int{1, 2, 3, 6}, []FrequencyType{FrequencyTypeDaily, FrequencyTypeWeekly}},
+	PeriodTypeYear:  {[]int{1, 2, 3, 5, 10, 15, 20}, []FrequencyType{FrequencyTypeDaily, FrequencyTypeWeekly, FrequencyTypeMonthly}},
+	PeriodTypeYTD:   {[]int{1}, []FrequencyType{FrequencyTypeDaily, FrequencyTypeWeekly}},
+}
+
+// priceHistoryFrequencies lists the frequencies Schwab accepts for each
+// frequency type.
+var priceHistoryFrequencies = map[FrequencyType][]int{
+	FrequencyTypeMinute:  {1, 5, 10, 15, 30},
+	FrequencyTypeDaily:   {1},
+	FrequencyTypeWeekly:  {1},
+	FrequencyTypeMonthly: {1},
+}
+
+// QuoteField selects a projection of the quotes endpoints' response.
+type QuoteField string
+
+const (
+	QuoteFieldAll         QuoteField = "all"
+	QuoteFieldQuote       QuoteField = "quote"
+	QuoteFieldFundamental QuoteField = "fundamental"
+	QuoteFieldExtended    QuoteField = "extended"
+	QuoteFieldReference   QuoteField = "reference"
+	QuoteFieldRegular     QuoteField = "regular"
+)
+
+var quoteFields = []QuoteField{
+	QuoteFieldAll, QuoteFieldQuote, QuoteFieldFundamental, QuoteFieldExtended, QuoteFieldReference, QuoteFieldRegular,
+}
+
+// AccountActivityType is the message type of an ACCT_ACTIVITY update. Schwab
+// sends more types than are listed here; unknown values are passed through.
+type AccountActivityType string
+
+const (
+	AccountActivitySubscribed     AccountActivityType = "SUBSCRIBED"
+	AccountActivityOrderCreated   AccountActivityType = "OrderCreated"
+	AccountActivityOrderAccepted  AccountActivityType = "OrderAccepted"
+	AccountActivityOrderFilled    AccountActivityType = "OrderFillCompleted"
+	AccountActivityOrderCancelled AccountActivityType = "OrderUROutCompleted"
+)
+
+// InstrumentProjection selects how the instruments endpoint interprets its
+// symbol argument.
+type InstrumentProjection string
+
+const (
+	// ProjectionSymbolSearch looks up one or more exact symbols.
+	ProjectionSymbolSearch InstrumentProjection = "symbol-search"
+	// ProjectionSymbolRegex matches symbols against a regular expression,
+	// e.g. "AAP.*".
+	ProjectionSymbolRegex InstrumentProjection = "symbol-regex"
+	// ProjectionDescSearch finds instruments whose description contains the
+	// query, e.g. "Apple".
+	ProjectionDescSearch InstrumentProjection = "desc-search"
+	// ProjectionDescRegex matches descriptions against a regular expression.
+	ProjectionDescRegex InstrumentProjection = "desc-regex"
+	// ProjectionSearch is a general search across symbols and descriptions.
+	ProjectionSearch InstrumentProjection = "search"
+	// ProjectionFundamental returns fundamental data for exact symbols.
+	ProjectionFundamental InstrumentProjection = "fundamental"
+)
+
+var instrumentProjections = []InstrumentProjection{
+	ProjectionSymbolSearch, ProjectionSymbolRegex, ProjectionDescSearch,
+	ProjectionDescRegex, ProjectionSearch, ProjectionFundamental,
+}
@@ -1,6 +1,10 @@
 package schwabdev
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 // Parameter validation errors
 var (
@@ -40,8 +44,23 @@ var (
 	// ErrDecryptionFailed indicates token cannot be decrypted without encryption key
 	ErrDecryptionFailed = errors.New("Cannot decrypt token, no encryption key provided.")
 
+	// ErrWrongEncryptionKey indicates an encrypted token failed verification
+	// with the configured key
+	ErrWrongEncryptionKey = errors.New("Cannot decrypt token, encryption key does not match")
+
 	// ErrInvalidGrantType indicates an invalid OAuth grant type was specified
 	ErrInvalidGrantType = errors.New("Invalid grant type; options are 'authorization_code' or 'refresh_token'")
+
+	// ErrAuthCodeRequired indicates an empty authorization code was supplied
+	ErrAuthCodeRequired = errors.New("Authorization code cannot be empty")
+
+	// ErrAccessTokenRequired indicates a token without an access token was
+	// supplied
+	ErrAccessTokenRequired = errors.New("Access token cannot be empty")
+
+	// ErrAuthorizationDenied indicates the user declined the consent screen,
+	// so Schwab redirected with error=access_denied instead of a code
+	ErrAuthorizationDenied = errors.New("Authorization was denied")
 )
 
 // Client configuration errors
@@ -53,8 +72,224 @@ var (
 	ErrUnsupportedTimeFormat = errors.New("Unsupported time format")
 )
 
+// Request validation errors
+var (
+	// ErrInvalidParameter indicates a request parameter was rejected before
+	// the request was sent
+	ErrInvalidParameter = errors.New("Invalid request parameter")
+)
+
+// Order validation errors, reported by ValidateOrder inside a
+// *ValidationError
+var (
+	// ErrMissingOrder indicates a nil order, or an OCO order without children
+	ErrMissingOrder = errors.New("Order is missing")
+
+	// ErrMissingOrderType indicates an order without an orderType
+	ErrMissingOrderType = errors.New("Order type is required")
+
+	// ErrInvalidSession indicates an order session Schwab does not accept
+	ErrInvalidSession = errors.New("Unknown session")
+
+	// ErrInvalidDuration indicates an order duration Schwab does not accept
+	ErrInvalidDuration = errors.New("Unknown duration")
+
+	// ErrMissingPrice indicates a LIMIT or STOP_LIMIT order without a price
+	ErrMissingPrice = errors.New("Price is required")
+
+	// ErrMissingStopPrice indicates a STOP or STOP_LIMIT order without a stop
+	// price
+	ErrMissingStopPrice = errors.New("Stop price is required")
+
+	// ErrMissingLegs indicates an order without legs, or a nil leg
+	ErrMissingLegs = errors.New("Order leg is missing")
+
+	// ErrInvalidQuantity indicates a leg quantity that is not positive
+	ErrInvalidQuantity = errors.New("Quantity must be positive")
+
+	// ErrInvalidInstruction indicates a leg instruction Schwab does not accept
+	ErrInvalidInstruction = errors.New("Unknown instruction")
+
+	// ErrMissingInstrument indicates a leg without an instrument symbol
+	ErrMissingInstrument = errors.New("Instrument symbol is required")
+)
+
 // Streaming errors
 var (
 	// ErrStreamerUnavailable indicates streamer information is not available
 	ErrStreamerUnavailable = errors.New("Streamer info unavailable")
+
+	// ErrStreamNotConnected indicates a request was made while the streamer
+	// had no connection
+	ErrStreamNotConnected = errors.New("Streamer not connected")
+
+	// ErrStreamLoginFailed indicates the streamer rejected the LOGIN request
+	ErrStreamLoginFailed = errors.New("Streamer login failed")
+
+	// ErrStreamWriteBufferFull indicates the outbound request queue is full
+	// because the writer cannot keep up with the connection
+	ErrStreamWriteBufferFull = errors.New("Stream write buffer full")
+
+	// ErrReconnectAttemptsExhausted indicates the streamer gave up
+	// reconnecting after the configured maximum number of attempts
+	ErrReconnectAttemptsExhausted = errors.New("Stream reconnect attempts exhausted")
+
+	// ErrNotSubscribed indicates an UNSUBS named a key that has no recorded
+	// subscription
+	ErrNotSubscribed = errors.New("Stream key not subscribed")
 )
+
+// Response errors
+var (
+	// ErrTruncatedResponse indicates a response body ended before the
+	// complete JSON document or declared Content-Length arrived
+	ErrTruncatedResponse = errors.New("Truncated response body")
+
+	// ErrNotFound indicates a lookup succeeded but Schwab returned no
+	// record, i.e. an empty body or a literal null for an unknown ID
+	ErrNotFound = errors.New("Not found")
+)
+
+// API errors
+
+// APIError is returned when the Schwab API responds with a status code of 400
+// or above, or another status rejected by Client.SetSuccessPredicate. Client
+// methods wrap it, so use errors.As to inspect it:
+//
+//	var apiErr *schwabdev.APIError
+//	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+//		// back off and retry
+//	}
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Code is the machine-readable error code from the response body, if any.
+	Code string
+
+	// Message is the human-readable error message from the response body, if any.
+	Message string
+
+	// Errors holds every error decoded from the response body. Most endpoints
+	// return a single error object, some return an array of them; Code and
+	// Message are taken from the first entry either way.
+	Errors []ErrorResponse
+
+	// Body is the raw response body.
+	Body []byte
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Code != "" && e.Message != "":
+		return fmt.Sprintf("schwab API error (%d): %s: %s", e.StatusCode, e.Code, e.Message)
+	case e.Message != "":
+		return fmt.Sprintf("schwab API error (%d): %s", e.StatusCode, e.Message)
+	case e.Code != "":
+		return fmt.Sprintf("schwab API error (%d): %s", e.StatusCode, e.Code)
+	default:
+		return fmt.Sprintf("schwab API error (%d)", e.StatusCode)
+	}
+}
+
+// OAuthError is returned when the Schwab OAuth token endpoint rejects a
+// grant. Code is the OAuth error code from the response body; "invalid_grant"
+// means the refresh token or authorization code is no longer valid and the
+// user must re-authorize, while 5xx statuses are usually transient:
+//
+//	var oauthErr *schwabdev.OAuthError
+//	if errors.As(err, &oauthErr) && oauthErr.Code == "invalid_grant" {
+//		// start the authorization flow again
+//	}
+type OAuthError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Code is the "error" field of the response body, if any.
+	Code string `json:"error"`
+
+	// Description is the "error_description" field of the response body, if any.
+	Description string `json:"error_description"`
+
+	// Body is the raw response body.
+	Body []byte `json:"-"`
+}
+
+func (e *OAuthError) Error() string {
+	switch {
+	case e.Code != "" && e.Description != "":
+		return fmt.Sprintf("OAuth error (%d): %s: %s", e.StatusCode, e.Code, e.Description)
+	case e.Description != "":
+		return fmt.Sprintf("OAuth error (%d): %s", e.StatusCode, e.Description)
+	case e.Code != "":
+		return fmt.Sprintf("OAuth error (%d): %s", e.StatusCode, e.Code)
+	default:
+		return fmt.Sprintf("OAuth request failed (%d): %s", e.StatusCode, e.Body)
+	}
+}
+
+// FieldError is one problem ValidateOrder found with an order. Err wraps one
+// of the order validation sentinels, such as ErrMissingPrice.
+type FieldError struct {
+	// Field is the JSON path of the offending field, e.g. "price" or
+	// "childOrderStrategies[1].orderLegCollection[0].quantity".
+	Field string
+
+	// Err describes the problem.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError is returned by ValidateOrder, and so by PlaceOrder, when an
+// order has problems. It lists all of them, and matches ErrInvalidParameter
+// as well as the sentinel of each problem:
+//
+//	var vErr *schwabdev.ValidationError
+//	if errors.As(err, &vErr) {
+//		for _, p := range vErr.Problems {
+//			fmt.Println(p.Field, p.Err)
+//		}
+//	}
+//	if errors.Is(err, schwabdev.ErrMissingPrice) {
+//		// prompt for a limit price
+//	}
+type ValidationError struct {
+	Problems []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		msgs[i] = p.Error()
+	}
+	return fmt.Sprintf("%v: %s", ErrInvalidParameter, strings.Join(msgs, "; "))
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Problems)+1)
+	errs = append(errs, ErrInvalidParameter)
+	for _, p := range e.Problems {
+		errs = append(errs, p)
+	}
+	return errs
+}
+
+// QuoteError reports a symbol that a quotes request could not price.
+type QuoteError struct {
+	// Symbol is the requested symbol, CUSIP or SSID.
+	Symbol string
+
+	// Reason describes why no quote was returned.
+	Reason string
+}
+
+func (e *QuoteError) Error() string {
+	return fmt.Sprintf("quote for %s unavailable: %s", e.Symbol, e.Reason)
+}
@@ -0,0 +1,66 @@
+package schwabdev_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	schwabdev "github.com/citizenadam/go-schwabapi"
+)
+
+func TestAPIError_ErrorsAs(t *testing.T) {
+	wrapped := fmt.Errorf("failed to get quotes: %w", &schwabdev.APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Message:    "rate limit exceeded",
+	})
+
+	var apiErr *schwabdev.APIError
+	if !errors.As(wrapped, &apiErr) {
+		t.Fatal("errors.As did not find *APIError")
+	}
+	if apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("StatusCode: want 429, got %d", apiErr.StatusCode)
+	}
+}
+
+func TestAPIError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *schwabdev.APIError
+		want string
+	}{
+		{"status only", &schwabdev.APIError{StatusCode: 500}, "schwab API error (500)"},
+		{"message", &schwabdev.APIError{StatusCode: 404, Message: "not found"}, "schwab API error (404): not found"},
+		{"code", &schwabdev.APIError{StatusCode: 401, Code: "invalid_client"}, "schwab API error (401): invalid_client"},
+		{"code and message", &schwabdev.APIError{StatusCode: 401, Code: "invalid_client", Message: "bad token"},
+			"schwab API error (401): invalid_client: bad token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOAuthError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *schwabdev.OAuthError
+		want string
+	}{
+		{"body only", &schwabdev.OAuthError{StatusCode: 502, Body: []byte("bad gateway")}, "OAuth request failed (502): bad gateway"},
+		{"code", &schwabdev.OAuthError{StatusCode: 401, Code: "invalid_client"}, "OAuth error (401): invalid_client"},
+		{"code and description", &schwabdev.OAuthError{StatusCode: 400, Code: "invalid_grant", Description: "expired"},
+			"OAuth error (400): invalid_grant: expired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package schwabdev
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// placedOrders tracks the keys passed to PlaceOrderIdempotent. The zero value
+// is ready to use.
+type placedOrders struct {
+	mu      sync.Mutex
+	entries map[string]*placedOrder
+}
+
+// placedOrder is the outcome of one keyed PlaceOrder call. done is closed
+// once resp and err are set; expires is zero until then.
+type placedOrder struct {
+	done    chan struct{}
+	resp    *PlaceOrderResponse
+	err     error
+	expires time.Time
+}
+
+// claim returns the entry for key and whether the caller created it and so
+// must place the order. Expired entries are dropped first.
+func (p *placedOrders) claim(key string, now time.Time) (*placedOrder, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, e := range p.entries {
+		if !e.expires.IsZero() && now.After(e.expires) {
+			delete(p.entries, k)
+		}
+	}
+	if e, ok := p.entries[key]; ok {
+		return e, false
+	}
+	if p.entries == nil {
+		p.entries = make(map[string]*placedOrder)
+	}
+	e := &placedOrder{done: make(chan struct{})}
+	p.entries[key] = e
+	return e, true
+}
+
+// forget drops the entry for key if it is still e, so the key can be used
+// again.
+func (p *placedOrders) forget(key string, e *placedOrder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.entries[key] == e {
+		delete(p.entries, key)
+	}
+}
+
+// requestSentKey is the context key under which PlaceOrderIdempotent passes
+// an *atomic.Bool that doRequest sets before handing a request to the HTTP
+// client.
+type requestSentKey struct{}
+
+// PlaceOrderIdempotent is PlaceOrder with a caller-chosen key guarding
+// against double submission, e.g. when a caller retries after a timeout.
+// The first call with a key places the order. Any call with the same key in
+// the following IdempotencyWindow, or while the first is still in flight,
+// sends nothing and returns the first call's result. That includes its
+// error, because a failed call may still have reached Schwab; check the
+// account's orders before retrying under a new key. The exception is a
+// failure that never sent the order, such as a ValidateOrder error or a
+// context cancelled beforehand: the key is released at once and can be
+// retried. Keys are remembered per Client, in memory only.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - accountHash: Account hash from LinkedAccounts()
+//   - order: Order request details, checked with ValidateOrder before sending
+//   - key: Non-empty idempotency key identifying this order
+//
+// Returns PlaceOrderResponse containing the order ID and any error that occurred.
+func (c *Client) PlaceOrderIdempotent(ctx context.Context, accountHash string, order *OrderRequest, key string) (*PlaceOrderResponse, error) {
+	if key == "" {
+		return nil, fmt.Errorf("failed to place order: %w: empty idempotency key", ErrInvalidParameter)
+	}
+
+	e, first := c.placed.claim(key, time.Now())
+	if !first {
+		select {
+		case <-e.done:
+			return e.resp, e.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var sent atomic.Bool
+	e.resp, e.err = c.PlaceOrder(context.WithValue(ctx, requestSentKey{}, &sent), accountHash, order)
+	if e.err != nil && (errors.Is(e.err, ErrInvalidParameter) || !sent.Load()) {
+		c.placed.forget(key, e)
+	} else {
+		c.placed.mu.Lock()
+		e.expires = time.Now().Add(IdempotencyWindow)
+		c.placed.mu.Unlock()
+	}
+	close(e.done)
+	return e.resp, e.err
+}
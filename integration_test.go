@@ -228,6 +228,36 @@ func TestIntegration_AccountOrders(t *testing.T) {
 	}
 }
 
+func TestIntegration_OrderDetails(t *testing.T) {
+	client := integrationClient(t)
+	ctx := context.Background()
+	hash := firstAccountHash(t, client)
+
+	// Get an order ID from the list first.
+	from := time.Now().AddDate(0, -1, 0)
+	to := time.Now()
+	list, err := client.AccountOrders(ctx, hash, from, to, nil, nil)
+	if err != nil {
+		t.Fatalf("AccountOrders (setup): %v", err)
+	}
+	if list == nil || len(*list) == 0 {
+		t.Skip("no orders in last 30 days — skipping OrderDetails")
+	}
+
+	orderID := (*list)[0].OrderID
+	resp, err := client.OrderDetails(ctx, hash, orderID)
+	if err != nil {
+		t.Fatalf("OrderDetails error: %v", err)
+	}
+	if resp == nil {
+		t.Fatal("response is nil")
+	}
+	if resp.OrderID != orderID {
+		t.Errorf("OrderID: want %d, got %d", orderID, resp.OrderID)
+	}
+	assertValidJSON(t, "OrderDetailsResponse", resp)
+}
+
 func TestIntegration_AccountOrdersAll(t *testing.T) {
 	client := integrationClient(t)
 	ctx := context.Background()
@@ -354,7 +384,7 @@ func TestIntegration_Quote(t *testing.T) {
 	client := integrationClient(t)
 	ctx := context.Background()
 
-	resp, err := client.Quote(ctx, "AAPL", nil)
+	resp, err := client.Quote(ctx, "AAPL", nil, nil)
 	if err != nil {
 		t.Fatalf("Quote error: %v", err)
 	}
@@ -389,7 +419,7 @@ func TestIntegration_Quote_AllFields(t *testing.T) {
 	ctx := context.Background()
 
 	fields := "all"
-	resp, err := client.Quote(ctx, "AAPL", &fields)
+	resp, err := client.Quote(ctx, "AAPL", &fields, nil)
 	if err != nil {
 		t.Fatalf("Quote(all) error: %v", err)
 	}
@@ -449,7 +479,7 @@ func TestIntegration_Quotes_52WeekHighLow(t *testing.T) {
 	ctx := context.Background()
 
 	fields := "quote"
-	resp, err := client.Quote(ctx, "SPY", &fields)
+	resp, err := client.Quote(ctx, "SPY", &fields, nil)
 	if err != nil {
 		t.Fatalf("Quote(SPY) error: %v", err)
 	}
@@ -0,0 +1,56 @@
+package schwabdev
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryTokenStorage keeps the token record in process memory only.
+//
+// Tokens are lost when the process exits, so it suits tests and short-lived
+// containers without a writable disk where the record is seeded up front
+// (e.g. from a secret manager) and refreshed in place.
+//
+// Usage:
+//
+//	storage := schwabdev.NewMemoryTokenStorage(&rec)
+//	tm, err := schwabdev.NewTokenManager(appKey, appSecret, callbackURL, storage, "", logger, nil)
+type MemoryTokenStorage struct {
+	mu  sync.Mutex
+	rec *TokenRecord
+}
+
+// NewMemoryTokenStorage creates a MemoryTokenStorage seeded with rec.
+// rec may be nil to start empty (first run).
+func NewMemoryTokenStorage(rec *TokenRecord) *MemoryTokenStorage {
+	m := &MemoryTokenStorage{}
+	if rec != nil {
+		cp := *rec
+		m.rec = &cp
+	}
+	return m
+}
+
+// Load returns a copy of the stored record, or (nil, nil) when empty.
+func (m *MemoryTokenStorage) Load(_ context.Context) (*TokenRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.rec == nil {
+		return nil, nil
+	}
+	cp := *m.rec
+	return &cp, nil
+}
+
+// Save replaces the stored record.
+func (m *MemoryTokenStorage) Save(_ context.Context, rec TokenRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rec = &rec
+	return nil
+}
+
+// Close is a no-op; the record stays readable after Close.
+func (m *MemoryTokenStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,221 @@
+package schwabdev
+
+import (
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ClientOption configures optional Client behaviour. Pass options as the
+// trailing arguments to NewClient; they are applied after the defaults.
+type ClientOption func(*Client)
+
+// WithRetry enables automatic retries for requests rejected with 429 Too Many
+// Requests or 503 Service Unavailable. The client honours the Retry-After
+// header (seconds or HTTP-date) and never sleeps past the caller's context
+// deadline. maxAttempts is the total number of attempts including the first;
+// values below 2 leave retrying disabled.
+func WithRetry(maxAttempts int) ClientOption {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+	}
+}
+
+// WithNetworkRetry enables automatic retries for requests that fail with a
+// transient network error (timeout, connection reset or refused, connection
+// closed before a response), waiting NetworkRetryBaseDelay doubled per attempt
+// up to NetworkRetryMaxDelay and never past the caller's context. It is
+// separate from WithRetry, which handles throttling responses.
+//
+// maxAttempts is the total number of attempts including the first; values
+// below 2 leave retrying disabled. Only GET requests are retried unless
+// methods lists the HTTP methods to retry explicitly — only include POST or
+// PUT if repeating the request cannot cause harm (e.g. a duplicate order).
+func WithNetworkRetry(maxAttempts int, methods ...string) ClientOption {
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet}
+	}
+	upper := make([]string, len(methods))
+	for i, m := range methods {
+		upper[i] = strings.ToUpper(m)
+	}
+	return func(c *Client) {
+		c.networkAttempts = maxAttempts
+		c.networkRetryMethods = upper
+	}
+}
+
+// WithBaseURL overrides the API base URL (default https://api.schwabapi.com).
+// This is mainly useful for pointing the client at a proxy or a test server.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = baseURL
+	}
+}
+
+// WithTokenURL points the Client's TokenManager at tokenURL for OAuth grants
+// (see TokenManager.SetTokenURL). Unlike calling SetTokenURL afterwards, it
+// also applies to the token check NewClient makes before returning.
+func WithTokenURL(tokenURL string) ClientOption {
+	return func(c *Client) {
+		c.tokenManager.SetTokenURL(tokenURL)
+	}
+}
+
+// WithHTTPClient makes the Client send API requests through hc, for example
+// one with an instrumented or proxying transport. The per-request timeout is
+// still applied through the request context, so hc.Timeout can stay zero.
+// Close and CloseIdleConnections close hc's idle connections. A nil hc keeps
+// the default client. OAuth token requests are not affected.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// WithQuotesBatchSize sets the maximum number of symbols Quotes sends in a
+// single request (default DefaultQuotesBatchSize). Larger symbol lists are
+// split into chunks of this size and fetched concurrently.
+func WithQuotesBatchSize(n int) ClientOption {
+	return func(c *Client) {
+		c.quotesBatchSize = n
+	}
+}
+
+// WithSymbolNormalization controls whether symbols passed to Quotes, Quote,
+// OptionChains, OptionExpirationChain and symbol-list Instruments searches
+// are cleaned with NormalizeSymbol before they are sent (default true).
+// Schwab matches symbols exactly, so "aapl" would otherwise return nothing.
+func WithSymbolNormalization(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.rawSymbols = !enabled
+	}
+}
+
+// WithLogger sets the logger used by the Client and its TokenManager
+// (default slog.Default()). A nil logger discards all output. Per-request
+// lines are logged at Debug and failed requests at Warn, so a handler at
+// Info level only shows problems.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		logger = loggerOrDiscard(logger)
+		c.logger = logger
+		c.tokenManager.logger = logger
+	}
+}
+
+// loggerOrDiscard returns logger, or a logger that discards all output if it
+// is nil.
+func loggerOrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return logger
+}
+
+// StreamerOption configures optional Streamer behaviour. Pass options as the
+// trailing arguments to NewStreamer; they are applied after the defaults.
+type StreamerOption func(*Streamer)
+
+// WithStaleTimeout sets how long a connection may go without receiving any
+// frame (data, response, or heartbeat) before it is closed as stale so the
+// reconnect loop can replace it. The default is 30 seconds; zero disables the
+// watchdog.
+func WithStaleTimeout(d time.Duration) StreamerOption {
+	return func(s *Streamer) {
+		s.staleTimeout = d
+	}
+}
+
+// WithPingInterval sets how often the Streamer sends a WebSocket ping to keep
+// NAT and proxy mappings alive, and how long it waits for the pong before
+// treating the connection as dead and closing it so Start reconnects
+// (defaults 20s and 10s). An interval of zero or less disables pings; a
+// timeout of zero or less keeps the default.
+func WithPingInterval(interval, timeout time.Duration) StreamerOption {
+	return func(s *Streamer) {
+		s.pingInterval = interval
+		if timeout > 0 {
+			s.pingTimeout = timeout
+		}
+	}
+}
+
+// WithReconnectBackoff sets the initial and maximum delay between reconnect
+// attempts made by Start (defaults 2s and 120s).
+func WithReconnectBackoff(base, maxBackoff time.Duration) StreamerOption {
+	return func(s *Streamer) {
+		s.reconnect.mu.Lock()
+		defer s.reconnect.mu.Unlock()
+		s.reconnect.baseBackoff = base
+		s.reconnect.backoffTime = base
+		s.reconnect.maxBackoff = maxBackoff
+	}
+}
+
+// WithMaxReconnectAttempts makes Start give up after n consecutive failed
+// reconnect attempts and return an error wrapping
+// ErrReconnectAttemptsExhausted, so a supervisor can alert. The count resets
+// once a connection stays up. n <= 0 retries forever (the default).
+func WithMaxReconnectAttempts(n int) StreamerOption {
+	return func(s *Streamer) {
+		s.reconnect.SetMaxAttempts(n)
+	}
+}
+
+// WithWriteTimeout bounds how long a single frame may take to write before
+// the connection is treated as wedged and closed (default 10s).
+func WithWriteTimeout(d time.Duration) StreamerOption {
+	return func(s *Streamer) {
+		s.writeTimeout = d
+	}
+}
+
+// WithWriteBuffer sets how many outbound requests may be queued for the
+// writer before service methods fail with ErrStreamWriteBufferFull
+// (default 64).
+func WithWriteBuffer(n int) StreamerOption {
+	return func(s *Streamer) {
+		s.writeBuffer = n
+	}
+}
+
+// WithStreamerInfoFunc sets a context-aware source of streamer connection
+// details that takes precedence over the InfoSource given to NewStreamer.
+// fn is called with the dial's context before every LOGIN, including each
+// reconnect, so the login always carries current credentials. Pass
+// (*Client).GetStreamerInfo to fetch them from the user preferences endpoint.
+func WithStreamerInfoFunc(fn func(ctx context.Context) (*StreamerInfo, error)) StreamerOption {
+	return func(s *Streamer) {
+		s.infoFunc = fn
+	}
+}
+
+// WithStreamerURL connects to url instead of the streamerSocketUrl reported
+// by the streamer info, e.g. to point the Streamer at a staging socket.
+func WithStreamerURL(url string) StreamerOption {
+	return func(s *Streamer) {
+		s.streamerURL = url
+	}
+}
+
+// WithDialer sets the function used to open the Streamer's network
+// connections, for example to route them through a proxy.
+func WithDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) StreamerOption {
+	return func(s *Streamer) {
+		s.dialer = dial
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used for wss:// connections.
+func WithTLSConfig(cfg *tls.Config) StreamerOption {
+	return func(s *Streamer) {
+		s.tlsConfig = cfg
+	}
+}
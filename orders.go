@@ -0,0 +1,248 @@
+package schwabdev
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OrderBuilder assembles a single-leg OrderRequest for PlaceOrder,
+// ReplaceOrder or PreviewOrder. Start from one of the constructors, chain the
+// pricing and timing methods, then call Build:
+//
+//	order, err := schwabdev.EquityBuy("AAPL", 10).Limit("190.50").Duration(schwabdev.DurationGoodTillCancel).Build()
+//
+// Orders default to a MARKET order for the NORMAL session with DAY duration.
+type OrderBuilder struct {
+	instruction OrderInstruction
+	assetType   AssetType
+	symbol      string
+	quantity    int
+	orderType   OrderType
+	price       string
+	stopPrice   string
+	duration    OrderDuration
+	session     OrderSession
+}
+
+// NewOrder starts an order for quantity units of symbol.
+func NewOrder(instruction OrderInstruction, assetType AssetType, symbol string, quantity int) *OrderBuilder {
+	return &OrderBuilder{
+		instruction: instruction,
+		assetType:   assetType,
+		symbol:      symbol,
+		quantity:    quantity,
+		orderType:   OrderTypeMarket,
+		duration:    DurationDay,
+		session:     SessionNormal,
+	}
+}
+
+// EquityBuy starts an order buying quantity shares of symbol.
+func EquityBuy(symbol string, quantity int) *OrderBuilder {
+	return NewOrder(InstructionBuy, AssetTypeEquity, symbol, quantity)
+}
+
+// EquitySell starts an order selling quantity shares of symbol.
+func EquitySell(symbol string, quantity int) *OrderBuilder {
+	return NewOrder(InstructionSell, AssetTypeEquity, symbol, quantity)
+}
+
+// OptionBuyToOpen starts an order opening a long position of quantity
+// contracts. symbol is the OCC option symbol, e.g. "AAPL  240809C00095000".
+func OptionBuyToOpen(symbol string, quantity int) *OrderBuilder {
+	return NewOrder(InstructionBuyToOpen, AssetTypeOption, symbol, quantity)
+}
+
+// OptionSellToClose starts an order closing quantity long contracts.
+func OptionSellToClose(symbol string, quantity int) *OrderBuilder {
+	return NewOrder(InstructionSellToClose, AssetTypeOption, symbol, quantity)
+}
+
+// OptionSellToOpen starts an order opening a short position of quantity
+// contracts.
+func OptionSellToOpen(symbol string, quantity int) *OrderBuilder {
+	return NewOrder(InstructionSellToOpen, AssetTypeOption, symbol, quantity)
+}
+
+// OptionBuyToClose starts an order closing quantity short contracts.
+func OptionBuyToClose(symbol string, quantity int) *OrderBuilder {
+	return NewOrder(InstructionBuyToClose, AssetTypeOption, symbol, quantity)
+}
+
+// Market makes this a market order.
+func (b *OrderBuilder) Market() *OrderBuilder {
+	b.orderType, b.price, b.stopPrice = OrderTypeMarket, "", ""
+	return b
+}
+
+// Limit makes this a limit order at price.
+func (b *OrderBuilder) Limit(price string) *OrderBuilder {
+	b.orderType, b.price, b.stopPrice = OrderTypeLimit, price, ""
+	return b
+}
+
+// Stop makes this a stop (market) order triggered at stopPrice.
+func (b *OrderBuilder) Stop(stopPrice string) *OrderBuilder {
+	b.orderType, b.price, b.stopPrice = OrderTypeStop, "", stopPrice
+	return b
+}
+
+// StopLimit makes this a stop-limit order: once stopPrice trades, a limit
+// order at limitPrice is working.
+func (b *OrderBuilder) StopLimit(stopPrice, limitPrice string) *OrderBuilder {
+	b.orderType, b.price, b.stopPrice = OrderTypeStopLimit, limitPrice, stopPrice
+	return b
+}
+
+// Duration sets how long the order stays working (default DAY).
+func (b *OrderBuilder) Duration(d OrderDuration) *OrderBuilder {
+	b.duration = d
+	return b
+}
+
+// Session sets the trading session (default NORMAL).
+func (b *OrderBuilder) Session(s OrderSession) *OrderBuilder {
+	b.session = s
+	return b
+}
+
+// Build validates the order with ValidateOrder and returns the request
+// payload. Unlike ValidateOrder it also requires a session and duration,
+// which the builder only leaves empty when told to. A problem is reported as
+// a *ValidationError, so errors.Is matches ErrInvalidParameter and the
+// sentinel of each problem, such as ErrMissingPrice.
+func (b *OrderBuilder) Build() (*OrderRequest, error) {
+	order := &OrderRequest{
+		OrderType:         string(b.orderType),
+		Session:           string(b.session),
+		Duration:          string(b.duration),
+		OrderStrategyType: string(OrderStrategySingle),
+		Price:             b.price,
+		StopPrice:         b.stopPrice,
+		OrderLegCollection: []*OrderLegRequest{{
+			Instruction: string(b.instruction),
+			Quantity:    b.quantity,
+			Instrument: &InstrumentRequest{
+				Symbol:    b.symbol,
+				AssetType: string(b.assetType),
+			},
+		}},
+	}
+	var problems []*FieldError
+	validateOrder(order, "", &problems)
+	if b.session == "" {
+		problems = append(problems, &FieldError{Field: "session", Err: fmt.Errorf("%w: %q", ErrInvalidSession, b.session)})
+	}
+	if b.duration == "" {
+		problems = append(problems, &FieldError{Field: "duration", Err: fmt.Errorf("%w: %q", ErrInvalidDuration, b.duration)})
+	}
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return order, nil
+}
+
+// OneCancelsOther combines orders into an OCO order: when one of them fills,
+// Schwab cancels the rest.
+func OneCancelsOther(orders ...*OrderRequest) *OrderRequest {
+	return &OrderRequest{
+		OrderStrategyType:    string(OrderStrategyOCO),
+		ChildOrderStrategies: orders,
+	}
+}
+
+// OneTriggersOther returns a copy of primary that, once filled, places
+// children. Combine with OneCancelsOther for a bracket order:
+//
+//	entry, _ := schwabdev.EquityBuy("AAPL", 10).Limit("190.00").Build()
+//	takeProfit, _ := schwabdev.EquitySell("AAPL", 10).Limit("200.00").Build()
+//	stopLoss, _ := schwabdev.EquitySell("AAPL", 10).Stop("185.00").Build()
+//	order := schwabdev.OneTriggersOther(entry, schwabdev.OneCancelsOther(takeProfit, stopLoss))
+func OneTriggersOther(primary *OrderRequest, children ...*OrderRequest) *OrderRequest {
+	order := *primary
+	order.OrderStrategyType = string(OrderStrategyTrigger)
+	order.ChildOrderStrategies = children
+	return &order
+}
+
+// ValidateOrder checks order for mistakes Schwab would reject or, worse,
+// accept: missing legs, non-positive quantities, unknown instructions,
+// sessions or durations, and LIMIT/STOP orders without their prices. An empty
+// session or duration is left for Schwab to default. Child
+// orders are validated too; an OCO wrapper only needs valid children. Every
+// problem found is reported in a *ValidationError, which wraps
+// ErrInvalidParameter. PlaceOrder calls it before sending.
+func ValidateOrder(order *OrderRequest) error {
+	var problems []*FieldError
+	validateOrder(order, "", &problems)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: problems}
+}
+
+// validateOrder appends the problems with order to problems, prefixing
+// field paths with prefix.
+func validateOrder(order *OrderRequest, prefix string, problems *[]*FieldError) {
+	add := func(field string, err error) {
+		*problems = append(*problems, &FieldError{Field: prefix + field, Err: err})
+	}
+	if order == nil {
+		field := cmp.Or(strings.TrimSuffix(prefix, "."), "order")
+		*problems = append(*problems, &FieldError{Field: field, Err: ErrMissingOrder})
+		return
+	}
+	if order.OrderStrategyType == string(OrderStrategyOCO) {
+		if len(order.ChildOrderStrategies) == 0 {
+			add("childOrderStrategies", ErrMissingOrder)
+		}
+		validateChildOrders(order, prefix, problems)
+		return
+	}
+
+	if order.OrderType == "" {
+		add("orderType", ErrMissingOrderType)
+	}
+	// Schwab applies its own defaults to an empty session or duration.
+	if order.Session != "" && !slices.Contains(orderSessions, OrderSession(order.Session)) {
+		add("session", fmt.Errorf("%w: %q", ErrInvalidSession, order.Session))
+	}
+	if order.Duration != "" && !slices.Contains(orderDurations, OrderDuration(order.Duration)) {
+		add("duration", fmt.Errorf("%w: %q", ErrInvalidDuration, order.Duration))
+	}
+	if (order.OrderType == string(OrderTypeLimit) || order.OrderType == string(OrderTypeStopLimit)) && order.Price == "" {
+		add("price", fmt.Errorf("%w for %s orders", ErrMissingPrice, order.OrderType))
+	}
+	if (order.OrderType == string(OrderTypeStop) || order.OrderType == string(OrderTypeStopLimit)) && order.StopPrice == "" {
+		add("stopPrice", fmt.Errorf("%w for %s orders", ErrMissingStopPrice, order.OrderType))
+	}
+	if len(order.OrderLegCollection) == 0 {
+		add("orderLegCollection", ErrMissingLegs)
+	}
+
+	for i, leg := range order.OrderLegCollection {
+		field := fmt.Sprintf("orderLegCollection[%d]", i)
+		if leg == nil {
+			add(field, ErrMissingLegs)
+			continue
+		}
+		if leg.Quantity <= 0 {
+			add(field+".quantity", fmt.Errorf("%w, got %d", ErrInvalidQuantity, leg.Quantity))
+		}
+		if !slices.Contains(orderInstructions, OrderInstruction(leg.Instruction)) {
+			add(field+".instruction", fmt.Errorf("%w: %q", ErrInvalidInstruction, leg.Instruction))
+		}
+		if leg.Instrument == nil || leg.Instrument.Symbol == "" {
+			add(field+".instrument", ErrMissingInstrument)
+		}
+	}
+	validateChildOrders(order, prefix, problems)
+}
+
+func validateChildOrders(order *OrderRequest, prefix string, problems *[]*FieldError) {
+	for i, child := range order.ChildOrderStrategies {
+		validateOrder(child, fmt.Sprintf("%schildOrderStrategies[%d].", prefix, i), problems)
+	}
+}
@@ -0,0 +1,283 @@
+package schwabdev_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	schwabdev "github.com/citizenadam/go-schwabapi"
+)
+
+// ── OrderBuilder ──────────────────────────────────────────────────────────────
+
+func TestOrderBuilder(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *schwabdev.OrderBuilder
+		want    string
+	}{
+		{
+			name:    "limit buy",
+			builder: schwabdev.EquityBuy("AAPL", 10).Limit("190.50").Duration(schwabdev.DurationGoodTillCancel),
+			want: `{"orderType":"LIMIT","session":"NORMAL","duration":"GOOD_TILL_CANCEL","orderStrategyType":"SINGLE",` +
+				`"price":"190.50","orderLegCollection":[{"instruction":"BUY","quantity":10,` +
+				`"instrument":{"symbol":"AAPL","assetType":"EQUITY"}}]}`,
+		},
+		{
+			name:    "market sell",
+			builder: schwabdev.EquitySell("MSFT", 5),
+			want: `{"orderType":"MARKET","session":"NORMAL","duration":"DAY","orderStrategyType":"SINGLE",` +
+				`"orderLegCollection":[{"instruction":"SELL","quantity":5,` +
+				`"instrument":{"symbol":"MSFT","assetType":"EQUITY"}}]}`,
+		},
+		{
+			name:    "stop limit",
+			builder: schwabdev.EquitySell("TSLA", 3).StopLimit("170.00", "169.50").Session(schwabdev.SessionSeamless),
+			want: `{"orderType":"STOP_LIMIT","session":"SEAMLESS","duration":"DAY","orderStrategyType":"SINGLE",` +
+				`"price":"169.50","stopPrice":"170.00","orderLegCollection":[{"instruction":"SELL","quantity":3,` +
+				`"instrument":{"symbol":"TSLA","assetType":"EQUITY"}}]}`,
+		},
+		{
+			name:    "option buy to open",
+			builder: schwabdev.OptionBuyToOpen("AAPL  240809C00095000", 1).Limit("2.15"),
+			want: `{"orderType":"LIMIT","session":"NORMAL","duration":"DAY","orderStrategyType":"SINGLE",` +
+				`"price":"2.15","orderLegCollection":[{"instruction":"BUY_TO_OPEN","quantity":1,` +
+				`"instrument":{"symbol":"AAPL  240809C00095000","assetType":"OPTION"}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			got, err := json.Marshal(order)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("JSON mismatch:\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrderBuilder_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *schwabdev.OrderBuilder
+		want    error
+	}{
+		{"empty symbol", schwabdev.EquityBuy("", 1), schwabdev.ErrMissingInstrument},
+		{"zero quantity", schwabdev.EquityBuy("AAPL", 0), schwabdev.ErrInvalidQuantity},
+		{"limit without price", schwabdev.EquityBuy("AAPL", 1).Limit(""), schwabdev.ErrMissingPrice},
+		{"stop without stop price", schwabdev.EquitySell("AAPL", 1).Stop(""), schwabdev.ErrMissingStopPrice},
+		{"empty duration", schwabdev.EquityBuy("AAPL", 1).Duration(""), schwabdev.ErrInvalidDuration},
+		{"empty session", schwabdev.EquityBuy("AAPL", 1).Session(""), schwabdev.ErrInvalidSession},
+		{"empty instruction", schwabdev.NewOrder("", schwabdev.AssetTypeEquity, "AAPL", 1), schwabdev.ErrInvalidInstruction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.builder.Build()
+			var vErr *schwabdev.ValidationError
+			if !errors.As(err, &vErr) || !errors.Is(err, schwabdev.ErrInvalidParameter) || !errors.Is(err, tt.want) {
+				t.Errorf("want *ValidationError matching ErrInvalidParameter and %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+// ── Composite orders ──────────────────────────────────────────────────────────
+
+// bracketOrder builds a limit entry that triggers an OCO take-profit/stop-loss.
+func bracketOrder(t *testing.T) *schwabdev.OrderRequest {
+	t.Helper()
+	entry, err := schwabdev.EquityBuy("AAPL", 10).Limit("190.00").Build()
+	if err != nil {
+		t.Fatalf("entry: %v", err)
+	}
+	takeProfit, err := schwabdev.EquitySell("AAPL", 10).Limit("200.00").Build()
+	if err != nil {
+		t.Fatalf("take profit: %v", err)
+	}
+	stopLoss, err := schwabdev.EquitySell("AAPL", 10).Stop("185.00").Build()
+	if err != nil {
+		t.Fatalf("stop loss: %v", err)
+	}
+	return schwabdev.OneTriggersOther(entry, schwabdev.OneCancelsOther(takeProfit, stopLoss))
+}
+
+func TestOneCancelsOther_Marshal(t *testing.T) {
+	b, err := json.Marshal(bracketOrder(t))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got struct {
+		OrderStrategyType    string `json:"orderStrategyType"`
+		OrderType            string `json:"orderType"`
+		ChildOrderStrategies []struct {
+			OrderStrategyType    string            `json:"orderStrategyType"`
+			OrderLegCollection   []json.RawMessage `json:"orderLegCollection"`
+			ChildOrderStrategies []struct {
+				OrderStrategyType string `json:"orderStrategyType"`
+				OrderType         string `json:"orderType"`
+				Price             string `json:"price"`
+				StopPrice         string `json:"stopPrice"`
+			} `json:"childOrderStrategies"`
+		} `json:"childOrderStrategies"`
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if got.OrderStrategyType != "TRIGGER" || got.OrderType != "LIMIT" {
+		t.Errorf("parent: want TRIGGER/LIMIT, got %s/%s", got.OrderStrategyType, got.OrderType)
+	}
+	if len(got.ChildOrderStrategies) != 1 {
+		t.Fatalf("want 1 OCO child, got %d", len(got.ChildOrderStrategies))
+	}
+	oco := got.ChildOrderStrategies[0]
+	if oco.OrderStrategyType != "OCO" || len(oco.OrderLegCollection) != 0 {
+		t.Errorf("OCO wrapper: got %+v", oco)
+	}
+	if len(oco.ChildOrderStrategies) != 2 {
+		t.Fatalf("want 2 OCO legs, got %d", len(oco.ChildOrderStrategies))
+	}
+	tp, sl := oco.ChildOrderStrategies[0], oco.ChildOrderStrategies[1]
+	if tp.OrderType != "LIMIT" || tp.Price != "200.00" || tp.OrderStrategyType != "SINGLE" {
+		t.Errorf("take profit: got %+v", tp)
+	}
+	if sl.OrderType != "STOP" || sl.StopPrice != "185.00" {
+		t.Errorf("stop loss: got %+v", sl)
+	}
+	if bytes.Contains(b, []byte(`"session":""`)) {
+		t.Errorf("OCO wrapper should omit empty fields: %s", b)
+	}
+}
+
+func TestOneTriggersOther_DoesNotMutatePrimary(t *testing.T) {
+	entry, _ := schwabdev.EquityBuy("AAPL", 1).Build()
+	child, _ := schwabdev.EquitySell("AAPL", 1).Limit("1.00").Build()
+	schwabdev.OneTriggersOther(entry, child)
+	if entry.OrderStrategyType != "SINGLE" || entry.ChildOrderStrategies != nil {
+		t.Errorf("primary modified: %+v", entry)
+	}
+}
+
+// ── Validation ────────────────────────────────────────────────────────────────
+
+func TestValidateOrder(t *testing.T) {
+	// valid returns a fresh limit buy for each case to break.
+	valid := func() *schwabdev.OrderRequest {
+		order, err := schwabdev.EquityBuy("AAPL", 10).Limit("190.00").Build()
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		return order
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(o *schwabdev.OrderRequest)
+		want   error
+	}{
+		{"missing order type", func(o *schwabdev.OrderRequest) { o.OrderType = "" }, schwabdev.ErrMissingOrderType},
+		{"limit without price", func(o *schwabdev.OrderRequest) { o.Price = "" }, schwabdev.ErrMissingPrice},
+		{"stop without stop price", func(o *schwabdev.OrderRequest) { o.OrderType, o.Price = "STOP", "" }, schwabdev.ErrMissingStopPrice},
+		{"stop limit without stop price", func(o *schwabdev.OrderRequest) { o.OrderType = "STOP_LIMIT" }, schwabdev.ErrMissingStopPrice},
+		{"unknown session", func(o *schwabdev.OrderRequest) { o.Session = "OVERNIGHT" }, schwabdev.ErrInvalidSession},
+		{"unknown duration", func(o *schwabdev.OrderRequest) { o.Duration = "FOREVER" }, schwabdev.ErrInvalidDuration},
+		{"no legs", func(o *schwabdev.OrderRequest) { o.OrderLegCollection = nil }, schwabdev.ErrMissingLegs},
+		{"zero quantity", func(o *schwabdev.OrderRequest) { o.OrderLegCollection[0].Quantity = 0 }, schwabdev.ErrInvalidQuantity},
+		{"negative quantity", func(o *schwabdev.OrderRequest) { o.OrderLegCollection[0].Quantity = -5 }, schwabdev.ErrInvalidQuantity},
+		{"unknown instruction", func(o *schwabdev.OrderRequest) { o.OrderLegCollection[0].Instruction = "HOLD" }, schwabdev.ErrInvalidInstruction},
+		{"missing instrument", func(o *schwabdev.OrderRequest) { o.OrderLegCollection[0].Instrument = nil }, schwabdev.ErrMissingInstrument},
+		{"invalid child", func(o *schwabdev.OrderRequest) {
+			o.ChildOrderStrategies = []*schwabdev.OrderRequest{{OrderType: "LIMIT"}}
+		}, schwabdev.ErrMissingPrice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := valid()
+			tt.mutate(order)
+			err := schwabdev.ValidateOrder(order)
+			if !errors.Is(err, schwabdev.ErrInvalidParameter) || !errors.Is(err, tt.want) {
+				t.Errorf("want ErrInvalidParameter and %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if err := schwabdev.ValidateOrder(nil); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("nil order: want ErrInvalidParameter, got %v", err)
+	}
+	if err := schwabdev.ValidateOrder(valid()); err != nil {
+		t.Errorf("valid order: %v", err)
+	}
+	for _, duration := range []string{"", "END_OF_WEEK", "END_OF_MONTH", "NEXT_END_OF_MONTH"} {
+		order := valid()
+		order.Session, order.Duration = "", duration
+		if err := schwabdev.ValidateOrder(order); err != nil {
+			t.Errorf("empty session, duration %q: %v", duration, err)
+		}
+	}
+	if err := schwabdev.ValidateOrder(bracketOrder(t)); err != nil {
+		t.Errorf("bracket order: %v", err)
+	}
+	if err := schwabdev.ValidateOrder(schwabdev.OneCancelsOther()); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("empty OCO: want ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestValidateOrder_ReportsEveryProblem(t *testing.T) {
+	child, _ := schwabdev.EquitySell("AAPL", 10).Stop("185.00").Build()
+	child.StopPrice = ""
+	order := &schwabdev.OrderRequest{
+		OrderType:         "LIMIT",
+		Session:           "NORMAL",
+		Duration:          "DAY",
+		OrderStrategyType: "TRIGGER",
+		OrderLegCollection: []*schwabdev.OrderLegRequest{
+			{Instruction: "HOLD", Quantity: 0, Instrument: &schwabdev.InstrumentRequest{Symbol: "AAPL", AssetType: "EQUITY"}},
+		},
+		ChildOrderStrategies: []*schwabdev.OrderRequest{child, nil},
+	}
+
+	err := schwabdev.ValidateOrder(order)
+	var vErr *schwabdev.ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("want *ValidationError, got %T: %v", err, err)
+	}
+	var fields []string
+	for _, p := range vErr.Problems {
+		fields = append(fields, p.Field)
+	}
+	want := []string{
+		"price",
+		"orderLegCollection[0].quantity",
+		"orderLegCollection[0].instruction",
+		"childOrderStrategies[0].stopPrice",
+		"childOrderStrategies[1]",
+	}
+	if !slices.Equal(fields, want) {
+		t.Errorf("problem fields:\n got %v\nwant %v", fields, want)
+	}
+	for _, sentinel := range []error{
+		schwabdev.ErrInvalidParameter, schwabdev.ErrMissingPrice, schwabdev.ErrInvalidQuantity,
+		schwabdev.ErrInvalidInstruction, schwabdev.ErrMissingStopPrice, schwabdev.ErrMissingOrder,
+	} {
+		if !errors.Is(err, sentinel) {
+			t.Errorf("errors.Is(err, %v) = false", sentinel)
+		}
+	}
+	if errors.Is(err, schwabdev.ErrMissingInstrument) {
+		t.Error("err should not match problems the order does not have")
+	}
+	if msg := err.Error(); !strings.Contains(msg, `orderLegCollection[0].instruction: Unknown instruction: "HOLD"`) {
+		t.Errorf("message = %s", msg)
+	}
+}
@@ -0,0 +1,83 @@
+package schwabdev
+
+import (
+	"sync"
+	"time"
+)
+
+// quoteCache holds recently fetched quotes for Client.EnableQuoteCache. Entries
+// are keyed by symbol together with the fields and indicative parameters, so
+// differently shaped responses are never mixed.
+type quoteCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[quoteCacheKey]cachedQuote
+}
+
+// EnableQuoteCache makes Quotes and Quote serve repeat requests for the same
+// symbol, fields and indicative parameters from memory for ttl after the
+// quote was fetched, so polling dashboards only request stale symbols.
+// Per-symbol errors are never cached. A ttl of zero or less disables the
+// cache and discards its contents.
+func (c *Client) EnableQuoteCache(ttl time.Duration) {
+	if ttl <= 0 {
+		c.quoteCache.Store(nil)
+		return
+	}
+	c.quoteCache.Store(newQuoteCache(ttl))
+}
+
+type quoteCacheKey struct {
+	symbol     string
+	fields     string
+	indicative bool
+}
+
+type cachedQuote struct {
+	quote   Quote
+	expires time.Time
+}
+
+func newQuoteCache(ttl time.Duration) *quoteCache {
+	return &quoteCache{ttl: ttl, entries: make(map[quoteCacheKey]cachedQuote)}
+}
+
+// get returns the cached quote for key if it has not expired.
+func (qc *quoteCache) get(key quoteCacheKey) (Quote, bool) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	e, ok := qc.entries[key]
+	if !ok {
+		return Quote{}, false
+	}
+	if time.Now().After(e.expires) {
+		delete(qc.entries, key)
+		return Quote{}, false
+	}
+	return e.quote, true
+}
+
+// put caches q under key. Error entries are not cached so they are retried.
+func (qc *quoteCache) put(key quoteCacheKey, q Quote) {
+	if q.Error != nil {
+		return
+	}
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+	qc.entries[key] = cachedQuote{quote: q, expires: time.Now().Add(qc.ttl)}
+}
+
+// cacheKey builds the cache key for symbol under the given request
+// parameters; nil parameters key the same as their server defaults would.
+func cacheKey(symbol string, fields *string, indicative *bool) quoteCacheKey {
+	key := quoteCacheKey{symbol: symbol}
+	if fields != nil {
+		key.fields = *fields
+	}
+	if indicative != nil {
+		key.indicative = *indicative
+	}
+	return key
+}
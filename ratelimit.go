@@ -0,0 +1,72 @@
+package schwabdev
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket shared by every request a Client sends. The
+// bucket holds up to burst tokens and refills at rps tokens per second; each
+// request takes one, waiting for it if the bucket is empty.
+type rateLimiter struct {
+	rps   float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// SetRateLimit caps the Client at rps requests per second, allowing bursts of
+// up to burst requests, so concurrent callers stay under Schwab's per-app
+// limit instead of tripping 429s. Every HTTP attempt, retries included, waits
+// for a token and gives up if its context ends first. An rps of zero or less
+// removes the limit, which is the default; burst is at least 1.
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	if rps <= 0 {
+		c.limiter.Store(nil)
+		return
+	}
+	b := float64(max(burst, 1))
+	c.limiter.Store(&rateLimiter{rps: rps, burst: b, tokens: b, last: time.Now()})
+}
+
+// wait blocks until a token is available or ctx ends.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	}
+}
+
+// reserve takes a token, returning how long to wait before it may be used.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rps)
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rps * float64(time.Second))
+}
+
+// cancel returns a token reserved by a caller that stopped waiting.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(l.burst, l.tokens+1)
+}
@@ -0,0 +1,101 @@
+package schwabdev
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// MaskAccountID shortens an account number or account hash for display,
+// keeping only its last four characters, e.g. "****5A2F". Identifiers of four
+// characters or fewer are masked entirely.
+func MaskAccountID(id string) string {
+	if len(id) <= 4 {
+		return "****"
+	}
+	return "****" + id[len(id)-4:]
+}
+
+// SetLogRedaction controls whether account identifiers are masked with
+// MaskAccountID in the Client's log lines. It is on by default; turn it off
+// only when logs stay on the local machine and the full hashes are needed
+// for debugging. Errors returned to callers are never redacted.
+func (c *Client) SetLogRedaction(enabled bool) {
+	c.logUnredacted.Store(!enabled)
+}
+
+// accountIDs returns the account identifiers in an API path: the segment
+// following "accounts", as in /trader/v1/accounts/{hash}/orders.
+func accountIDs(path string) []string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(path, "/")
+	var ids []string
+	for i, seg := range segments[:max(len(segments)-1, 0)] {
+		if seg == "accounts" && segments[i+1] != "" {
+			ids = append(ids, segments[i+1])
+		}
+	}
+	return ids
+}
+
+// redactingHandler masks a fixed set of secrets in the message and every
+// attribute value of the records it passes on.
+type redactingHandler struct {
+	slog.Handler
+	secrets []string
+}
+
+func (h redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, h.redact(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.redactAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.redactAttr(a)
+	}
+	return redactingHandler{h.Handler.WithAttrs(redacted), h.secrets}
+}
+
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{h.Handler.WithGroup(name), h.secrets}
+}
+
+func (h redactingHandler) redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(h.redact(v.String()))
+	case slog.KindAny:
+		var s string
+		if err, ok := v.Any().(error); ok {
+			s = err.Error()
+		} else {
+			s = fmt.Sprint(v.Any())
+		}
+		if r := h.redact(s); r != s {
+			a.Value = slog.StringValue(r)
+		}
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.redactAttr(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	}
+	return a
+}
+
+func (h redactingHandler) redact(s string) string {
+	for _, secret := range h.secrets {
+		s = strings.ReplaceAll(s, secret, MaskAccountID(secret))
+	}
+	return s
+}
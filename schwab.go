@@ -0,0 +1,93 @@
+package schwabdev
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// Config holds everything New needs to build a ready-to-use Schwab session.
+// Only AppKey, AppSecret and CallbackURL are required.
+type Config struct {
+	AppKey      string
+	AppSecret   string
+	CallbackURL string
+
+	// TokenPath is where tokens are persisted (default ~/.schwabdev/tokens.json).
+	TokenPath string
+
+	// EncryptionKey, if set, encrypts tokens at rest (see GenerateKey).
+	EncryptionKey string
+
+	// Timeout bounds each HTTP attempt (default DefaultHTTPRequestTimeout).
+	Timeout time.Duration
+
+	// CallOnAuth completes the OAuth flow when re-authorisation is needed;
+	// nil prompts on stdin.
+	CallOnAuth func(authURL string) (callbackURL string, err error)
+
+	// Logger is shared by the client, token manager and streamer
+	// (default slog.Default()). Use slog.New(slog.DiscardHandler) to
+	// silence it.
+	Logger *slog.Logger
+
+	ClientOptions   []ClientOption
+	StreamerOptions []StreamerOption
+}
+
+// Schwab bundles a Client and a Streamer that share one token manager, so a
+// single constructor call is enough to use both the REST and streaming APIs:
+//
+//	s, err := schwabdev.New(schwabdev.Config{AppKey: key, AppSecret: secret, CallbackURL: cb})
+//	if err != nil { ... }
+//	defer s.Close(ctx)
+//	quotes, err := s.Client().Quotes(ctx, "AAPL,MSFT", nil, nil)
+//	err = s.Stream().Start(ctx, nil)
+type Schwab struct {
+	client   *Client
+	streamer *Streamer
+}
+
+// New builds the token manager, API client and streamer described by cfg.
+// No network calls are made until the client or streamer is used.
+func New(cfg Config) (*Schwab, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	opts := append([]ClientOption{WithLogger(logger)}, cfg.ClientOptions...)
+	client, err := NewClient(cfg.AppKey, cfg.AppSecret, cfg.CallbackURL, cfg.TokenPath,
+		cfg.EncryptionKey, cfg.Timeout, cfg.CallOnAuth, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	streamerOpts := append([]StreamerOption{WithStreamerInfoFunc(client.GetStreamerInfo)}, cfg.StreamerOptions...)
+	streamer := NewStreamer(logger, client.TokenManager(), client.InfoSource(), streamerOpts...)
+	return &Schwab{client: client, streamer: streamer}, nil
+}
+
+// Client returns the API client for accounts, orders and market data.
+func (s *Schwab) Client() *Client {
+	return s.client
+}
+
+// Stream returns the streamer, which authenticates with the client's tokens.
+func (s *Schwab) Stream() *Streamer {
+	return s.streamer
+}
+
+// TokenManager returns the token manager shared by the client and streamer.
+func (s *Schwab) TokenManager() *TokenManager {
+	return s.client.TokenManager()
+}
+
+// Close logs the streamer out and shuts its connection down gracefully (see
+// Streamer.Close), then releases the client's resources. ctx bounds the wait
+// for the logout; the client is released either way.
+func (s *Schwab) Close(ctx context.Context) error {
+	err := s.streamer.Close(ctx)
+	return errors.Join(err, s.client.Close())
+}
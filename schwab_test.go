@@ -0,0 +1,125 @@
+package schwabdev_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	schwabdev "github.com/citizenadam/go-schwabapi"
+)
+
+func TestNew_WiresClientAndStreamer(t *testing.T) {
+	loginCh := make(chan streamRequest, 1)
+	logoutCh := make(chan streamRequest, 1)
+	wsSrv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		req, err := ackLogin(ctx, c, 0)
+		if err != nil {
+			return
+		}
+		loginCh <- req
+		for {
+			var req streamRequest
+			if err := wsjson.Read(ctx, c, &req); err != nil {
+				return
+			}
+			if req.Command == "LOGOUT" {
+				logoutCh <- req
+			}
+		}
+	})
+	wsURL := "ws" + strings.TrimPrefix(wsSrv.URL, "http")
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testAccessToken {
+			t.Errorf("Authorization: %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Path != "/trader/v1/userPreference" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"streamerInfo":[{"streamerSocketUrl":%q,"schwabClientCustomerId":"customer",`+
+			`"schwabClientCorrelId":"correl","schwabClientChannel":"N9","schwabClientFunctionId":"APIAPP"}]}`, wsURL)
+	}))
+	defer api.Close()
+
+	tokenPath := filepath.Join(t.TempDir(), "tokens.json")
+	storage, err := schwabdev.NewFileTokenStorage(tokenPath)
+	if err != nil {
+		t.Fatalf("NewFileTokenStorage: %v", err)
+	}
+	now := time.Now().UTC()
+	if err := storage.Save(context.Background(), schwabdev.TokenRecord{
+		AccessTokenIssued:  now,
+		RefreshTokenIssued: now,
+		AccessToken:        testAccessToken,
+		RefreshToken:       "test-refresh-token",
+		ExpiresIn:          1800,
+	}); err != nil {
+		t.Fatalf("save tokens: %v", err)
+	}
+
+	s, err := schwabdev.New(schwabdev.Config{
+		AppKey:        testAppKey,
+		AppSecret:     testAppSecret,
+		CallbackURL:   testCallbackURL,
+		TokenPath:     tokenPath,
+		ClientOptions: []schwabdev.ClientOption{schwabdev.WithBaseURL(api.URL)},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	closed := false
+	defer func() {
+		if !closed {
+			s.Close(context.Background())
+		}
+	}()
+
+	if s.TokenManager() != s.Client().TokenManager() {
+		t.Error("client and session should share one token manager")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.Stream().Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	select {
+	case req := <-loginCh:
+		if req.Parameters["Authorization"] != testAccessToken {
+			t.Errorf("LOGIN token: %v", req.Parameters["Authorization"])
+		}
+	case <-ctx.Done():
+		t.Fatal("no LOGIN received")
+	}
+
+	// Close logs the streamer out rather than just dropping the connection.
+	closed = true
+	if err := s.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	select {
+	case req := <-logoutCh:
+		if req.Service != "ADMIN" {
+			t.Errorf("LOGOUT service: %s", req.Service)
+		}
+	case <-ctx.Done():
+		t.Fatal("no LOGOUT received")
+	}
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	_, err := schwabdev.New(schwabdev.Config{AppSecret: testAppSecret, CallbackURL: testCallbackURL})
+	if !errors.Is(err, schwabdev.ErrAppKeyRequired) {
+		t.Fatalf("want ErrAppKeyRequired, got %v", err)
+	}
+}
@@ -7,6 +7,6 @@
 //
 // Basic usage:
 //
-//	client := schwabdev.NewClient(appKey, appSecret, callbackURL)
-//	stream := schwabdev.NewStream(client)
+//	s, err := schwabdev.New(schwabdev.Config{AppKey: appKey, AppSecret: appSecret, CallbackURL: callbackURL})
+//	client, stream := s.Client(), s.Stream()
 package schwabdev
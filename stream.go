@@ -1,11 +1,18 @@
 package schwabdev
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
 	"math/rand"
+	"net"
+	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -16,8 +23,13 @@ import (
 )
 
 const (
-	pingInterval = 20 * time.Second
-	pingTimeout  = 10 * time.Second
+	loginTimeout = 10 * time.Second
+
+	defaultPingInterval = 20 * time.Second
+	defaultPingTimeout  = 10 * time.Second
+	defaultStaleTimeout = 30 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+	defaultWriteBuffer  = 64
 )
 
 // TokenProvider is any type that can return a fresh, valid access token on
@@ -37,17 +49,131 @@ type TokenProvider interface {
 //	"schwabClientCorrelId"   string
 type InfoSource func() (map[string]any, error)
 
+// DataHandler receives subscription updates routed by the Streamer. It is
+// called synchronously from the read loop, so it should return quickly.
+type DataHandler func(ctx context.Context, data StreamData)
+
+// SentRequest describes a request sent by the Streamer that the server has
+// not acknowledged yet.
+type SentRequest struct {
+	ID      int64
+	Service string
+	Command string
+	Keys    []string
+	SentAt  time.Time // when the request was queued
+}
+
+// ResponseHandler receives command acknowledgements routed by the Streamer
+// together with the request they answer, or nil if the request ID is not
+// one the Streamer is waiting on.
+type ResponseHandler func(ctx context.Context, resp StreamResponse, req *SentRequest)
+
+// ConnectionState is the lifecycle stage of a Streamer's connection.
+type ConnectionState int
+
+const (
+	// StateDisconnected means there is no connection and none is being made.
+	StateDisconnected ConnectionState = iota
+	// StateConnecting means the WebSocket is being dialled.
+	StateConnecting
+	// StateAuthenticating means the socket is open and LOGIN is in flight.
+	StateAuthenticating
+	// StateConnected means the streamer accepted LOGIN and data can flow.
+	StateConnected
+	// StateReconnecting means Start lost or failed to make a connection and
+	// is waiting to try again.
+	StateReconnecting
+)
+
+func (cs ConnectionState) String() string {
+	switch cs {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateAuthenticating:
+		return "authenticating"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return fmt.Sprintf("ConnectionState(%d)", int(cs))
+	}
+}
+
 // Streamer handles the full WebSocket lifecycle for the Schwab Streamer API.
 type Streamer struct {
 	tokens    TokenProvider
 	infoSrc   InfoSource
+	infoFunc  func(ctx context.Context) (*StreamerInfo, error) // overrides infoSrc when set
 	logger    *slog.Logger
 	reconnect *ReconnectManager
 
+	// staleTimeout is how long a connection may stay silent before the
+	// watchdog closes it. Zero disables the watchdog.
+	staleTimeout time.Duration
+
+	// pingInterval is how often pingLoop pings the server, and pingTimeout
+	// how long it waits for the pong. A zero interval disables pings.
+	pingInterval time.Duration
+	pingTimeout  time.Duration
+
+	// writeTimeout bounds each frame write; writeBuffer is the capacity of
+	// the outbound queue drained by writeLoop.
+	writeTimeout time.Duration
+	writeBuffer  int
+
+	// streamerURL overrides the socket URL from the streamer info; dialer
+	// and tlsConfig customise the connection. Zero values use the defaults.
+	streamerURL string
+	dialer      func(ctx context.Context, network, addr string) (net.Conn, error)
+	tlsConfig   *tls.Config
+
 	mu            sync.RWMutex
 	conn          *websocket.Conn
+	info          map[string]any                 // streamer info conn logged in with
+	out           chan outbound                  // outbound queue for conn
+	served        chan struct{}                  // closed once serve has torn conn down
+	stopServe     context.CancelFunc             // cancels the loops serving conn
+	closed        bool                           // set by Close; stops Start from reconnecting
 	subscriptions map[string]map[string][]string // service → key → fields
+	handlers      map[string][]DataHandler       // service → callbacks; "" = all services
+	onHeartbeat   func(at time.Time)
+	onStateChange func(old, new ConnectionState)
+	onResponse    ResponseHandler
+	onSequenceGap func(service, key string, expected, got int)
+	onRawMessage  func(data []byte)
 	requestID     atomic.Int64
+
+	// sendMu serializes recording a subscription change with queueing its
+	// request, so concurrent callers cannot leave the recorded subscriptions
+	// disagreeing with the order in which the server applied the requests.
+	sendMu sync.Mutex
+
+	// pending maps request IDs sent on the current connection to the
+	// request, until the matching response arrives.
+	pendingMu sync.Mutex
+	pending   map[int64]SentRequest
+
+	// latest accumulates the LEVELONE_EQUITIES fields received per symbol,
+	// so LatestQuote can serve a complete quote from partial updates.
+	latestMu sync.Mutex
+	latest   map[string]*latestEquity
+
+	// lastSeq is the last "seq" value received per service and key on the
+	// current connection, for OnSequenceGap.
+	seqMu   sync.Mutex
+	lastSeq map[seqKey]int
+
+	// nextLogin is completed and replaced each time a login succeeds or is
+	// rejected, waking WaitForLogin.
+	loginMu   sync.Mutex
+	nextLogin *loginOutcome
+
+	lastHeartbeat atomic.Int64 // UnixNano of the last notify heartbeat; 0 = none yet
+	lastFrame     atomic.Int64 // UnixNano of the last frame of any kind
+	state         atomic.Int32 // current ConnectionState
 }
 
 // NewStreamer initialises the streamer.
@@ -55,86 +181,733 @@ type Streamer struct {
 //   - tokens: provides a fresh access token whenever one is needed (always
 //     current, never stale).
 //   - infoSrc: fetches streamer connection info from the Schwab API.
-func NewStreamer(logger *slog.Logger, tokens TokenProvider, infoSrc InfoSource) *Streamer {
-	return &Streamer{
+//
+// A nil logger discards all output.
+func NewStreamer(logger *slog.Logger, tokens TokenProvider, infoSrc InfoSource, opts ...StreamerOption) *Streamer {
+	s := &Streamer{
 		tokens:        tokens,
 		infoSrc:       infoSrc,
-		logger:        logger,
+		logger:        loggerOrDiscard(logger),
 		reconnect:     NewReconnectManager(logger),
+		staleTimeout:  defaultStaleTimeout,
+		pingInterval:  defaultPingInterval,
+		pingTimeout:   defaultPingTimeout,
+		writeTimeout:  defaultWriteTimeout,
+		writeBuffer:   defaultWriteBuffer,
 		subscriptions: make(map[string]map[string][]string),
+		handlers:      make(map[string][]DataHandler),
+		pending:       make(map[int64]SentRequest),
+		latest:        make(map[string]*latestEquity),
+		lastSeq:       make(map[seqKey]int),
+		nextLogin:     &loginOutcome{done: make(chan struct{})},
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
-// Start connects, logs in, replays subscriptions, and then reads messages into
-// dataChan until the context is cancelled or an unrecoverable error occurs.
-// Transient disconnects are handled automatically with exponential backoff.
-func (s *Streamer) Start(ctx context.Context, dataChan chan<- []byte) error {
-	return s.reconnect.ReconnectWithBackoff(ctx, func(innerCtx context.Context) error {
-		info, err := s.infoSrc()
-		if err != nil {
-			return fmt.Errorf("get streamer info: %w", err)
+// OnData registers fn to be called for every data update of service (e.g.
+// "LEVELONE_EQUITIES"). An empty service registers a catch-all that sees
+// updates for every service. Multiple handlers may be registered per service;
+// they run in registration order, service-specific handlers first.
+func (s *Streamer) OnData(service string, fn DataHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	service = strings.ToUpper(service)
+	s.handlers[service] = append(s.handlers[service], fn)
+}
+
+// State returns the current connection state.
+func (s *Streamer) State() ConnectionState {
+	return ConnectionState(s.state.Load())
+}
+
+// OnStateChange registers fn to be called on every connection state
+// transition. It runs synchronously on the goroutine making the transition,
+// so it should return quickly. It replaces any previously registered
+// function.
+func (s *Streamer) OnStateChange(fn func(old, new ConnectionState)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onStateChange = fn
+}
+
+// setState moves the Streamer to state and reports the transition to the
+// OnStateChange callback. Setting the current state again is a no-op.
+func (s *Streamer) setState(state ConnectionState) {
+	old := ConnectionState(s.state.Swap(int32(state)))
+	if old == state {
+		return
+	}
+
+	s.mu.RLock()
+	fn := s.onStateChange
+	s.mu.RUnlock()
+	if fn != nil {
+		fn(old, state)
+	}
+}
+
+// OnHeartbeat registers fn to be called with the receipt time of each notify
+// heartbeat. It replaces any previously registered function.
+func (s *Streamer) OnHeartbeat(fn func(at time.Time)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onHeartbeat = fn
+}
+
+// OnRawMessage registers fn to be called with every frame the read loop
+// receives, before it is routed, e.g. to log the frames of a service that has
+// no typed decoder yet. Frames of the LOGIN handshake are not included. fn is
+// called synchronously from the read loop, so it should return quickly; data
+// is not reused and may be retained. It replaces any previously registered
+// function; nil removes it.
+func (s *Streamer) OnRawMessage(fn func(data []byte)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRawMessage = fn
+}
+
+// OnResponse registers fn to be called for every command acknowledgement
+// (e.g. the response to a SUBS). It replaces any previously registered
+// function.
+func (s *Streamer) OnResponse(fn ResponseHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onResponse = fn
+}
+
+// OnSequenceGap registers fn to be called when a data entry's "seq" value
+// skips ahead of the last one received for the same service and key,
+// meaning frames were dropped and the subscriber should re-snapshot (for
+// example by resubscribing). expected is the sequence number that should
+// have arrived and got the one that did. A sequence that restarts or goes
+// backwards is not a gap, and tracking starts afresh on every connection.
+// It replaces any previously registered function.
+func (s *Streamer) OnSequenceGap(fn func(service, key string, expected, got int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onSequenceGap = fn
+}
+
+// PendingRequest returns the request sent with id if its response has not
+// arrived yet.
+func (s *Streamer) PendingRequest(id int64) (SentRequest, bool) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	req, ok := s.pending[id]
+	return req, ok
+}
+
+// PendingRequests returns the requests awaiting a response, ordered by ID.
+// Requests sent on an earlier connection are dropped when it is replaced.
+func (s *Streamer) PendingRequests() []SentRequest {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	reqs := slices.Collect(maps.Values(s.pending))
+	slices.SortFunc(reqs, func(a, b SentRequest) int { return cmp.Compare(a.ID, b.ID) })
+	return reqs
+}
+
+// takePending removes and returns the pending request answered by a
+// response carrying id.
+func (s *Streamer) takePending(id json.Number) (*SentRequest, bool) {
+	n, err := id.Int64()
+	if err != nil {
+		return nil, false
+	}
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	req, ok := s.pending[n]
+	if !ok {
+		return nil, false
+	}
+	delete(s.pending, n)
+	return &req, true
+}
+
+// forgetPending drops the pending entries of requests that were never sent.
+func (s *Streamer) forgetPending(reqs ...map[string]any) {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+	for _, req := range reqs {
+		if id, ok := req["requestid"].(int64); ok {
+			delete(s.pending, id)
+		}
+	}
+}
+
+// LastHeartbeat returns when the most recent notify heartbeat was received,
+// or the zero time if none has arrived. A heartbeat that is far in the past
+// indicates a stalled connection.
+func (s *Streamer) LastHeartbeat() time.Time {
+	ns := s.lastHeartbeat.Load()
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns)
+}
+
+// RouteMessage decodes a raw streamer frame and invokes the handlers
+// registered with OnData for each data update it contains. Notify heartbeats
+// update LastHeartbeat and invoke the OnHeartbeat callback. Command responses
+// are matched to their pending request by request ID and passed to the
+// OnResponse callback. The read loop
+// calls it for every frame; it is exported so recorded frames can be
+// replayed through the same path.
+func (s *Streamer) RouteMessage(ctx context.Context, raw []byte) error {
+	return s.route(ctx, raw, nil)
+}
+
+// route is RouteMessage with an extra handler, called after the registered
+// ones for every data update; Run passes its handler here.
+func (s *Streamer) route(ctx context.Context, raw []byte, extra DataHandler) error {
+	var msg StreamMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return fmt.Errorf("decode stream message: %w", err)
+	}
+
+	for _, n := range msg.Notify {
+		if n.Heartbeat == "" {
+			continue
+		}
+		now := time.Now()
+		s.lastHeartbeat.Store(now.UnixNano())
+
+		s.mu.RLock()
+		fn := s.onHeartbeat
+		s.mu.RUnlock()
+		if fn != nil {
+			fn(now)
 		}
+	}
+
+	for _, resp := range msg.Response {
+		req, _ := s.takePending(resp.RequestID)
 
-		wsURL, ok := info["streamerSocketUrl"].(string)
-		if !ok || wsURL == "" {
-			return fmt.Errorf("streamerSocketUrl missing or empty")
+		s.mu.RLock()
+		fn := s.onResponse
+		s.mu.RUnlock()
+		if fn != nil {
+			fn(ctx, resp, req)
 		}
+	}
 
-		c, _, err := websocket.Dial(innerCtx, wsURL, nil)
-		if err != nil {
-			return fmt.Errorf("websocket dial: %w", err)
+	for _, data := range msg.Data {
+		if data.Service == "LEVELONE_EQUITIES" {
+			s.cacheEquities(data)
 		}
+		s.checkSequence(data)
 
-		s.mu.Lock()
-		s.conn = c
-		s.mu.Unlock()
+		s.mu.RLock()
+		fns := slices.Concat(s.handlers[data.Service], s.handlers[""])
+		s.mu.RUnlock()
+		if extra != nil {
+			fns = append(fns, extra)
+		}
 
-		defer func() {
-			s.mu.Lock()
-			s.conn = nil
-			s.mu.Unlock()
-		}()
+		for _, fn := range fns {
+			fn(ctx, data)
+		}
+	}
+	return nil
+}
+
+// seqKey identifies a sequenced stream: one key of one service.
+type seqKey struct{ service, key string }
+
+// checkSequence records the "seq" value of each entry in data and reports
+// any forward jump to the OnSequenceGap callback.
+func (s *Streamer) checkSequence(data StreamData) {
+	s.mu.RLock()
+	fn := s.onSequenceGap
+	s.mu.RUnlock()
+
+	type gap struct {
+		key           string
+		expected, got int
+	}
+	var gaps []gap
+	s.seqMu.Lock()
+	for _, content := range data.Content {
+		seq, ok := content["seq"].(float64)
+		if !ok {
+			continue
+		}
+		key, _ := content["key"].(string)
+		k := seqKey{data.Service, key}
+		if last, seen := s.lastSeq[k]; seen && int(seq) > last+1 {
+			gaps = append(gaps, gap{key, last + 1, int(seq)})
+		}
+		s.lastSeq[k] = int(seq)
+	}
+	s.seqMu.Unlock()
 
-		if err := s.login(innerCtx, info); err != nil {
-			c.Close(websocket.StatusInternalError, "login failed")
-			return fmt.Errorf("login: %w", err)
+	for _, g := range gaps {
+		s.logger.Debug("stream sequence gap", "service", data.Service, "key", g.key, "expected", g.expected, "got", g.got)
+		if fn != nil {
+			fn(data.Service, g.key, g.expected, g.got)
 		}
+	}
+}
 
-		if err := s.resubscribe(innerCtx, info); err != nil {
-			// Non-fatal: log and continue — the read loop may still work.
-			s.logger.Error("resubscribe after reconnect failed", "error", err)
+// latestEquity is the merged LEVELONE_EQUITIES content of one symbol and the
+// timestamp of the frame that last updated it.
+type latestEquity struct {
+	fields    map[string]any
+	timestamp int64
+}
+
+// cacheEquities merges each entry of a LEVELONE_EQUITIES update into the
+// fields last seen for its symbol.
+func (s *Streamer) cacheEquities(data StreamData) {
+	s.latestMu.Lock()
+	defer s.latestMu.Unlock()
+	for _, content := range data.Content {
+		symbol, _ := content["key"].(string)
+		if symbol == "" {
+			continue
 		}
+		entry := s.latest[symbol]
+		if entry == nil {
+			entry = &latestEquity{fields: make(map[string]any, len(content))}
+			s.latest[symbol] = entry
+		}
+		maps.Copy(entry.fields, content)
+		entry.timestamp = data.Timestamp
+	}
+}
 
-		s.reconnect.ResetBackoff()
+// LatestQuote returns the most recent LEVELONE_EQUITIES data streamed for
+// symbol as a Quote, combining every partial update received so far. ok is
+// false if nothing has been received for symbol. Values survive disconnects,
+// so check the age of QuoteData.QuoteTime (epoch ms; the time of the last
+// update if Schwab did not send one) and fall back to the REST API when it is
+// stale:
+//
+//	q, ok := streamer.LatestQuote("AAPL")
+//	if !ok || time.Since(time.UnixMilli(q.QuoteData.QuoteTime)) > 5*time.Second {
+//		resp, err := client.Quote(ctx, "AAPL", nil, nil)
+//		if err != nil {
+//			return err
+//		}
+//		q = (*schwabdev.Quote)(resp)
+//	}
+func (s *Streamer) LatestQuote(symbol string) (*Quote, bool) {
+	s.latestMu.Lock()
+	entry, ok := s.latest[symbol]
+	var fields map[string]any
+	var timestamp int64
+	if ok {
+		fields, timestamp = maps.Clone(entry.fields), entry.timestamp
+	}
+	s.latestMu.Unlock()
+	if !ok {
+		return nil, false
+	}
 
-		// Run ping loop and read loop concurrently; whichever returns first
-		// tears down the connection for the other.
-		pingCtx, cancelPing := context.WithCancel(innerCtx)
-		defer cancelPing()
+	eq, err := DecodeLevelOneEquity(fields)
+	if err != nil {
+		s.logger.Debug("cached quote not decodable", "symbol", symbol, "error", err)
+		return nil, false
+	}
+	q := eq.Quote()
+	if q.QuoteData.QuoteTime == 0 {
+		q.QuoteData.QuoteTime = timestamp
+	}
+	return q, true
+}
 
-		go s.pingLoop(pingCtx, c)
+// Start connects, logs in, replays subscriptions, and then reads messages into
+// dataChan until the context is cancelled or an unrecoverable error occurs.
+// Transient disconnects are handled automatically with exponential backoff,
+// up to the WithMaxReconnectAttempts cap. Start returns nil once Close has
+// been called.
+func (s *Streamer) Start(ctx context.Context, dataChan chan<- []byte) error {
+	return s.supervise(ctx, dataChan, nil, false)
+}
+
+// Run supervises the connection like Start, but delivers decoded updates
+// instead of raw frames: handler, if non-nil, is called for every data
+// update of every service, after any handlers registered with OnData. It
+// connects, logs in, replays subscriptions, and reconnects with backoff
+// whenever the connection drops, returning only when ctx is cancelled
+// (ctx.Err()), Close is called (nil), reconnect attempts run out, or the
+// login fails. Unlike Start, Run does not retry a rejected login or a failure
+// to get an access token, since the same credentials would fail again; that
+// error wraps ErrStreamLoginFailed.
+//
+//	streamer.Subscribe(ctx, "LEVELONE_EQUITIES", []string{"AAPL"}, []string{"0", "1", "2", "3"}, "SUBS")
+//	err := streamer.Run(ctx, func(ctx context.Context, data schwabdev.StreamData) {
+//		log.Println(data.Service, data.Content)
+//	})
+func (s *Streamer) Run(ctx context.Context, handler DataHandler) error {
+	return s.supervise(ctx, nil, handler, true)
+}
 
-		return s.readLoop(innerCtx, c, dataChan)
+// supervise runs the reconnect loop behind Start and Run. With loginFatal
+// set, a login failure ends the loop instead of being retried.
+func (s *Streamer) supervise(ctx context.Context, dataChan chan<- []byte, handler DataHandler, loginFatal bool) error {
+	s.setClosed(false)
+	defer s.setState(StateDisconnected)
+	return s.reconnect.ReconnectWithBackoff(ctx, func(innerCtx context.Context) error {
+		if s.isClosed() {
+			return nil
+		}
+		if s.State() != StateReconnecting {
+			s.setState(StateConnecting)
+		}
+		sess, err := s.dial(innerCtx)
+		if err == nil {
+			sess.handler = handler
+			err = s.serve(innerCtx, sess, dataChan)
+		}
+		if loginFatal && errors.Is(err, ErrStreamLoginFailed) {
+			return terminalError{err}
+		}
+		if err != nil && !s.isClosed() {
+			s.setState(StateReconnecting)
+		}
+		return err
 	})
 }
 
-// Stop gracefully closes the WebSocket connection.
+// Connect dials the streamer, sends LOGIN and waits for Schwab to acknowledge
+// it, returning an error wrapping ErrStreamLoginFailed if the login is
+// rejected. On success recorded subscriptions are replayed and the keepalive
+// and read loops run in the background, delivering frames to dataChan until
+// the connection drops or ctx is cancelled. Connect does not reconnect; use
+// Start for a supervised connection.
+func (s *Streamer) Connect(ctx context.Context, dataChan chan<- []byte) error {
+	s.setClosed(false)
+	s.setState(StateConnecting)
+	sess, err := s.dial(ctx)
+	if err != nil {
+		s.setState(StateDisconnected)
+		return err
+	}
+	go func() {
+		defer s.setState(StateDisconnected)
+		if err := s.serve(ctx, sess, dataChan); err != nil && ctx.Err() == nil {
+			s.logger.Warn("stream connection closed", "error", err)
+		}
+	}()
+	return nil
+}
+
+// WaitForLogin blocks until the Streamer is logged in, for callers that run
+// Start or Run in another goroutine and want to know the connection is up
+// before going on. It returns nil at once if the Streamer is connected,
+// the error wrapping ErrStreamLoginFailed if Schwab rejects the next login,
+// or ctx.Err() when ctx is done; bound the wait with a context deadline.
+// Connection failures other than a rejected login are retried by Start and
+// keep WaitForLogin waiting.
+func (s *Streamer) WaitForLogin(ctx context.Context) error {
+	// Take the pending outcome before checking the state, so a login that
+	// completes in between still wakes us.
+	s.loginMu.Lock()
+	next := s.nextLogin
+	s.loginMu.Unlock()
+	if s.State() == StateConnected {
+		return nil
+	}
+
+	select {
+	case <-next.done:
+		return next.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loginOutcome is the result of a login attempt; err is set before done is
+// closed.
+type loginOutcome struct {
+	done chan struct{}
+	err  error
+}
+
+// loginFinished records the outcome of a login and wakes WaitForLogin.
+func (s *Streamer) loginFinished(err error) {
+	s.loginMu.Lock()
+	defer s.loginMu.Unlock()
+	s.nextLogin.err = err
+	close(s.nextLogin.done)
+	s.nextLogin = &loginOutcome{done: make(chan struct{})}
+}
+
+// Stop closes the WebSocket connection without logging out and cancels the
+// loops serving it; use Done to wait for them to exit.
 func (s *Streamer) Stop() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	if s.stopServe != nil {
+		s.stopServe()
+		s.stopServe = nil
+	}
 	if s.conn != nil {
 		s.conn.Close(websocket.StatusNormalClosure, "user requested stop")
 		s.conn = nil
 	}
 }
 
+// Close logs out and shuts the connection down gracefully: it queues an
+// ADMIN/LOGOUT request behind any pending writes, waits for the write loop to
+// send it, closes the WebSocket with a normal-closure status and waits for
+// the keepalive, write and read loops to exit. A Streamer run by Start stops
+// reconnecting. Calling Close when not connected, including a second time,
+// is a no-op that returns nil.
+func (s *Streamer) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	c, info, out, served := s.conn, s.info, s.out, s.served
+	s.conn, s.info, s.out = nil, nil, nil
+	s.mu.Unlock()
+	defer s.setState(StateDisconnected)
+
+	if c == nil {
+		return nil
+	}
+
+	err := s.logout(ctx, info, out, served)
+	if closeErr := c.Close(websocket.StatusNormalClosure, "logout"); err == nil && closeErr != nil {
+		err = fmt.Errorf("close websocket: %w", closeErr)
+	}
+
+	select {
+	case <-served:
+	case <-ctx.Done():
+		c.CloseNow()
+		return ctx.Err()
+	}
+	return err
+}
+
+// logout queues a LOGOUT request on out and waits until it has been written.
+func (s *Streamer) logout(ctx context.Context, info map[string]any, out chan<- outbound, served <-chan struct{}) error {
+	sent := make(chan error, 1)
+	req := outbound{req: s.buildRequest("ADMIN", "LOGOUT", map[string]any{}, info), sent: sent}
+
+	select {
+	case out <- req:
+	case <-served:
+		return fmt.Errorf("logout: connection already closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-sent:
+		if err != nil {
+			return fmt.Errorf("logout: %w", err)
+		}
+		return nil
+	case <-served:
+		return fmt.Errorf("logout: connection closed before LOGOUT was sent")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done returns a channel that is closed once the keepalive, write and read
+// loops of the current connection have all exited, whether because the
+// connection dropped, its context was cancelled, or Stop or Close was called.
+// When no connection has been made the channel is already closed.
+func (s *Streamer) Done() <-chan struct{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.served == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return s.served
+}
+
+func (s *Streamer) setClosed(closed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = closed
+}
+
+func (s *Streamer) isClosed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.closed
+}
+
+// ── Connection lifecycle ─────────────────────────────────────────────────────
+
+// dialOptions returns the WebSocket dial options for the configured dialer
+// and TLS config, or nil to use the library defaults.
+func (s *Streamer) dialOptions() *websocket.DialOptions {
+	if s.dialer == nil && s.tlsConfig == nil {
+		return nil
+	}
+	return &websocket.DialOptions{
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				DialContext:     s.dialer,
+				TLSClientConfig: s.tlsConfig,
+			},
+		},
+	}
+}
+
+// session is a logged-in connection together with its outbound queue and the
+// channel closed once serve has torn it down. dial hands it to serve directly
+// so a concurrent Close clearing s.out cannot leave serve without a queue.
+type session struct {
+	conn    *websocket.Conn
+	out     chan outbound
+	served  chan struct{}
+	handler DataHandler // Run's handler, if any
+}
+
+// dial opens the WebSocket, completes the LOGIN handshake and replays the
+// recorded subscriptions. On success the connection is published in s.conn
+// for the service methods to use.
+func (s *Streamer) dial(ctx context.Context) (*session, error) {
+	info, err := s.streamerInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get streamer info: %w", err)
+	}
+
+	wsURL, ok := info["streamerSocketUrl"].(string)
+	if s.streamerURL != "" {
+		wsURL, ok = s.streamerURL, true
+	}
+	if !ok || wsURL == "" {
+		return nil, fmt.Errorf("streamerSocketUrl missing or empty")
+	}
+
+	c, _, err := websocket.Dial(ctx, wsURL, s.dialOptions())
+	if err != nil {
+		return nil, fmt.Errorf("websocket dial: %w", err)
+	}
+	s.setState(StateAuthenticating)
+
+	// Responses to requests sent on an earlier connection will never arrive.
+	s.pendingMu.Lock()
+	clear(s.pending)
+	s.pendingMu.Unlock()
+	// Sequence numbers do not carry over to a new connection either.
+	s.seqMu.Lock()
+	clear(s.lastSeq)
+	s.seqMu.Unlock()
+
+	if err := s.login(ctx, c, info); err != nil {
+		c.Close(websocket.StatusInternalError, "login failed")
+		if errors.Is(err, ErrStreamLoginFailed) {
+			s.loginFinished(err)
+		}
+		return nil, fmt.Errorf("login: %w", err)
+	}
+
+	sess := &session{
+		conn:   c,
+		out:    make(chan outbound, s.writeBuffer),
+		served: make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.conn = c
+	s.info = info
+	s.out = sess.out
+	s.served = sess.served
+	s.mu.Unlock()
+	s.setState(StateConnected)
+	s.loginFinished(nil)
+
+	if err := s.resubscribe(ctx, info); err != nil {
+		// Non-fatal: log and continue — the read loop may still work.
+		s.logger.Error("resubscribe after reconnect failed", "error", err)
+	}
+
+	return sess, nil
+}
+
+// streamerInfo fetches the connection metadata for a new LOGIN, from the
+// function set with WithStreamerInfoFunc if any and the InfoSource otherwise.
+// It is called on every dial so a reconnect never reuses stale credentials.
+func (s *Streamer) streamerInfo(ctx context.Context) (map[string]any, error) {
+	if s.infoFunc == nil {
+		return s.infoSrc()
+	}
+	info, err := s.infoFunc(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return streamerInfoMap(info), nil
+}
+
+// streamerInfoMap converts a StreamerInfo into the map form used by
+// InfoSource.
+func streamerInfoMap(info *StreamerInfo) map[string]any {
+	return map[string]any{
+		"streamerSocketUrl":      info.StreamerURL,
+		"schwabClientCustomerId": info.SchwabClientCustomerID,
+		"schwabClientCorrelId":   info.SchwabClientCorrelID,
+		"schwabClientChannel":    info.SchwabClientChannel,
+		"schwabClientFunctionId": info.SchwabClientFunctionID,
+	}
+}
+
+// serve runs the keepalive, watchdog, write and read loops on a logged-in
+// connection until it drops or ctx is cancelled, and returns once all of
+// them have exited. It returns nil if the connection was closed by Close.
+func (s *Streamer) serve(ctx context.Context, sess *session, dataChan chan<- []byte) error {
+	c := sess.conn
+	defer close(sess.served)
+	defer func() {
+		s.mu.Lock()
+		if s.conn == c {
+			s.conn = nil
+			s.info = nil
+			s.out = nil
+			s.stopServe = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	s.reconnect.ResetBackoff()
+
+	// Run ping loop, watchdog, write loop and read loop concurrently;
+	// whichever returns first tears down the connection for the others.
+	// Cancelling ctx, or Stop, ends all of them.
+	loopCtx, cancelLoops := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancelLoops()
+
+	s.mu.Lock()
+	if s.conn == c {
+		s.stopServe = cancelLoops
+	}
+	s.mu.Unlock()
+
+	s.lastFrame.Store(time.Now().UnixNano())
+	wg.Go(func() { s.pingLoop(loopCtx, c) })
+	wg.Go(func() { s.watchdog(loopCtx, c) })
+	wg.Go(func() { s.writeLoop(loopCtx, c, sess.out) })
+
+	err := s.readLoop(loopCtx, c, dataChan, sess.handler)
+	if s.isClosed() {
+		return nil
+	}
+	return err
+}
+
 // ── Keepalive ────────────────────────────────────────────────────────────────
 
 // pingLoop sends a Ping frame every pingInterval. If the Pong is not received
 // within pingTimeout the connection is forcibly closed so the read loop detects
 // the error and triggers a reconnect.
 func (s *Streamer) pingLoop(ctx context.Context, c *websocket.Conn) {
-	ticker := time.NewTicker(pingInterval)
+	if s.pingInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.pingInterval)
 	defer ticker.Stop()
 
 	for {
@@ -142,10 +915,10 @@ func (s *Streamer) pingLoop(ctx context.Context, c *websocket.Conn) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			pingCtx, cancel := context.WithTimeout(ctx, pingTimeout)
+			pingCtx, cancel := context.WithTimeout(ctx, s.pingTimeout)
 			if err := c.Ping(pingCtx); err != nil {
 				s.logger.Warn("ping failed, closing connection", "error", err)
-				c.Close(websocket.StatusGoingAway, "ping timeout")
+				c.CloseNow()
 				cancel()
 				return
 			}
@@ -154,14 +927,92 @@ func (s *Streamer) pingLoop(ctx context.Context, c *websocket.Conn) {
 	}
 }
 
-// ── Read loop ────────────────────────────────────────────────────────────────
+// watchdog closes the connection when no frame has arrived for staleTimeout,
+// which makes the read loop fail and lets Start reconnect with backoff.
+func (s *Streamer) watchdog(ctx context.Context, c *websocket.Conn) {
+	if s.staleTimeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.staleTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			silent := time.Since(time.Unix(0, s.lastFrame.Load()))
+			if silent > s.staleTimeout {
+				s.logger.Warn("no frames received, closing stale connection", "silent", silent.Round(time.Millisecond))
+				c.CloseNow()
+				return
+			}
+		}
+	}
+}
+
+// ── Write & read loops ───────────────────────────────────────────────────────
 
-func (s *Streamer) readLoop(ctx context.Context, c *websocket.Conn, dataChan chan<- []byte) error {
+// outbound is a request queued for writeLoop. If sent is non-nil it receives
+// the result of the write; it must be buffered.
+type outbound struct {
+	req  map[string]any
+	sent chan<- error
+}
+
+// writeLoop drains the outbound queue onto the connection. A write that
+// fails or exceeds writeTimeout closes the connection so the read loop
+// fails and Start can reconnect.
+func (s *Streamer) writeLoop(ctx context.Context, c *websocket.Conn, out <-chan outbound) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case o := <-out:
+			err := s.writeFrame(ctx, c, o.req)
+			if o.sent != nil {
+				o.sent <- err
+			}
+			if err != nil {
+				s.logger.Warn("stream write failed, closing connection", "error", err)
+				c.CloseNow()
+				return
+			}
+		}
+	}
+}
+
+// writeFrame writes v to c, giving up after writeTimeout.
+func (s *Streamer) writeFrame(ctx context.Context, c *websocket.Conn, v any) error {
+	if s.writeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.writeTimeout)
+		defer cancel()
+	}
+	return wsjson.Write(ctx, c, v)
+}
+
+// readLoop routes every frame to the registered data handlers and forwards
+// the raw bytes to dataChan. dataChan may be nil when only handlers are used.
+func (s *Streamer) readLoop(ctx context.Context, c *websocket.Conn, dataChan chan<- []byte, handler DataHandler) error {
 	for {
 		_, msg, err := c.Read(ctx)
 		if err != nil {
 			return err
 		}
+		s.lastFrame.Store(time.Now().UnixNano())
+		s.mu.RLock()
+		tap := s.onRawMessage
+		s.mu.RUnlock()
+		if tap != nil {
+			tap(msg)
+		}
+		if err := s.route(ctx, msg, handler); err != nil {
+			s.logger.Debug("stream message not routed", "error", err)
+		}
+		if dataChan == nil {
+			continue
+		}
 		select {
 		case dataChan <- msg:
 		case <-ctx.Done():
@@ -172,11 +1023,11 @@ func (s *Streamer) readLoop(ctx context.Context, c *websocket.Conn, dataChan cha
 
 // ── Auth & subscription internals ───────────────────────────────────────────
 
-func (s *Streamer) login(ctx context.Context, info map[string]any) error {
+func (s *Streamer) login(ctx context.Context, c *websocket.Conn, info map[string]any) error {
 	// Always fetch a fresh token at login time so we never send a stale one.
 	token, err := s.tokens.AccessToken()
 	if err != nil {
-		return fmt.Errorf("get access token for login: %w", err)
+		return fmt.Errorf("%w: get access token: %w", ErrStreamLoginFailed, err)
 	}
 
 	params := map[string]any{
@@ -185,62 +1036,75 @@ func (s *Streamer) login(ctx context.Context, info map[string]any) error {
 		"SchwabClientFunctionId": info["schwabClientFunctionId"],
 	}
 	req := s.buildRequest("ADMIN", "LOGIN", params, info)
+	if err := s.writeFrame(ctx, c, req); err != nil {
+		return err
+	}
+	return s.awaitLogin(ctx, c)
+}
 
-	s.mu.RLock()
-	c := s.conn
-	s.mu.RUnlock()
+// awaitLogin reads frames until the ADMIN/LOGIN response arrives. Anything
+// received before it (e.g. heartbeats) is discarded.
+func (s *Streamer) awaitLogin(ctx context.Context, c *websocket.Conn) error {
+	ctx, cancel := context.WithTimeout(ctx, loginTimeout)
+	defer cancel()
 
-	return wsjson.Write(ctx, c, req)
+	for {
+		var msg StreamMessage
+		if err := wsjson.Read(ctx, c, &msg); err != nil {
+			return fmt.Errorf("await login response: %w", err)
+		}
+		for _, resp := range msg.Response {
+			if resp.Service != "ADMIN" || resp.Command != "LOGIN" {
+				continue
+			}
+			s.takePending(resp.RequestID)
+			if resp.Content.Code != 0 {
+				return fmt.Errorf("%w: code %d: %s", ErrStreamLoginFailed, resp.Content.Code, resp.Content.Msg)
+			}
+			return nil
+		}
+	}
 }
 
 func (s *Streamer) resubscribe(ctx context.Context, info map[string]any) error {
-	s.mu.RLock()
 	// Snapshot the subscription map so we don't hold the lock during I/O.
-	type subEntry struct {
-		service string
-		keys    []string
-		fields  []string
-	}
-	var entries []subEntry
-	for service, keysMap := range s.subscriptions {
-		// Group keys that share an identical field set into a single request.
-		fieldGroups := make(map[string][]string) // fieldsCSV → keys
-		for key, fields := range keysMap {
-			csv := strings.Join(fields, ",")
-			fieldGroups[csv] = append(fieldGroups[csv], key)
-		}
-		for fieldsCSV, keys := range fieldGroups {
-			entries = append(entries, subEntry{
-				service: service,
-				keys:    keys,
-				fields:  strings.Split(fieldsCSV, ","),
-			})
-		}
-	}
+	s.mu.RLock()
+	entries := s.snapshotLocked()
 	c := s.conn
 	s.mu.RUnlock()
 
 	for _, e := range entries {
 		params := map[string]any{
-			"keys":   strings.Join(e.keys, ","),
-			"fields": strings.Join(e.fields, ","),
+			"keys":   strings.Join(e.Keys, ","),
+			"fields": strings.Join(e.Fields, ","),
 		}
-		req := s.buildRequest(e.service, "ADD", params, info)
-		if err := wsjson.Write(ctx, c, req); err != nil {
+		req := s.buildRequest(e.Service, e.Command, params, info)
+		if err := s.writeFrame(ctx, c, req); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// buildRequest is the single place that assembles a Schwab streamer request
-// and increments the monotonic requestID. It intentionally does NOT acquire
-// s.mu — callers manage locking around the conn reference separately.
+// buildRequest is the single place that assembles a Schwab streamer request,
+// increments the monotonic requestID and records the request as pending. It
+// intentionally does NOT acquire s.mu — callers manage locking around the
+// conn reference separately.
 func (s *Streamer) buildRequest(service, command string, params map[string]any, info map[string]any) map[string]any {
 	id := s.requestID.Add(1)
+	service, command = strings.ToUpper(service), strings.ToUpper(command)
+
+	sent := SentRequest{ID: id, Service: service, Command: command, SentAt: time.Now()}
+	if keys, _ := params["keys"].(string); keys != "" {
+		sent.Keys = strings.Split(keys, ",")
+	}
+	s.pendingMu.Lock()
+	s.pending[id] = sent
+	s.pendingMu.Unlock()
+
 	return map[string]any{
-		"service":                strings.ToUpper(service),
-		"command":                strings.ToUpper(command),
+		"service":                service,
+		"command":                command,
 		"requestid":              id,
 		"SchwabClientCustomerId": info["schwabClientCustomerId"],
 		"SchwabClientCorrelId":   info["schwabClientCorrelId"],
@@ -248,25 +1112,123 @@ func (s *Streamer) buildRequest(service, command string, params map[string]any,
 	}
 }
 
-// record stores a subscription so it can be replayed after a reconnect.
-func (s *Streamer) record(service, command string, keys, fields []string) {
+// record stores a subscription so it can be replayed after a reconnect. It
+// follows Schwab's semantics: SUBS replaces every key of the service with
+// keys, while ADD keeps the existing keys and merges fields into the field
+// set of any key that is already subscribed.
+func (s *Streamer) record(service, command string, keys, fields []string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if strings.ToUpper(command) == "UNSUBS" {
+		// Only the listed keys go; the others keep their recorded fields.
+		if err := s.checkSubscribedLocked(service, keys); err != nil {
+			return err
+		}
+		for _, k := range keys {
+			delete(s.subscriptions[service], k)
+		}
+		if len(s.subscriptions[service]) == 0 {
+			delete(s.subscriptions, service)
+		}
+		return nil
+	}
+
 	if s.subscriptions[service] == nil {
+		if strings.ToUpper(command) == "VIEW" {
+			return nil
+		}
 		s.subscriptions[service] = make(map[string][]string)
 	}
 
 	switch strings.ToUpper(command) {
-	case "ADD", "SUBS":
+	case "SUBS":
+		clear(s.subscriptions[service])
 		for _, k := range keys {
-			s.subscriptions[service][k] = fields
+			s.subscriptions[service][k] = slices.Clone(fields)
 		}
-	case "UNSUBS":
+	case "ADD":
 		for _, k := range keys {
-			delete(s.subscriptions[service], k)
+			merged := slices.Clone(s.subscriptions[service][k])
+			for _, f := range fields {
+				if !slices.Contains(merged, f) {
+					merged = append(merged, f)
+				}
+			}
+			s.subscriptions[service][k] = merged
+		}
+	case "VIEW":
+		for k := range s.subscriptions[service] {
+			s.subscriptions[service][k] = slices.Clone(fields)
+		}
+	}
+	return nil
+}
+
+// checkSubscribedLocked returns an error wrapping ErrNotSubscribed naming the
+// first of keys not recorded for service. s.mu must be held.
+func (s *Streamer) checkSubscribedLocked(service string, keys []string) error {
+	for _, k := range keys {
+		if _, ok := s.subscriptions[service][k]; !ok {
+			return fmt.Errorf("%w: %s key %q", ErrNotSubscribed, service, k)
+		}
+	}
+	return nil
+}
+
+// Snapshot returns the recorded subscriptions as ADD requests ready to pass to
+// SendBatch, one per service and field set, with keys that share a field set
+// grouped together. The result is ordered by service, then fields, then keys,
+// and shares no memory with the Streamer.
+func (s *Streamer) Snapshot() []*Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snapshotLocked()
+}
+
+// snapshotLocked implements Snapshot; the caller must hold s.mu.
+func (s *Streamer) snapshotLocked() []*Subscription {
+	var subs []*Subscription
+	for service, keysMap := range s.subscriptions {
+		// Group keys that share an identical field set into a single request.
+		fieldGroups := make(map[string][]string) // fieldsCSV → keys
+		for key, fields := range keysMap {
+			csv := strings.Join(fields, ",")
+			fieldGroups[csv] = append(fieldGroups[csv], key)
+		}
+		for fieldsCSV, keys := range fieldGroups {
+			slices.Sort(keys)
+			var fields []string
+			if fieldsCSV != "" {
+				fields = strings.Split(fieldsCSV, ",")
+			}
+			subs = append(subs, &Subscription{Service: service, Command: "ADD", Keys: keys, Fields: fields})
+		}
+	}
+	slices.SortFunc(subs, func(a, b *Subscription) int {
+		return cmp.Or(
+			cmp.Compare(a.Service, b.Service),
+			cmp.Compare(strings.Join(a.Fields, ","), strings.Join(b.Fields, ",")),
+			cmp.Compare(a.Keys[0], b.Keys[0]),
+		)
+	})
+	return subs
+}
+
+// Subscriptions returns a copy of the recorded subscriptions as
+// service → key → fields. These are what Start replays after a reconnect.
+func (s *Streamer) Subscriptions() map[string]map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make(map[string]map[string][]string, len(s.subscriptions))
+	for service, keys := range s.subscriptions {
+		out[service] = make(map[string][]string, len(keys))
+		for k, fields := range keys {
+			out[service][k] = slices.Clone(fields)
 		}
 	}
+	return out
 }
 
 // send records the subscription and writes the request to the WebSocket.
@@ -276,13 +1238,12 @@ func (s *Streamer) send(ctx context.Context, service, command string, keys, fiel
 		return fmt.Errorf("send %s/%s: keys must not be empty", service, command)
 	}
 
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
 	if strings.ToUpper(command) != "LOGOUT" {
-		s.record(service, command, keys, fields)
-	}
-
-	info, err := s.infoSrc()
-	if err != nil {
-		return fmt.Errorf("get streamer info: %w", err)
+		if err := s.record(service, command, keys, fields); err != nil {
+			return fmt.Errorf("send %s/%s: %w", service, command, err)
+		}
 	}
 
 	params := map[string]any{
@@ -291,24 +1252,186 @@ func (s *Streamer) send(ctx context.Context, service, command string, keys, fiel
 	}
 	maps.Copy(params, extra)
 
+	return s.write(ctx, service, command, params)
+}
+
+// write builds a request for the current session and queues it for the
+// write loop. It never blocks: if the queue is full it returns
+// ErrStreamWriteBufferFull.
+func (s *Streamer) write(ctx context.Context, service, command string, params map[string]any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	info, out, ok := s.session()
+	if !ok {
+		return fmt.Errorf("%s: %w", service, ErrStreamNotConnected)
+	}
 	req := s.buildRequest(service, command, params, info)
+	if err := enqueue(service, out, req); err != nil {
+		s.forgetPending(req)
+		return err
+	}
+	return nil
+}
 
+// session returns the streamer info and outbound queue of the current
+// connection, or false if there is none.
+func (s *Streamer) session() (map[string]any, chan<- outbound, bool) {
 	s.mu.RLock()
-	c := s.conn
+	defer s.mu.RUnlock()
+	return s.info, s.out, s.conn != nil
+}
+
+// enqueue hands frame to the write loop without blocking. label identifies
+// the frame in errors.
+func enqueue(label string, out chan<- outbound, frame map[string]any) error {
+	select {
+	case out <- outbound{req: frame}:
+		return nil
+	default:
+		return fmt.Errorf("%s: %w", label, ErrStreamWriteBufferFull)
+	}
+}
+
+// SendBatch records every subscription in subs and sends them to the
+// streamer in a single {"requests": [...]} frame, each request with its own
+// request ID. An empty Command means "ADD". Every subscription needs a
+// service and at least one key, and an UNSUBS may only name subscribed keys;
+// if one does not, nothing is recorded or sent.
+func (s *Streamer) SendBatch(ctx context.Context, subs []*Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+	for i, sub := range subs {
+		if sub == nil || sub.Service == "" || len(sub.Keys) == 0 {
+			return fmt.Errorf("send batch: subscription %d needs a service and keys", i)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.RLock()
+	for _, sub := range subs {
+		if strings.ToUpper(sub.Command) != "UNSUBS" {
+			continue
+		}
+		if err := s.checkSubscribedLocked(strings.ToUpper(sub.Service), sub.Keys); err != nil {
+			s.mu.RUnlock()
+			return fmt.Errorf("send batch: %w", err)
+		}
+	}
 	s.mu.RUnlock()
 
-	if c == nil {
-		return fmt.Errorf("%s: streamer not connected", service)
+	for _, sub := range subs {
+		if err := s.record(strings.ToUpper(sub.Service), cmp.Or(sub.Command, "ADD"), sub.Keys, sub.Fields); err != nil {
+			return fmt.Errorf("send batch: %w", err)
+		}
+	}
+
+	info, out, ok := s.session()
+	if !ok {
+		return fmt.Errorf("send batch: %w", ErrStreamNotConnected)
+	}
+	requests := make([]map[string]any, len(subs))
+	for i, sub := range subs {
+		params := map[string]any{
+			"keys":   strings.Join(sub.Keys, ","),
+			"fields": strings.Join(sub.Fields, ","),
+		}
+		requests[i] = s.buildRequest(sub.Service, cmp.Or(sub.Command, "ADD"), params, info)
+	}
+	if err := enqueue("send batch", out, map[string]any{"requests": requests}); err != nil {
+		s.forgetPending(requests...)
+		return err
+	}
+	return nil
+}
+
+// View changes the fields streamed for every subscribed key of service
+// without re-subscribing. The recorded field set is updated so reconnects
+// replay the new fields.
+func (s *Streamer) View(ctx context.Context, service string, fields []string) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("view %s: fields must not be empty", service)
+	}
+	service = strings.ToUpper(service)
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.record(service, "VIEW", nil, fields)
+	return s.write(ctx, service, "VIEW", map[string]any{"fields": strings.Join(fields, ",")})
+}
+
+// RemoveSubscription forgets the recorded subscription for key on service so
+// it is not replayed after a reconnect. Nothing is sent to the server; use
+// the service method with "UNSUBS" to stop the stream itself.
+func (s *Streamer) RemoveSubscription(service, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	service = strings.ToUpper(service)
+	delete(s.subscriptions[service], key)
+	if len(s.subscriptions[service]) == 0 {
+		delete(s.subscriptions, service)
+	}
+}
+
+// UnsubscribeAll sends one UNSUBS request per service covering every
+// recorded key and clears the recorded subscriptions. It is intended for
+// clean shutdown. Services whose request fails stay recorded and their
+// errors are joined into the returned error.
+func (s *Streamer) UnsubscribeAll(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.mu.RLock()
+	services := make(map[string][]string, len(s.subscriptions))
+	for service, keys := range s.subscriptions {
+		if len(keys) > 0 {
+			services[service] = slices.Sorted(maps.Keys(keys))
+		}
+	}
+	s.mu.RUnlock()
+
+	var errs []error
+	for _, service := range slices.Sorted(maps.Keys(services)) {
+		params := map[string]any{"keys": strings.Join(services[service], ",")}
+		if err := s.write(ctx, service, "UNSUBS", params); err != nil {
+			errs = append(errs, fmt.Errorf("unsubscribe %s: %w", service, err))
+			continue
+		}
+		for _, key := range services[service] {
+			s.RemoveSubscription(service, key)
+		}
 	}
-	return wsjson.Write(ctx, c, req)
+	return errors.Join(errs...)
 }
 
 // ── Public service methods ───────────────────────────────────────────────────
 //
-// command is typically "ADD", "SUBS", or "UNSUBS".
+// command is typically "ADD", "SUBS", or "UNSUBS". SUBS replaces every
+// subscription of the service; ADD adds keys and widens the fields of keys
+// already subscribed; UNSUBS removes only the listed keys, leaving the rest
+// and their fields in place. UNSUBS of a key that is not subscribed returns
+// an error wrapping ErrNotSubscribed and sends nothing. Subscriptions
+// records the result for replay.
 // fields are integer indices expressed as strings ("0", "1", …) matching the
 // StreamFields map in translate.go.
 
+// Subscribe sends command for keys and fields of any streaming service, like
+// the typed service methods below. The subscription is recorded before it is
+// sent and every reconnect made by Start replays the recorded subscriptions,
+// so callers subscribe once and never resubscribe themselves. If the
+// streamer is not connected the subscription is only recorded, to be sent
+// when it connects, and Subscribe returns nil.
+func (s *Streamer) Subscribe(ctx context.Context, service string, keys, fields []string, command string) error {
+	err := s.send(ctx, strings.ToUpper(service), command, keys, fields, nil)
+	if errors.Is(err, ErrStreamNotConnected) {
+		return nil
+	}
+	return err
+}
+
 func (s *Streamer) LevelOneEquities(ctx context.Context, keys, fields []string, command string) error {
 	return s.send(ctx, "LEVELONE_EQUITIES", command, keys, fields, nil)
 }
@@ -379,12 +1502,15 @@ type ReconnectManager struct {
 	maxBackoff   time.Duration
 	minUptime    time.Duration
 	jitterFactor float64
+	maxAttempts  int // 0 = retry forever
+	attempts     int // backoff waits since the last stable connection
 }
 
-// NewReconnectManager returns a ReconnectManager with sensible defaults.
+// NewReconnectManager returns a ReconnectManager with sensible defaults. A nil
+// logger discards all output.
 func NewReconnectManager(logger *slog.Logger) *ReconnectManager {
 	return &ReconnectManager{
-		logger:       logger,
+		logger:       loggerOrDiscard(logger),
 		baseBackoff:  2 * time.Second,
 		backoffTime:  2 * time.Second,
 		maxBackoff:   120 * time.Second,
@@ -400,10 +1526,43 @@ func (r *ReconnectManager) ResetBackoff() {
 	r.backoffTime = r.baseBackoff
 }
 
+// SetMaxAttempts caps how many times ReconnectWithBackoff retries after
+// consecutive failures before giving up with ErrReconnectAttemptsExhausted.
+// n <= 0 retries forever, which is the default.
+func (r *ReconnectManager) SetMaxAttempts(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.maxAttempts = n
+}
+
+// Attempts returns how many times ReconnectWithBackoff has backed off since
+// the last connection that stayed up for the minimum uptime.
+func (r *ReconnectManager) Attempts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts
+}
+
+// ShouldReconnect reports whether another attempt is allowed under the
+// SetMaxAttempts cap.
+func (r *ReconnectManager) ShouldReconnect() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.maxAttempts <= 0 || r.attempts < r.maxAttempts
+}
+
+func (r *ReconnectManager) resetAttempts() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = 0
+}
+
 func (r *ReconnectManager) nextSleep() time.Duration {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	r.attempts++
+
 	jitter := float64(r.backoffTime) * r.jitterFactor * (rand.Float64()*2 - 1)
 	sleep := r.backoffTime + time.Duration(jitter)
 
@@ -413,9 +1572,20 @@ func (r *ReconnectManager) nextSleep() time.Duration {
 	return sleep
 }
 
+// terminalError marks a connection error that retrying cannot fix;
+// ReconnectWithBackoff returns the wrapped error without reconnecting.
+type terminalError struct{ err error }
+
+func (e terminalError) Error() string { return e.err.Error() }
+
+func (e terminalError) Unwrap() error { return e.err }
+
 // ReconnectWithBackoff calls connectFunc in a loop, backing off between
-// failures. It returns only when the context is cancelled or connectFunc
-// returns nil (success without a disconnect).
+// failures. It returns when the context is cancelled, connectFunc returns nil
+// (success without a disconnect), or the SetMaxAttempts cap is reached, in
+// which case the error wraps ErrReconnectAttemptsExhausted and the last
+// connection error. A connectFunc error wrapping a terminalError also ends
+// the loop, returning the error it marks without reconnecting.
 func (r *ReconnectManager) ReconnectWithBackoff(ctx context.Context, connectFunc func(context.Context) error) error {
 	for {
 		if ctx.Err() != nil {
@@ -429,9 +1599,17 @@ func (r *ReconnectManager) ReconnectWithBackoff(ctx context.Context, connectFunc
 		if err == nil {
 			return nil
 		}
+		var terminal terminalError
+		if errors.As(err, &terminal) {
+			return terminal.err
+		}
 
 		if uptime > r.minUptime {
 			r.ResetBackoff()
+			r.resetAttempts()
+		}
+		if !r.ShouldReconnect() {
+			return fmt.Errorf("%w after %d attempts: %w", ErrReconnectAttemptsExhausted, r.Attempts(), err)
 		}
 
 		sleep := r.nextSleep()
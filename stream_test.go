@@ -0,0 +1,1662 @@
+package schwabdev_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	schwabdev "github.com/citizenadam/go-schwabapi"
+)
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+// staticToken is a TokenProvider that always returns the same token.
+type staticToken string
+
+func (s staticToken) AccessToken() (string, error) { return string(s), nil }
+
+// streamRequest is the shape of a request frame sent by the Streamer.
+type streamRequest struct {
+	Service    string         `json:"service"`
+	Command    string         `json:"command"`
+	RequestID  int64          `json:"requestid"`
+	Parameters map[string]any `json:"parameters"`
+}
+
+// mockStreamServer runs handle for every WebSocket connection accepted.
+func mockStreamServer(t *testing.T, handle func(ctx context.Context, c *websocket.Conn)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		defer c.CloseNow()
+		handle(r.Context(), c)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestStreamer returns a Streamer whose info source points at srv. srv may
+// be nil for tests that never connect.
+func newTestStreamer(srv *httptest.Server, opts ...schwabdev.StreamerOption) *schwabdev.Streamer {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	wsURL := ""
+	if srv != nil {
+		wsURL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	}
+	info := func() (map[string]any, error) {
+		return map[string]any{
+			"streamerSocketUrl":      wsURL,
+			"schwabClientChannel":    "N9",
+			"schwabClientFunctionId": "APIAPP",
+			"schwabClientCustomerId": "customer",
+			"schwabClientCorrelId":   "correl",
+		}, nil
+	}
+	return schwabdev.NewStreamer(logger, staticToken(testAccessToken), info, opts...)
+}
+
+// ackLogin reads the LOGIN request and answers it with code.
+func ackLogin(ctx context.Context, c *websocket.Conn, code int) (streamRequest, error) {
+	var req streamRequest
+	if err := wsjson.Read(ctx, c, &req); err != nil {
+		return req, err
+	}
+	return req, wsjson.Write(ctx, c, map[string]any{
+		"response": []map[string]any{{
+			"service":   "ADMIN",
+			"command":   "LOGIN",
+			"requestid": "1",
+			"content":   map[string]any{"code": code, "msg": "status"},
+		}},
+	})
+}
+
+// recordingServer acks LOGIN and then forwards every request it receives on
+// the returned channel.
+func recordingServer(t *testing.T) (*httptest.Server, <-chan streamRequest) {
+	t.Helper()
+	frames := make(chan streamRequest, 64)
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		for {
+			var req streamRequest
+			if err := wsjson.Read(ctx, c, &req); err != nil {
+				return
+			}
+			frames <- req
+		}
+	})
+	return srv, frames
+}
+
+// connectStreamer connects streamer and stops it when the test ends.
+func connectStreamer(t *testing.T, streamer *schwabdev.Streamer) context.Context {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	if err := streamer.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	t.Cleanup(streamer.Stop)
+	return ctx
+}
+
+// nextFrame returns the next request received by a recordingServer.
+func nextFrame(t *testing.T, frames <-chan streamRequest) streamRequest {
+	t.Helper()
+	select {
+	case req := <-frames:
+		return req
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a request frame")
+		return streamRequest{}
+	}
+}
+
+// ── Connect ───────────────────────────────────────────────────────────────────
+
+func TestStreamer_ConnectSendsLogin(t *testing.T) {
+	loginCh := make(chan streamRequest, 1)
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		// A heartbeat before the ack must not confuse the handshake.
+		wsjson.Write(ctx, c, map[string]any{"notify": []map[string]any{{"heartbeat": "1"}}})
+		req, err := ackLogin(ctx, c, 0)
+		if err != nil {
+			return
+		}
+		loginCh <- req
+		c.Read(ctx) // hold the connection open
+	})
+
+	streamer := newTestStreamer(srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer streamer.Stop()
+
+	if err := streamer.Connect(ctx, make(chan []byte, 1)); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	req := <-loginCh
+	if req.Service != "ADMIN" || req.Command != "LOGIN" {
+		t.Errorf("first frame: want ADMIN/LOGIN, got %s/%s", req.Service, req.Command)
+	}
+	if req.Parameters["Authorization"] != testAccessToken {
+		t.Errorf("Authorization: want %q, got %v", testAccessToken, req.Parameters["Authorization"])
+	}
+	if req.Parameters["SchwabClientChannel"] != "N9" || req.Parameters["SchwabClientFunctionId"] != "APIAPP" {
+		t.Errorf("login parameters: %v", req.Parameters)
+	}
+}
+
+func TestStreamer_ConnectLoginRejected(t *testing.T) {
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		ackLogin(ctx, c, 3)
+		c.Read(ctx)
+	})
+
+	streamer := newTestStreamer(srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := streamer.Connect(ctx, make(chan []byte, 1))
+	if !errors.Is(err, schwabdev.ErrStreamLoginFailed) {
+		t.Fatalf("want ErrStreamLoginFailed, got %v", err)
+	}
+}
+
+func TestStreamer_WaitForLogin(t *testing.T) {
+	release := make(chan struct{})
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+			return
+		}
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		c.Read(ctx)
+	})
+
+	streamer := newTestStreamer(srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	short, cancelShort := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancelShort()
+	if err := streamer.WaitForLogin(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("before Start: want context.DeadlineExceeded, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- streamer.Start(ctx, nil) }()
+	waited := make(chan error, 1)
+	go func() { waited <- streamer.WaitForLogin(ctx) }()
+
+	time.Sleep(20 * time.Millisecond)
+	select {
+	case err := <-waited:
+		t.Fatalf("WaitForLogin returned before the LOGIN response: %v", err)
+	default:
+	}
+	close(release)
+	if err := <-waited; err != nil {
+		t.Fatalf("WaitForLogin: %v", err)
+	}
+	if got := streamer.State(); got != schwabdev.StateConnected {
+		t.Errorf("state after WaitForLogin = %v, want connected", got)
+	}
+	if err := streamer.WaitForLogin(ctx); err != nil {
+		t.Errorf("WaitForLogin while connected: %v", err)
+	}
+	cancel()
+	<-done
+}
+
+func TestStreamer_WaitForLoginReportsRejection(t *testing.T) {
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		ackLogin(ctx, c, 3)
+	})
+
+	streamer := newTestStreamer(srv, schwabdev.WithReconnectBackoff(time.Millisecond, 2*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Start keeps retrying, so WaitForLogin sees a rejection however late
+	// it starts waiting.
+	done := make(chan error, 1)
+	go func() { done <- streamer.Start(ctx, nil) }()
+
+	if err := streamer.WaitForLogin(ctx); !errors.Is(err, schwabdev.ErrStreamLoginFailed) {
+		t.Errorf("WaitForLogin: want ErrStreamLoginFailed, got %v", err)
+	}
+	cancel()
+	<-done
+}
+
+// ── Data routing ──────────────────────────────────────────────────────────────
+
+const levelOneFrame = `{"data":[
+	{"service":"LEVELONE_EQUITIES","timestamp":1715900000000,"command":"SUBS",
+	 "content":[{"key":"AAPL","1":190.1,"2":190.2,"3":190.15}]},
+	{"service":"CHART_EQUITY","timestamp":1715900000000,"command":"SUBS",
+	 "content":[{"key":"MSFT","1":420.0}]}
+]}`
+
+func TestStreamer_RouteMessage_InvokesHandlers(t *testing.T) {
+	streamer := newTestStreamer(nil)
+
+	var equities, all []string
+	streamer.OnData("LEVELONE_EQUITIES", func(_ context.Context, d schwabdev.StreamData) {
+		equities = append(equities, "first:"+d.Content[0]["key"].(string))
+	})
+	streamer.OnData("levelone_equities", func(_ context.Context, d schwabdev.StreamData) {
+		equities = append(equities, "second:"+d.Content[0]["key"].(string))
+	})
+	streamer.OnData("", func(_ context.Context, d schwabdev.StreamData) {
+		all = append(all, d.Service)
+	})
+
+	if err := streamer.RouteMessage(context.Background(), []byte(levelOneFrame)); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+
+	if want := []string{"first:AAPL", "second:AAPL"}; !slices.Equal(equities, want) {
+		t.Errorf("equity handlers: want %v, got %v", want, equities)
+	}
+	if want := []string{"LEVELONE_EQUITIES", "CHART_EQUITY"}; !slices.Equal(all, want) {
+		t.Errorf("catch-all handler: want %v, got %v", want, all)
+	}
+}
+
+func TestStreamer_RouteMessage_InvalidJSON(t *testing.T) {
+	streamer := newTestStreamer(nil)
+	if err := streamer.RouteMessage(context.Background(), []byte(`{"data":`)); err == nil {
+		t.Fatal("want decode error")
+	}
+}
+
+func TestStreamer_ConnectRoutesData(t *testing.T) {
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		c.Write(ctx, websocket.MessageText, []byte(levelOneFrame))
+		c.Read(ctx)
+	})
+
+	streamer := newTestStreamer(srv)
+	got := make(chan schwabdev.StreamData, 1)
+	streamer.OnData("LEVELONE_EQUITIES", func(_ context.Context, d schwabdev.StreamData) { got <- d })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer streamer.Stop()
+
+	if err := streamer.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	select {
+	case d := <-got:
+		if d.Content[0]["key"] != "AAPL" {
+			t.Errorf("unexpected content: %v", d.Content)
+		}
+	case <-ctx.Done():
+		t.Fatal("data handler not called")
+	}
+}
+
+func TestStreamer_OnRawMessage(t *testing.T) {
+	const unknown = `{"data":[{"service":"NEW_SERVICE","timestamp":1715900000000,"content":[{"key":"X"}]}]}`
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		c.Write(ctx, websocket.MessageText, []byte(levelOneFrame))
+		c.Write(ctx, websocket.MessageText, []byte(unknown))
+		c.Read(ctx)
+	})
+
+	streamer := newTestStreamer(srv)
+	type tapped struct {
+		data   string
+		routed int32 // LEVELONE_EQUITIES updates routed before the tap saw data
+	}
+	raw := make(chan tapped, 2)
+	var routed atomic.Int32
+	streamer.OnRawMessage(func(data []byte) {
+		raw <- tapped{string(data), routed.Load()}
+	})
+	streamer.OnData("LEVELONE_EQUITIES", func(ctx context.Context, data schwabdev.StreamData) {
+		routed.Add(1)
+	})
+	connectStreamer(t, streamer)
+
+	for i, want := range []string{levelOneFrame, unknown} {
+		select {
+		case got := <-raw:
+			if got.data != want {
+				t.Errorf("tap got %s, want %s", got.data, want)
+			}
+			if got.routed != int32(i) {
+				t.Errorf("frame %d: tap called after routing (%d updates routed)", i, got.routed)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("tap not called")
+		}
+	}
+}
+
+func TestStreamer_OnSequenceGap(t *testing.T) {
+	type gap struct {
+		service, key  string
+		expected, got int
+	}
+	var gaps []gap
+	streamer := newTestStreamer(nil)
+	streamer.OnSequenceGap(func(service, key string, expected, got int) {
+		gaps = append(gaps, gap{service, key, expected, got})
+	})
+
+	route := func(service string, entries ...string) {
+		t.Helper()
+		frame := fmt.Sprintf(`{"data":[{"service":%q,"timestamp":1715900000000,"command":"SUBS","content":[%s]}]}`,
+			service, strings.Join(entries, ","))
+		if err := streamer.RouteMessage(context.Background(), []byte(frame)); err != nil {
+			t.Fatalf("RouteMessage: %v", err)
+		}
+	}
+	route("CHART_EQUITY", `{"key":"AAPL","seq":1}`, `{"key":"MSFT","seq":40}`)
+	route("CHART_EQUITY", `{"key":"AAPL","seq":2}`, `{"key":"MSFT","seq":41}`)
+	route("NASDAQ_BOOK", `{"key":"AAPL","seq":7}`)
+	route("CHART_EQUITY", `{"key":"AAPL","seq":3}`)
+	if len(gaps) != 0 {
+		t.Fatalf("in-order sequences reported gaps: %+v", gaps)
+	}
+
+	route("CHART_EQUITY", `{"key":"AAPL","seq":6}`, `{"key":"MSFT","seq":42}`)
+	route("NASDAQ_BOOK", `{"key":"AAPL","seq":9}`)
+	route("CHART_EQUITY", `{"key":"AAPL","seq":1}`) // restarted, not a gap
+	route("CHART_EQUITY", `{"key":"AAPL","seq":2}`)
+	route("LEVELONE_EQUITIES", `{"key":"AAPL","3":190.5}`)
+
+	want := []gap{
+		{"CHART_EQUITY", "AAPL", 4, 6},
+		{"NASDAQ_BOOK", "AAPL", 8, 9},
+	}
+	if !slices.Equal(gaps, want) {
+		t.Errorf("gaps = %+v, want %+v", gaps, want)
+	}
+}
+
+func TestStreamer_LatestQuote(t *testing.T) {
+	partial := `{"data":[{"service":"LEVELONE_EQUITIES","timestamp":1715900001000,"command":"SUBS",
+		"content":[{"key":"AAPL","3":190.5,"34":1715900000900}]}]}`
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		c.Write(ctx, websocket.MessageText, []byte(levelOneFrame))
+		c.Write(ctx, websocket.MessageText, []byte(partial))
+		c.Read(ctx)
+	})
+
+	streamer := newTestStreamer(srv)
+	if _, ok := streamer.LatestQuote("AAPL"); ok {
+		t.Fatal("LatestQuote before any data should report ok=false")
+	}
+
+	// The first frame's quote time falls back to the frame timestamp.
+	if err := streamer.RouteMessage(context.Background(), []byte(levelOneFrame)); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	q, ok := streamer.LatestQuote("AAPL")
+	if !ok || q.Symbol != "AAPL" || q.QuoteData.LastPrice != 190.15 || q.QuoteData.QuoteTime != 1715900000000 {
+		t.Fatalf("after first frame: %+v, %v", q, ok)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	defer streamer.Stop()
+	if err := streamer.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	for {
+		q, _ = streamer.LatestQuote("AAPL")
+		if q.QuoteData.LastPrice == 190.5 {
+			break
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("cache never updated; last %+v", q.QuoteData)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// The partial update keeps the bid and ask from the earlier frame.
+	if d := q.QuoteData; d.BidPrice != 190.1 || d.AskPrice != 190.2 || d.QuoteTime != 1715900000900 {
+		t.Errorf("merged quote = %+v", d)
+	}
+	if _, ok := streamer.LatestQuote("MSFT"); ok {
+		t.Error("CHART_EQUITY data should not populate LatestQuote")
+	}
+}
+
+// ── Heartbeats ────────────────────────────────────────────────────────────────
+
+func TestStreamer_RouteMessage_Heartbeat(t *testing.T) {
+	streamer := newTestStreamer(nil)
+	if !streamer.LastHeartbeat().IsZero() {
+		t.Fatal("LastHeartbeat should be zero before any heartbeat")
+	}
+
+	var called time.Time
+	streamer.OnHeartbeat(func(at time.Time) { called = at })
+	streamer.OnData("", func(context.Context, schwabdev.StreamData) {
+		t.Error("heartbeat must not be routed as data")
+	})
+
+	before := time.Now()
+	if err := streamer.RouteMessage(context.Background(), []byte(`{"notify":[{"heartbeat":"1715900000000"}]}`)); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+
+	last := streamer.LastHeartbeat()
+	if last.Before(before) {
+		t.Errorf("LastHeartbeat not updated: %v", last)
+	}
+	if !called.Equal(last) {
+		t.Errorf("OnHeartbeat: want %v, got %v", last, called)
+	}
+}
+
+// ── Keepalive pings ───────────────────────────────────────────────────────────
+
+func TestStreamer_PingsAtConfiguredInterval(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		pings []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+			OnPingReceived: func(context.Context, []byte) bool {
+				mu.Lock()
+				pings = append(pings, time.Now())
+				mu.Unlock()
+				return true
+			},
+		})
+		if err != nil {
+			return
+		}
+		defer c.CloseNow()
+		if _, err := ackLogin(r.Context(), c, 0); err != nil {
+			return
+		}
+		for {
+			if _, _, err := c.Read(r.Context()); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	streamer := newTestStreamer(srv, schwabdev.WithPingInterval(50*time.Millisecond, time.Second))
+	connectStreamer(t, streamer)
+	time.Sleep(330 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(pings) < 4 || len(pings) > 7 {
+		t.Fatalf("got %d pings in 330ms at a 50ms interval", len(pings))
+	}
+	for i := 1; i < len(pings); i++ {
+		if gap := pings[i].Sub(pings[i-1]); gap < 30*time.Millisecond {
+			t.Errorf("ping %d came %v after the previous one", i, gap)
+		}
+	}
+}
+
+func TestStreamer_UnansweredPingClosesConnection(t *testing.T) {
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		// Never read again, so pings go unanswered.
+		time.Sleep(2 * time.Second)
+	})
+
+	streamer := newTestStreamer(srv,
+		schwabdev.WithStaleTimeout(0),
+		schwabdev.WithPingInterval(50*time.Millisecond, 100*time.Millisecond))
+	connectStreamer(t, streamer)
+
+	select {
+	case <-streamer.Done():
+	case <-time.After(time.Second):
+		t.Fatal("connection still open after an unanswered ping")
+	}
+}
+
+// ── Stale connection watchdog ─────────────────────────────────────────────────
+
+func TestStreamer_WatchdogClosesSilentConnection(t *testing.T) {
+	closed := make(chan error, 1)
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		// Go silent; the read returns once the client drops the socket.
+		_, _, err := c.Read(ctx)
+		closed <- err
+	})
+
+	streamer := newTestStreamer(srv, schwabdev.WithStaleTimeout(100*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := streamer.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	select {
+	case err := <-closed:
+		if err == nil {
+			t.Error("want read error after watchdog close")
+		}
+	case <-ctx.Done():
+		t.Fatal("watchdog did not close the silent connection")
+	}
+}
+
+func TestStreamer_WatchdogTriggersReconnect(t *testing.T) {
+	var conns atomic.Int32
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		conns.Add(1)
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		c.Read(ctx)
+	})
+
+	streamer := newTestStreamer(srv,
+		schwabdev.WithStaleTimeout(100*time.Millisecond),
+		schwabdev.WithReconnectBackoff(10*time.Millisecond, 20*time.Millisecond),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- streamer.Start(ctx, nil) }()
+
+	for conns.Load() < 2 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("want a reconnect after the stale timeout, got %d connections", conns.Load())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Start: want context.Canceled, got %v", err)
+	}
+}
+
+func TestStreamer_MaxReconnectAttempts(t *testing.T) {
+	var conns atomic.Int32
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		conns.Add(1)
+		ackLogin(ctx, c, 3) // reject every login
+	})
+
+	streamer := newTestStreamer(srv,
+		schwabdev.WithReconnectBackoff(time.Millisecond, 2*time.Millisecond),
+		schwabdev.WithMaxReconnectAttempts(2),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := streamer.Start(ctx, nil)
+	if !errors.Is(err, schwabdev.ErrReconnectAttemptsExhausted) {
+		t.Fatalf("Start: want ErrReconnectAttemptsExhausted, got %v", err)
+	}
+	if !errors.Is(err, schwabdev.ErrStreamLoginFailed) {
+		t.Errorf("want the last connection error wrapped, got %v", err)
+	}
+	// The first connection plus two retries.
+	if got := conns.Load(); got != 3 {
+		t.Errorf("want 3 connection attempts, got %d", got)
+	}
+}
+
+func TestStreamer_RunReconnectsAfterDisconnects(t *testing.T) {
+	var conns atomic.Int32
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		n := conns.Add(1)
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		frame := fmt.Sprintf(`{"data":[{"service":"LEVELONE_EQUITIES","timestamp":1715900000000,"command":"SUBS",
+			"content":[{"key":"AAPL","seq":%d}]}]}`, n)
+		c.Write(ctx, websocket.MessageText, []byte(frame))
+		if n <= 2 {
+			c.Close(websocket.StatusGoingAway, "server restart")
+			return
+		}
+		c.Read(ctx)
+	})
+
+	streamer := newTestStreamer(srv, schwabdev.WithReconnectBackoff(time.Millisecond, 2*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := make(chan float64, 3)
+	done := make(chan error, 1)
+	go func() {
+		done <- streamer.Run(ctx, func(ctx context.Context, data schwabdev.StreamData) {
+			seq, _ := data.Content[0]["seq"].(float64)
+			received <- seq
+		})
+	}()
+
+	for want := 1.0; want <= 3; want++ {
+		select {
+		case seq := <-received:
+			if seq != want {
+				t.Fatalf("update from connection %v, want %v", seq, want)
+			}
+		case err := <-done:
+			t.Fatalf("Run returned early: %v", err)
+		case <-ctx.Done():
+			t.Fatalf("no update from connection %v", want)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Run: want context.Canceled, got %v", err)
+	}
+	if got := conns.Load(); got != 3 {
+		t.Errorf("connections = %d, want 3", got)
+	}
+}
+
+func TestStreamer_RunStopsOnLoginFailure(t *testing.T) {
+	var conns atomic.Int32
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		conns.Add(1)
+		ackLogin(ctx, c, 3)
+	})
+
+	streamer := newTestStreamer(srv, schwabdev.WithReconnectBackoff(time.Millisecond, 2*time.Millisecond))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := streamer.Run(ctx, nil)
+	if !errors.Is(err, schwabdev.ErrStreamLoginFailed) || errors.Is(err, schwabdev.ErrReconnectAttemptsExhausted) {
+		t.Fatalf("Run: want ErrStreamLoginFailed without retries, got %v", err)
+	}
+	if got := conns.Load(); got != 1 {
+		t.Errorf("connections = %d, want 1", got)
+	}
+	if got := streamer.State(); got != schwabdev.StateDisconnected {
+		t.Errorf("state = %v, want disconnected", got)
+	}
+}
+
+func TestReconnectManager_Attempts(t *testing.T) {
+	r := schwabdev.NewReconnectManager(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	r.SetMaxAttempts(1)
+	if !r.ShouldReconnect() || r.Attempts() != 0 {
+		t.Fatalf("fresh manager: attempts %d, should reconnect %v", r.Attempts(), r.ShouldReconnect())
+	}
+
+	// The first failure backs off (the default base is 2s) before the cap is
+	// checked again, so cancel during that wait.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := r.ReconnectWithBackoff(ctx, func(context.Context) error { return errors.New("dial failed") })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ReconnectWithBackoff: %v", err)
+	}
+	if r.Attempts() != 1 || r.ShouldReconnect() {
+		t.Errorf("after one backoff: attempts %d, should reconnect %v", r.Attempts(), r.ShouldReconnect())
+	}
+}
+
+// ── VIEW ──────────────────────────────────────────────────────────────────────
+
+func TestStreamer_View(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL", "MSFT"}, []string{"0", "1"}, "SUBS"); err != nil {
+		t.Fatalf("LevelOneEquities: %v", err)
+	}
+	nextFrame(t, frames)
+
+	if err := streamer.View(ctx, "LEVELONE_EQUITIES", []string{"0", "1", "2", "3"}); err != nil {
+		t.Fatalf("View: %v", err)
+	}
+	req := nextFrame(t, frames)
+	if req.Service != "LEVELONE_EQUITIES" || req.Command != "VIEW" {
+		t.Errorf("frame: want LEVELONE_EQUITIES/VIEW, got %s/%s", req.Service, req.Command)
+	}
+	if req.Parameters["fields"] != "0,1,2,3" {
+		t.Errorf("fields: want 0,1,2,3, got %v", req.Parameters["fields"])
+	}
+
+	subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]
+	for _, key := range []string{"AAPL", "MSFT"} {
+		if got := strings.Join(subs[key], ","); got != "0,1,2,3" {
+			t.Errorf("%s recorded fields: want 0,1,2,3, got %s", key, got)
+		}
+	}
+}
+
+// ── Write backpressure ────────────────────────────────────────────────────────
+
+// deafServer acks LOGIN and then never reads again, so the client's socket
+// buffers eventually fill and writes stall. Calling release drops the
+// connection so the client can shut down without waiting on a close
+// handshake.
+func deafServer(t *testing.T) (srv *httptest.Server, release func()) {
+	t.Helper()
+	done := make(chan struct{})
+	srv = mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		<-done
+	})
+	release = sync.OnceFunc(func() { close(done) })
+	t.Cleanup(release)
+	return srv, release
+}
+
+func TestStreamer_WriteBufferFull(t *testing.T) {
+	srv, release := deafServer(t)
+	defer release()
+	streamer := newTestStreamer(srv, schwabdev.WithWriteBuffer(2), schwabdev.WithWriteTimeout(time.Minute))
+	ctx := connectStreamer(t, streamer)
+
+	// Large frames fill the kernel socket buffers quickly; once the writer is
+	// stuck the queue fills and sends must fail rather than block.
+	keys := []string{strings.Repeat("K", 64<<10)}
+	for range 2000 {
+		err := streamer.LevelOneEquities(ctx, keys, []string{"0"}, "SUBS")
+		if errors.Is(err, schwabdev.ErrStreamWriteBufferFull) {
+			return
+		}
+		if err != nil {
+			t.Fatalf("LevelOneEquities: %v", err)
+		}
+	}
+	t.Fatal("want ErrStreamWriteBufferFull once the writer stalls")
+}
+
+func TestStreamer_WriteTimeoutClosesConnection(t *testing.T) {
+	srv, _ := deafServer(t)
+	streamer := newTestStreamer(srv, schwabdev.WithWriteTimeout(100*time.Millisecond))
+	ctx := connectStreamer(t, streamer)
+
+	keys := []string{strings.Repeat("K", 64<<10)}
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		err := streamer.LevelOneEquities(ctx, keys, []string{"0"}, "SUBS")
+		if err != nil && strings.Contains(err.Error(), "not connected") {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("want the connection closed after a write exceeded the timeout")
+}
+
+// ── Subscription cleanup ──────────────────────────────────────────────────────
+
+func TestStreamer_AddMergesFields(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL"}, []string{"0", "1"}, "SUBS"); err != nil {
+		t.Fatalf("SUBS: %v", err)
+	}
+	nextFrame(t, frames)
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL", "MSFT"}, []string{"1", "2"}, "ADD"); err != nil {
+		t.Fatalf("ADD: %v", err)
+	}
+	nextFrame(t, frames)
+
+	subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]
+	if got := subs["AAPL"]; !slices.Equal(got, []string{"0", "1", "2"}) {
+		t.Errorf("AAPL fields = %v, want [0 1 2]", got)
+	}
+	if got := subs["MSFT"]; !slices.Equal(got, []string{"1", "2"}) {
+		t.Errorf("MSFT fields = %v, want [1 2]", got)
+	}
+}
+
+func TestStreamer_SubsReplacesSubscriptions(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL", "MSFT"}, []string{"0", "1", "2"}, "SUBS"); err != nil {
+		t.Fatalf("SUBS: %v", err)
+	}
+	nextFrame(t, frames)
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL"}, []string{"3"}, "SUBS"); err != nil {
+		t.Fatalf("SUBS: %v", err)
+	}
+	nextFrame(t, frames)
+
+	subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]
+	if len(subs) != 1 || !slices.Equal(subs["AAPL"], []string{"3"}) {
+		t.Errorf("want only AAPL with fields [3], got %v", subs)
+	}
+}
+
+func TestStreamer_PartialUnsubsKeepsOtherKeys(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL", "MSFT", "IBM"}, []string{"0", "1"}, "SUBS"); err != nil {
+		t.Fatalf("SUBS: %v", err)
+	}
+	nextFrame(t, frames)
+	if err := streamer.LevelOneEquities(ctx, []string{"IBM"}, []string{"2"}, "ADD"); err != nil {
+		t.Fatalf("ADD: %v", err)
+	}
+	nextFrame(t, frames)
+	if err := streamer.LevelOneEquities(ctx, []string{"MSFT"}, nil, "UNSUBS"); err != nil {
+		t.Fatalf("UNSUBS: %v", err)
+	}
+	if req := nextFrame(t, frames); req.Command != "UNSUBS" || req.Parameters["keys"] != "MSFT" {
+		t.Errorf("unexpected frame: %+v", req)
+	}
+
+	subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]
+	if len(subs) != 2 || !slices.Equal(subs["AAPL"], []string{"0", "1"}) || !slices.Equal(subs["IBM"], []string{"0", "1", "2"}) {
+		t.Errorf("want AAPL [0 1] and IBM [0 1 2], got %v", subs)
+	}
+
+	// An unknown key fails the whole request: nothing is removed or sent.
+	err := streamer.LevelOneEquities(ctx, []string{"AAPL", "MSFT"}, nil, "UNSUBS")
+	if !errors.Is(err, schwabdev.ErrNotSubscribed) || !strings.Contains(err.Error(), `"MSFT"`) {
+		t.Errorf("want ErrNotSubscribed naming MSFT, got %v", err)
+	}
+	batch := []*schwabdev.Subscription{{Service: "LEVELONE_EQUITIES", Command: "UNSUBS", Keys: []string{"TSLA"}}}
+	if err := streamer.SendBatch(ctx, batch); !errors.Is(err, schwabdev.ErrNotSubscribed) {
+		t.Errorf("SendBatch: want ErrNotSubscribed, got %v", err)
+	}
+	if subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]; len(subs) != 2 {
+		t.Errorf("rejected UNSUBS changed subscriptions: %v", subs)
+	}
+
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL", "IBM"}, nil, "UNSUBS"); err != nil {
+		t.Fatalf("UNSUBS: %v", err)
+	}
+	if req := nextFrame(t, frames); req.Command != "UNSUBS" || req.Parameters["keys"] != "AAPL,IBM" {
+		t.Errorf("unexpected frame: %+v", req)
+	}
+	if _, ok := streamer.Subscriptions()["LEVELONE_EQUITIES"]; ok {
+		t.Error("service should be forgotten once its last key is unsubscribed")
+	}
+}
+
+func TestStreamer_Snapshot(t *testing.T) {
+	streamer := newTestStreamer(nil)
+	// Subscribing while disconnected still records the keys.
+	ctx := context.Background()
+	streamer.LevelOneEquities(ctx, []string{"MSFT", "AAPL"}, []string{"0", "1"}, "SUBS")
+	streamer.LevelOneEquities(ctx, []string{"IBM"}, []string{"0"}, "ADD")
+	streamer.ChartEquity(ctx, []string{"SPY"}, []string{"0", "1", "2"}, "SUBS")
+
+	describe := func(subs []*schwabdev.Subscription) []string {
+		var out []string
+		for _, s := range subs {
+			out = append(out, fmt.Sprintf("%s %s %v %v", s.Service, s.Command, s.Keys, s.Fields))
+		}
+		return out
+	}
+	want := []string{
+		"CHART_EQUITY ADD [SPY] [0 1 2]",
+		"LEVELONE_EQUITIES ADD [IBM] [0]",
+		"LEVELONE_EQUITIES ADD [AAPL MSFT] [0 1]",
+	}
+	snap := streamer.Snapshot()
+	if got := describe(snap); !slices.Equal(got, want) {
+		t.Fatalf("Snapshot:\n got %v\nwant %v", got, want)
+	}
+
+	// Later changes to the Streamer do not show through the snapshot...
+	streamer.RemoveSubscription("LEVELONE_EQUITIES", "AAPL")
+	streamer.LevelOneEquities(ctx, []string{"IBM"}, []string{"2"}, "ADD")
+	if got := describe(snap); !slices.Equal(got, want) {
+		t.Errorf("snapshot changed with the Streamer:\n got %v\nwant %v", got, want)
+	}
+
+	// ...and changes to the snapshot do not reach the Streamer.
+	snap[1].Keys[0] = "TSLA"
+	snap[1].Fields[0] = "9"
+	subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]
+	if _, ok := subs["TSLA"]; ok || !slices.Equal(subs["IBM"], []string{"0", "2"}) {
+		t.Errorf("Streamer changed with the snapshot: %v", subs)
+	}
+}
+
+func TestStreamer_RemoveSubscription(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL", "MSFT"}, []string{"0"}, "SUBS"); err != nil {
+		t.Fatalf("LevelOneEquities: %v", err)
+	}
+	nextFrame(t, frames)
+
+	streamer.RemoveSubscription("levelone_equities", "AAPL")
+	subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]
+	if _, ok := subs["AAPL"]; ok || len(subs) != 1 {
+		t.Errorf("want only MSFT recorded, got %v", subs)
+	}
+
+	streamer.RemoveSubscription("LEVELONE_EQUITIES", "MSFT")
+	if subs := streamer.Subscriptions(); len(subs) != 0 {
+		t.Errorf("want no subscriptions, got %v", subs)
+	}
+
+	select {
+	case req := <-frames:
+		t.Errorf("RemoveSubscription sent a frame: %+v", req)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStreamer_UnsubscribeAll(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	if err := streamer.LevelOneEquities(ctx, []string{"MSFT", "AAPL"}, []string{"0"}, "SUBS"); err != nil {
+		t.Fatalf("LevelOneEquities: %v", err)
+	}
+	if err := streamer.ChartEquity(ctx, []string{"SPY"}, []string{"0"}, "SUBS"); err != nil {
+		t.Fatalf("ChartEquity: %v", err)
+	}
+	nextFrame(t, frames)
+	nextFrame(t, frames)
+
+	if err := streamer.UnsubscribeAll(ctx); err != nil {
+		t.Fatalf("UnsubscribeAll: %v", err)
+	}
+
+	got := map[string]string{}
+	for range 2 {
+		req := nextFrame(t, frames)
+		if req.Command != "UNSUBS" {
+			t.Errorf("%s: want UNSUBS, got %s", req.Service, req.Command)
+		}
+		got[req.Service], _ = req.Parameters["keys"].(string)
+	}
+	if got["LEVELONE_EQUITIES"] != "AAPL,MSFT" || got["CHART_EQUITY"] != "SPY" {
+		t.Errorf("UNSUBS keys: %v", got)
+	}
+	if subs := streamer.Subscriptions(); len(subs) != 0 {
+		t.Errorf("want no subscriptions, got %v", subs)
+	}
+}
+
+func TestStreamer_UnsubscribeAllNotConnected(t *testing.T) {
+	streamer := newTestStreamer(nil)
+	// Subscribing while disconnected still records the keys for replay.
+	streamer.LevelOneEquities(context.Background(), []string{"AAPL"}, []string{"0"}, "SUBS")
+
+	if err := streamer.UnsubscribeAll(context.Background()); err == nil {
+		t.Fatal("want error when not connected")
+	}
+	if subs := streamer.Subscriptions()["LEVELONE_EQUITIES"]; len(subs) != 1 {
+		t.Errorf("failed unsubscribe should keep the record, got %v", subs)
+	}
+}
+
+func TestStreamer_ConcurrentSubscribeUnsubscribe(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv, schwabdev.WithWriteBuffer(256))
+	ctx := connectStreamer(t, streamer)
+
+	keys := []string{"AAPL", "MSFT", "NVDA", "AMZN", "TSLA"}
+	var sent atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			key := keys[i%len(keys)]
+			fields := []string{"0", fmt.Sprint(i % 3)}
+			if err := streamer.Subscribe(ctx, "LEVELONE_EQUITIES", []string{key}, fields, "ADD"); err != nil {
+				t.Errorf("ADD %s: %v", key, err)
+				return
+			}
+			sent.Add(1)
+			streamer.Subscriptions()
+			if i%2 == 0 {
+				err := streamer.Subscribe(ctx, "LEVELONE_EQUITIES", []string{key}, nil, "UNSUBS")
+				switch {
+				case err == nil:
+					sent.Add(1)
+				case !errors.Is(err, schwabdev.ErrNotSubscribed):
+					t.Errorf("UNSUBS %s: %v", key, err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	// Apply the requests in the order the server received them; the
+	// recorded subscriptions must match the resulting server-side state.
+	server := make(map[string][]string)
+	for range sent.Load() {
+		req := nextFrame(t, frames)
+		key, _ := req.Parameters["keys"].(string)
+		switch req.Command {
+		case "ADD":
+			fields, _ := req.Parameters["fields"].(string)
+			for f := range strings.SplitSeq(fields, ",") {
+				if !slices.Contains(server[key], f) {
+					server[key] = append(server[key], f)
+				}
+			}
+		case "UNSUBS":
+			delete(server, key)
+		}
+	}
+	got := streamer.Subscriptions()["LEVELONE_EQUITIES"]
+	if len(got) != len(server) {
+		t.Fatalf("recorded %v, server has %v", got, server)
+	}
+	for key, fields := range server {
+		if !slices.Equal(got[key], fields) {
+			t.Errorf("%s: recorded fields %v, server has %v", key, got[key], fields)
+		}
+	}
+}
+
+// ── Close ─────────────────────────────────────────────────────────────────────
+
+func TestStreamer_Close(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	served := make(chan error, 1)
+	go func() { served <- streamer.Start(ctx, nil) }()
+
+	// Wait for the connection, then queue a request ahead of the LOGOUT.
+	deadline := time.Now().Add(2 * time.Second)
+	for streamer.LevelOneEquities(ctx, []string{"AAPL"}, []string{"0"}, "SUBS") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("streamer never connected")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := streamer.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	req := nextFrame(t, frames)
+	if req.Command == "ADD" {
+		// Attempts made before the connection was up are replayed on login.
+		req = nextFrame(t, frames)
+	}
+	if req.Command != "SUBS" {
+		t.Errorf("want pending SUBS flushed first, got %s/%s", req.Service, req.Command)
+	}
+	if req := nextFrame(t, frames); req.Service != "ADMIN" || req.Command != "LOGOUT" {
+		t.Errorf("want ADMIN/LOGOUT, got %s/%s", req.Service, req.Command)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Errorf("Start after Close: want nil, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Start kept running after Close")
+	}
+
+	if err := streamer.Close(ctx); err != nil {
+		t.Errorf("second Close: want nil, got %v", err)
+	}
+	if err := streamer.LevelOneEquities(ctx, []string{"MSFT"}, []string{"0"}, "SUBS"); err == nil {
+		t.Error("want error sending after Close")
+	}
+}
+
+func TestStreamer_ConnectContextCancelStopsLoops(t *testing.T) {
+	srv, _ := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	select {
+	case <-streamer.Done():
+	default:
+		t.Fatal("Done before connecting should be closed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := streamer.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	done := streamer.Done()
+	select {
+	case <-done:
+		t.Fatal("loops exited while connected")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("loops still running after the context was cancelled")
+	}
+}
+
+func TestStreamer_StopStopsLoops(t *testing.T) {
+	srv, _ := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	connectStreamer(t, streamer)
+
+	done := streamer.Done()
+	streamer.Stop()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("loops still running after Stop")
+	}
+}
+
+// ── Batching ──────────────────────────────────────────────────────────────────
+
+func TestStreamer_SendBatchWritesOneFrame(t *testing.T) {
+	messages := make(chan []byte, 8)
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		for {
+			_, msg, err := c.Read(ctx)
+			if err != nil {
+				return
+			}
+			messages <- msg
+		}
+	})
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	err := streamer.SendBatch(ctx, []*schwabdev.Subscription{
+		{Service: "LEVELONE_EQUITIES", Command: "SUBS", Keys: []string{"AAPL", "MSFT"}, Fields: []string{"0", "1"}},
+		{Service: "chart_equity", Keys: []string{"SPY"}, Fields: []string{"0"}},
+		{Service: "LEVELONE_FUTURES", Command: "ADD", Keys: []string{"/ES"}, Fields: []string{"0"}},
+	})
+	if err != nil {
+		t.Fatalf("SendBatch: %v", err)
+	}
+
+	var frame struct {
+		Requests []streamRequest `json:"requests"`
+	}
+	select {
+	case msg := <-messages:
+		if err := json.Unmarshal(msg, &frame); err != nil {
+			t.Fatalf("decode frame: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no frame received")
+	}
+	if len(frame.Requests) != 3 {
+		t.Fatalf("want 3 requests in one frame, got %d", len(frame.Requests))
+	}
+	if r := frame.Requests[1]; r.Service != "CHART_EQUITY" || r.Command != "ADD" || r.Parameters["keys"] != "SPY" {
+		t.Errorf("second request: %+v", r)
+	}
+	for i := 1; i < len(frame.Requests); i++ {
+		if frame.Requests[i].RequestID <= frame.Requests[i-1].RequestID {
+			t.Errorf("request IDs not increasing: %d then %d", frame.Requests[i-1].RequestID, frame.Requests[i].RequestID)
+		}
+	}
+	select {
+	case msg := <-messages:
+		t.Errorf("want exactly one frame, got another: %s", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	subs := streamer.Subscriptions()
+	if len(subs["LEVELONE_EQUITIES"]) != 2 || len(subs["CHART_EQUITY"]) != 1 || len(subs["LEVELONE_FUTURES"]) != 1 {
+		t.Errorf("batch not recorded: %v", subs)
+	}
+}
+
+func TestStreamer_SendBatchRejectsEmptyKeys(t *testing.T) {
+	streamer := newTestStreamer(nil)
+	err := streamer.SendBatch(context.Background(), []*schwabdev.Subscription{
+		{Service: "LEVELONE_EQUITIES", Keys: []string{"AAPL"}},
+		{Service: "CHART_EQUITY"},
+	})
+	if err == nil {
+		t.Fatal("want error for subscription without keys")
+	}
+	if subs := streamer.Subscriptions(); len(subs) != 0 {
+		t.Errorf("nothing should be recorded, got %v", subs)
+	}
+}
+
+// ── Request tracking ──────────────────────────────────────────────────────────
+
+func TestStreamer_RequestIDsTracked(t *testing.T) {
+	srv, frames := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	ctx := connectStreamer(t, streamer)
+
+	if pending := streamer.PendingRequests(); len(pending) != 0 {
+		t.Fatalf("LOGIN still pending after ack: %+v", pending)
+	}
+
+	for _, key := range []string{"AAPL", "MSFT", "SPY"} {
+		if err := streamer.LevelOneEquities(ctx, []string{key}, []string{"0"}, "ADD"); err != nil {
+			t.Fatalf("LevelOneEquities(%s): %v", key, err)
+		}
+	}
+	// LOGIN took request ID 1.
+	for i, want := range []int64{2, 3, 4} {
+		if req := nextFrame(t, frames); req.RequestID != want {
+			t.Errorf("subscription %d: requestid = %d, want %d", i, req.RequestID, want)
+		}
+	}
+
+	pending := streamer.PendingRequests()
+	if len(pending) != 3 || pending[0].ID != 2 || pending[2].ID != 4 {
+		t.Fatalf("PendingRequests = %+v", pending)
+	}
+	sent, ok := streamer.PendingRequest(3)
+	if !ok || sent.Service != "LEVELONE_EQUITIES" || sent.Command != "ADD" || !slices.Equal(sent.Keys, []string{"MSFT"}) {
+		t.Errorf("PendingRequest(3) = %+v, %v", sent, ok)
+	}
+
+	var matched *schwabdev.SentRequest
+	streamer.OnResponse(func(_ context.Context, _ schwabdev.StreamResponse, req *schwabdev.SentRequest) {
+		matched = req
+	})
+	ack := `{"response":[{"service":"LEVELONE_EQUITIES","command":"ADD","requestid":"3","content":{"code":0,"msg":"ADD command succeeded"}}]}`
+	if err := streamer.RouteMessage(context.Background(), []byte(ack)); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	if matched == nil || matched.ID != 3 {
+		t.Fatalf("response matched %+v, want request 3", matched)
+	}
+	if _, ok := streamer.PendingRequest(3); ok {
+		t.Error("request 3 still pending after its response")
+	}
+
+	unknown := `{"response":[{"service":"ADMIN","command":"QOS","requestid":"99","content":{"code":0}}]}`
+	if err := streamer.RouteMessage(context.Background(), []byte(unknown)); err != nil {
+		t.Fatalf("RouteMessage: %v", err)
+	}
+	if matched != nil {
+		t.Errorf("unknown request ID matched %+v", matched)
+	}
+}
+
+// ── Connection options ────────────────────────────────────────────────────────
+
+func TestStreamer_WithDialerAndStreamerURL(t *testing.T) {
+	srv, frames := recordingServer(t)
+
+	var dials atomic.Int32
+	dialer := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dials.Add(1)
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	}
+	// The info source reports no socket URL; WithStreamerURL supplies it.
+	streamer := newTestStreamer(nil,
+		schwabdev.WithStreamerURL("ws"+strings.TrimPrefix(srv.URL, "http")),
+		schwabdev.WithDialer(dialer),
+	)
+	ctx := connectStreamer(t, streamer)
+
+	if got := dials.Load(); got != 1 {
+		t.Errorf("custom dialer used %d times, want 1", got)
+	}
+	if err := streamer.LevelOneEquities(ctx, []string{"AAPL"}, []string{"0"}, "SUBS"); err != nil {
+		t.Fatalf("LevelOneEquities: %v", err)
+	}
+	if req := nextFrame(t, frames); req.Service != "LEVELONE_EQUITIES" {
+		t.Errorf("unexpected frame: %+v", req)
+	}
+}
+
+func TestStreamer_WithTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			t.Errorf("accept: %v", err)
+			return
+		}
+		defer c.CloseNow()
+		if _, err := ackLogin(r.Context(), c, 0); err != nil {
+			return
+		}
+		for {
+			if _, _, err := c.Read(r.Context()); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	wsURL := "wss" + strings.TrimPrefix(srv.URL, "https")
+
+	// Without the test CA the certificate is rejected.
+	untrusted := newTestStreamer(nil, schwabdev.WithStreamerURL(wsURL))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := untrusted.Connect(ctx, nil); err == nil {
+		untrusted.Stop()
+		t.Fatal("want certificate error without the TLS config")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	streamer := newTestStreamer(nil,
+		schwabdev.WithStreamerURL(wsURL),
+		schwabdev.WithTLSConfig(&tls.Config{RootCAs: roots}),
+	)
+	connectStreamer(t, streamer)
+}
+
+// rotatingTokens hands out tokens in order, repeating the last one.
+type rotatingTokens struct {
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (r *rotatingTokens) AccessToken() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token := r.tokens[0]
+	if len(r.tokens) > 1 {
+		r.tokens = r.tokens[1:]
+	}
+	return token, nil
+}
+
+func TestStreamer_ReconnectFetchesFreshCredentials(t *testing.T) {
+	frames := make(chan streamRequest, 16)
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		var login streamRequest
+		if err := wsjson.Read(ctx, c, &login); err != nil {
+			return
+		}
+		code := 0
+		if login.Parameters["Authorization"] != "fresh-token" {
+			code = 3
+		}
+		wsjson.Write(ctx, c, map[string]any{
+			"response": []map[string]any{{
+				"service": "ADMIN", "command": "LOGIN", "requestid": "1",
+				"content": map[string]any{"code": code, "msg": "status"},
+			}},
+		})
+		if code != 0 {
+			return
+		}
+		for {
+			var req streamRequest
+			if err := wsjson.Read(ctx, c, &req); err != nil {
+				return
+			}
+			frames <- req
+		}
+	})
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	var fetches atomic.Int32
+	infoFunc := func(ctx context.Context) (*schwabdev.StreamerInfo, error) {
+		n := fetches.Add(1)
+		return &schwabdev.StreamerInfo{
+			StreamerURL:            wsURL,
+			SchwabClientCustomerID: fmt.Sprintf("customer-%d", n),
+			SchwabClientCorrelID:   "correl",
+			SchwabClientChannel:    "N9",
+			SchwabClientFunctionID: "APIAPP",
+		}, nil
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	tokens := &rotatingTokens{tokens: []string{"stale-token", "fresh-token"}}
+	unused := func() (map[string]any, error) { return nil, errors.New("InfoSource should not be used") }
+	streamer := schwabdev.NewStreamer(logger, tokens, unused,
+		schwabdev.WithStreamerInfoFunc(infoFunc),
+		schwabdev.WithReconnectBackoff(10*time.Millisecond, 20*time.Millisecond),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Recorded while disconnected, replayed once logged in.
+	streamer.LevelOneEquities(ctx, []string{"AAPL"}, []string{"0"}, "SUBS")
+
+	served := make(chan error, 1)
+	go func() { served <- streamer.Start(ctx, nil) }()
+
+	req := nextFrame(t, frames)
+	if req.Service != "LEVELONE_EQUITIES" || req.Parameters["keys"] != "AAPL" {
+		t.Errorf("want replayed AAPL subscription, got %+v", req)
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("streamer info fetched %d times, want 2", n)
+	}
+	streamer.Stop()
+	cancel()
+	<-served
+}
+
+// ── Resubscribe on reconnect ──────────────────────────────────────────────────
+
+func TestStreamer_SubscribeReplayedAfterReconnect(t *testing.T) {
+	type frame struct {
+		conn int32
+		req  streamRequest
+	}
+	var conns atomic.Int32
+	frames := make(chan frame, 16)
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		n := conns.Add(1)
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		for received := 0; ; received++ {
+			if n == 1 && received == 2 {
+				return // drop the first connection after two requests
+			}
+			var req streamRequest
+			if err := wsjson.Read(ctx, c, &req); err != nil {
+				return
+			}
+			frames <- frame{n, req}
+		}
+	})
+	streamer := newTestStreamer(srv, schwabdev.WithReconnectBackoff(10*time.Millisecond, 20*time.Millisecond))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// Registered while disconnected: sent on the first connect.
+	if err := streamer.Subscribe(ctx, "chart_equity", []string{"SPY"}, []string{"0", "1"}, "SUBS"); err != nil {
+		t.Fatalf("Subscribe before connect: %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- streamer.Start(ctx, nil) }()
+	defer func() {
+		streamer.Stop()
+		cancel()
+		<-served
+	}()
+
+	next := func() frame {
+		t.Helper()
+		select {
+		case f := <-frames:
+			return f
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for a frame")
+			return frame{}
+		}
+	}
+	describe := func(f frame) string {
+		return fmt.Sprintf("conn %d %s %s %v", f.conn, f.req.Service, f.req.Parameters["keys"], f.req.Parameters["fields"])
+	}
+
+	if got, want := describe(next()), "conn 1 CHART_EQUITY SPY 0,1"; got != want {
+		t.Errorf("first connect: got %q, want %q", got, want)
+	}
+	for streamer.State() != schwabdev.StateConnected {
+		time.Sleep(time.Millisecond)
+	}
+	if err := streamer.Subscribe(ctx, "LEVELONE_EQUITIES", []string{"AAPL", "MSFT"}, []string{"0", "3"}, "SUBS"); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if got, want := describe(next()), "conn 1 LEVELONE_EQUITIES AAPL,MSFT 0,3"; got != want {
+		t.Errorf("live subscribe: got %q, want %q", got, want)
+	}
+
+	// The server drops the connection; Start reconnects and replays both
+	// subscriptions without Subscribe being called again.
+	got := []string{describe(next()), describe(next())}
+	want := []string{"conn 2 CHART_EQUITY SPY 0,1", "conn 2 LEVELONE_EQUITIES AAPL,MSFT 0,3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("after reconnect:\n got %q\nwant %q", got, want)
+	}
+}
+
+// ── Connection state ──────────────────────────────────────────────────────────
+
+// stateRecorder collects the transitions reported by OnStateChange.
+type stateRecorder struct {
+	mu     sync.Mutex
+	states []schwabdev.ConnectionState
+}
+
+func (r *stateRecorder) record(_, new schwabdev.ConnectionState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.states = append(r.states, new)
+}
+
+func (r *stateRecorder) get() []schwabdev.ConnectionState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.states)
+}
+
+func TestStreamer_StateConnectAndClose(t *testing.T) {
+	srv, _ := recordingServer(t)
+	streamer := newTestStreamer(srv)
+	var rec stateRecorder
+	streamer.OnStateChange(rec.record)
+
+	if got := streamer.State(); got != schwabdev.StateDisconnected {
+		t.Fatalf("initial state = %v", got)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := streamer.Connect(ctx, nil); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if got := streamer.State(); got != schwabdev.StateConnected {
+		t.Errorf("state after Connect = %v", got)
+	}
+	if err := streamer.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	want := []schwabdev.ConnectionState{
+		schwabdev.StateConnecting, schwabdev.StateAuthenticating,
+		schwabdev.StateConnected, schwabdev.StateDisconnected,
+	}
+	if got := rec.get(); !slices.Equal(got, want) {
+		t.Errorf("transitions = %v, want %v", got, want)
+	}
+}
+
+func TestStreamer_StateReconnecting(t *testing.T) {
+	var conns atomic.Int32
+	srv := mockStreamServer(t, func(ctx context.Context, c *websocket.Conn) {
+		if conns.Add(1) == 1 {
+			// Reject the first login so Start has to retry.
+			ackLogin(ctx, c, 3)
+			return
+		}
+		if _, err := ackLogin(ctx, c, 0); err != nil {
+			return
+		}
+		// Keep reading so the close handshake completes.
+		for {
+			if _, _, err := c.Read(ctx); err != nil {
+				return
+			}
+		}
+	})
+	streamer := newTestStreamer(srv, schwabdev.WithReconnectBackoff(10*time.Millisecond, 20*time.Millisecond))
+	var rec stateRecorder
+	streamer.OnStateChange(rec.record)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	served := make(chan error, 1)
+	go func() { served <- streamer.Start(ctx, nil) }()
+
+	for streamer.State() != schwabdev.StateConnected {
+		if ctx.Err() != nil {
+			t.Fatalf("never connected; transitions %v", rec.get())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := streamer.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := <-served; err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	want := []schwabdev.ConnectionState{
+		schwabdev.StateConnecting, schwabdev.StateAuthenticating, schwabdev.StateReconnecting,
+		schwabdev.StateAuthenticating, schwabdev.StateConnected, schwabdev.StateDisconnected,
+	}
+	if got := rec.get(); !slices.Equal(got, want) {
+		t.Errorf("transitions = %v, want %v", got, want)
+	}
+	if s := schwabdev.StateReconnecting.String(); s != "reconnecting" {
+		t.Errorf("String() = %q", s)
+	}
+}
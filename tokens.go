@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -35,6 +36,9 @@ type TokenManager struct {
 	// accesses the underlying medium directly — all I/O goes through here.
 	storage TokenStorage
 
+	// tokenURL is the OAuth token endpoint. Defaults to OAuthTokenURL.
+	tokenURL string
+
 	// mu guards the in-memory token fields below.
 	mu sync.RWMutex
 
@@ -46,10 +50,13 @@ type TokenManager struct {
 	refreshTokenIssued  time.Time
 	accessTokenTimeout  time.Duration
 	refreshTokenTimeout time.Duration
+
+	stats TokenManagerStats
 }
 
 // NewTokenManager creates a TokenManager using a caller-supplied TokenStorage.
 // Use NewTokenManagerWithFilePath for the common case of file-based persistence.
+// A nil logger discards all output.
 func NewTokenManager(
 	appKey, appSecret, callbackURL string,
 	storage TokenStorage,
@@ -66,8 +73,9 @@ func NewTokenManager(
 		appSecret:           appSecret,
 		callbackURL:         callbackURL,
 		storage:             storage,
-		logger:              logger,
+		logger:              loggerOrDiscard(logger),
 		callOnAuth:          callOnAuth,
+		tokenURL:            OAuthTokenURL,
 		accessTokenTimeout:  AccessTokenValidity,
 		refreshTokenTimeout: RefreshTokenValidity,
 	}
@@ -102,6 +110,15 @@ func NewTokenManagerWithFilePath(
 	return NewTokenManager(appKey, appSecret, callbackURL, storage, encryption, logger, callOnAuth)
 }
 
+// SetTokenURL overrides the OAuth token endpoint used for the
+// authorization-code and refresh-token grants. Intended for tests and
+// proxies; the default is OAuthTokenURL.
+func (tm *TokenManager) SetTokenURL(tokenURL string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.tokenURL = tokenURL
+}
+
 // Close releases resources held by the storage backend.
 func (tm *TokenManager) Close() error {
 	return tm.storage.Close()
@@ -147,6 +164,21 @@ func (tm *TokenManager) TokenInfo() TokenInfo {
 	}
 }
 
+// TokenManagerStats counts the refreshes a TokenManager has performed since it
+// was created.
+type TokenManagerStats struct {
+	AccessTokenRefreshes  int // successful refresh-token grants
+	RefreshTokenRefreshes int // successful re-authorisations (authorization-code grants)
+	RefreshFailures       int // failed attempts of either kind
+}
+
+// Stats returns a snapshot of the refresh counters.
+func (tm *TokenManager) Stats() TokenManagerStats {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return tm.stats
+}
+
 // UpdateTokens checks expiry and refreshes tokens as needed.
 // Returns true if any refresh was performed.
 func (tm *TokenManager) UpdateTokens(forceAccessToken, forceRefreshToken bool) (bool, error) {
@@ -209,6 +241,10 @@ func (tm *TokenManager) loadFromStorage() error {
 	if err != nil {
 		return fmt.Errorf("decrypt refresh token: %w", err)
 	}
+	decryptedIT, err := Decrypt(rec.IDToken, tm.encryptionKey)
+	if err != nil {
+		return fmt.Errorf("decrypt id token: %w", err)
+	}
 
 	timeout := AccessTokenValidity
 	if rec.ExpiresIn > 0 {
@@ -220,7 +256,7 @@ func (tm *TokenManager) loadFromStorage() error {
 	tm.refreshTokenIssued = rec.RefreshTokenIssued.UTC()
 	tm.accessToken = decryptedAT
 	tm.refreshToken = decryptedRT
-	tm.idToken = rec.IDToken
+	tm.idToken = decryptedIT
 	tm.accessTokenTimeout = timeout
 	tm.mu.Unlock()
 
@@ -252,6 +288,14 @@ func (tm *TokenManager) saveTokens(atIssued, rtIssued time.Time, tokenDict map[s
 	at, rt, it := tm.accessToken, tm.refreshToken, tm.idToken
 	tm.mu.Unlock()
 
+	tokenType, scope := "Bearer", "api"
+	if val, ok := tokenDict["token_type"].(string); ok && val != "" {
+		tokenType = val
+	}
+	if val, ok := tokenDict["scope"].(string); ok && val != "" {
+		scope = val
+	}
+
 	// Encrypt outside the lock (CPU-bound, not shared state).
 	encAT, err := Encrypt(at, tm.encryptionKey)
 	if err != nil {
@@ -261,40 +305,70 @@ func (tm *TokenManager) saveTokens(atIssued, rtIssued time.Time, tokenDict map[s
 	if err != nil {
 		return fmt.Errorf("encrypt refresh token: %w", err)
 	}
+	encIT, err := Encrypt(it, tm.encryptionKey)
+	if err != nil {
+		return fmt.Errorf("encrypt id token: %w", err)
+	}
 
 	return tm.storage.Save(context.Background(), TokenRecord{
 		AccessTokenIssued:  atIssued.UTC(),
 		RefreshTokenIssued: rtIssued.UTC(),
 		AccessToken:        encAT,
 		RefreshToken:       encRT,
-		IDToken:            it,
+		IDToken:            encIT,
 		ExpiresIn:          expiresIn,
-		TokenType:          "Bearer",
-		Scope:              "api",
+		TokenType:          tokenType,
+		Scope:              scope,
 	})
 }
 
 // ── Token refresh ─────────────────────────────────────────────────────────────
 
-func (tm *TokenManager) updateAccessToken() error {
+func (tm *TokenManager) updateAccessToken() (err error) {
+	defer func() { tm.countRefresh(&tm.stats.AccessTokenRefreshes, err) }()
+
 	tm.mu.RLock()
 	rt := tm.refreshToken
 	rtIssued := tm.refreshTokenIssued
 	tm.mu.RUnlock()
 
-	response, err := tm.postOAuthToken("refresh_token", rt)
+	response, err := tm.postOAuthToken(context.Background(), "refresh_token", rt)
 	if err != nil {
 		return err
 	}
 	return tm.saveTokens(time.Now().UTC(), rtIssued, response)
 }
 
-func (tm *TokenManager) updateRefreshToken() error {
+func (tm *TokenManager) updateRefreshToken() (err error) {
+	defer func() { tm.countRefresh(&tm.stats.RefreshTokenRefreshes, err) }()
+
 	authCode, err := tm.getNewTokens()
 	if err != nil {
 		return err
 	}
-	response, err := tm.postOAuthToken("authorization_code", authCode)
+	return tm.InitializeFromCode(context.Background(), authCode)
+}
+
+// countRefresh increments counter on success or RefreshFailures otherwise.
+func (tm *TokenManager) countRefresh(counter *int, err error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if err != nil {
+		tm.stats.RefreshFailures++
+		return
+	}
+	*counter++
+}
+
+// InitializeFromCode exchanges an authorization code (the "code" query
+// parameter Schwab appends to the callback URL) for a new access/refresh
+// token pair and persists both with the current time as their issue time.
+// Use it for first-time setup when the code is obtained outside callOnAuth.
+func (tm *TokenManager) InitializeFromCode(ctx context.Context, code string) error {
+	if code == "" {
+		return ErrAuthCodeRequired
+	}
+	response, err := tm.postOAuthToken(ctx, "authorization_code", code)
 	if err != nil {
 		return err
 	}
@@ -302,12 +376,69 @@ func (tm *TokenManager) updateRefreshToken() error {
 	return tm.saveTokens(now, now, response)
 }
 
+// ParseCallbackCode extracts the authorization code from the URL Schwab
+// redirected the browser to after the consent screen
+// (callbackURL?code=...&session=...), ready for InitializeFromCode. It returns
+// ErrAuthorizationDenied if the user declined access, and ErrAuthCodeRequired
+// if the URL carries no code.
+func ParseCallbackCode(redirectedURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(redirectedURL))
+	if err != nil {
+		return "", fmt.Errorf("parse callback URL: %w", err)
+	}
+	query := parsed.Query()
+	if code := query.Get("error"); code != "" {
+		desc := query.Get("error_description")
+		if desc == "" {
+			desc = code
+		}
+		if code == "access_denied" {
+			return "", fmt.Errorf("%w: %s", ErrAuthorizationDenied, desc)
+		}
+		return "", fmt.Errorf("authorization failed: %s", desc)
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", ErrAuthCodeRequired
+	}
+	return code, nil
+}
+
+// ApplyToken installs a token obtained outside the TokenManager, such as from
+// a grant made by another process, and persists it. The access token is
+// treated as issued now, as is the refresh token if tok carries one; an empty
+// refresh or ID token keeps the current value.
+func (tm *TokenManager) ApplyToken(tok *Token) error {
+	if tok == nil || tok.AccessToken == "" {
+		return ErrAccessTokenRequired
+	}
+	now := time.Now().UTC()
+	tm.mu.RLock()
+	rtIssued := tm.refreshTokenIssued
+	tm.mu.RUnlock()
+	if tok.RefreshToken != "" {
+		rtIssued = now
+	}
+
+	dict := map[string]any{
+		"access_token":  tok.AccessToken,
+		"refresh_token": tok.RefreshToken,
+		"id_token":      tok.IDToken,
+		"token_type":    tok.TokenType,
+		"scope":         tok.Scope,
+	}
+	if tok.ExpiresIn > 0 {
+		dict["expires_in"] = float64(tok.ExpiresIn)
+	}
+	return tm.saveTokens(now, rtIssued, dict)
+}
+
 // ── OAuth helpers ─────────────────────────────────────────────────────────────
 
 func (tm *TokenManager) getNewTokens() (string, error) {
 	authURL := fmt.Sprintf(
-		"https://api.schwabapi.com/v1/oauth/authorize?client_id=%s&redirect_uri=%s",
-		tm.appKey, url.QueryEscape(tm.callbackURL),
+		"%s?client_id=%s&redirect_uri=%s",
+		OAuthAuthorizeURL, tm.appKey, url.QueryEscape(tm.callbackURL),
 	)
 
 	var rawCallback string
@@ -324,14 +455,15 @@ func (tm *TokenManager) getNewTokens() (string, error) {
 	}
 
 	rawCallback = strings.TrimSpace(rawCallback)
-	parsed, err := url.Parse(rawCallback)
-	if err != nil || parsed.Query().Get("code") == "" {
+	code, err := ParseCallbackCode(rawCallback)
+	if errors.Is(err, ErrAuthCodeRequired) {
+		// Not a redirect URL; treat the input as the bare code.
 		return rawCallback, nil
 	}
-	return parsed.Query().Get("code"), nil
+	return code, err
 }
 
-func (tm *TokenManager) postOAuthToken(grantType, code string) (map[string]any, error) {
+func (tm *TokenManager) postOAuthToken(ctx context.Context, grantType, code string) (map[string]any, error) {
 	client := &http.Client{Timeout: OAuthTokenRequestTimeout}
 	auth := base64.StdEncoding.EncodeToString([]byte(tm.appKey + ":" + tm.appSecret))
 
@@ -347,9 +479,11 @@ func (tm *TokenManager) postOAuthToken(grantType, code string) (map[string]any,
 		return nil, ErrInvalidGrantType
 	}
 
-	req, err := http.NewRequest(http.MethodPost,
-		"https://api.schwabapi.com/v1/oauth/token",
-		strings.NewReader(data.Encode()))
+	tm.mu.RLock()
+	tokenURL := tm.tokenURL
+	tm.mu.RUnlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -367,18 +501,18 @@ func (tm *TokenManager) postOAuthToken(grantType, code string) (map[string]any,
 		return nil, err
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		oauthErr := &OAuthError{StatusCode: resp.StatusCode, Body: body}
+		// The body is best effort: gateways may answer with HTML or nothing.
+		_ = json.Unmarshal(body, oauthErr)
+		return nil, oauthErr
+	}
+
 	var result map[string]any
 	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, fmt.Errorf("parse OAuth response: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		if desc, ok := result["error_description"].(string); ok {
-			return nil, fmt.Errorf("OAuth error: %s", desc)
-		}
-		return nil, fmt.Errorf("OAuth request failed (%d): %s", resp.StatusCode, body)
-	}
-
 	return result, nil
 }
 
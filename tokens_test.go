@@ -0,0 +1,448 @@
+package schwabdev_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	schwabdev "github.com/citizenadam/go-schwabapi"
+)
+
+// ── helpers ───────────────────────────────────────────────────────────────────
+
+// newTestTokenManager returns a TokenManager backed by a temp file holding rec
+// and pointed at tokenURL for all OAuth grants.
+func newTestTokenManager(t *testing.T, tokenURL string, rec schwabdev.TokenRecord) (*schwabdev.TokenManager, schwabdev.TokenStorage) {
+	t.Helper()
+
+	storage, err := schwabdev.NewFileTokenStorage(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatalf("NewFileTokenStorage: %v", err)
+	}
+	if rec.AccessToken != "" {
+		if err := storage.Save(context.Background(), rec); err != nil {
+			t.Fatalf("save tokens: %v", err)
+		}
+	}
+
+	tm, err := schwabdev.NewTokenManager(testAppKey, testAppSecret, testCallbackURL, storage, "", nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	tm.SetTokenURL(tokenURL)
+	t.Cleanup(func() { tm.Close() })
+	return tm, storage
+}
+
+// ── Refresh-token grant ───────────────────────────────────────────────────────
+
+func TestTokenManager_RefreshesExpiredAccessToken(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != testAppKey || pass != testAppSecret {
+			t.Errorf("missing or wrong basic auth: %q:%q", user, pass)
+		}
+		r.ParseForm()
+		form = r.PostForm
+		io.WriteString(w, `{"access_token":"new-access","refresh_token":"stale-refresh",`+
+			`"token_type":"Bearer","scope":"api readonly","expires_in":900}`)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC()
+	tm, storage := newTestTokenManager(t, srv.URL, schwabdev.TokenRecord{
+		AccessTokenIssued:  now.Add(-time.Hour),
+		RefreshTokenIssued: now,
+		AccessToken:        "old-access",
+		RefreshToken:       "stale-refresh",
+		ExpiresIn:          1800,
+	})
+
+	got, err := tm.AccessToken()
+	if err != nil {
+		t.Fatalf("AccessToken: %v", err)
+	}
+	if got != "new-access" {
+		t.Errorf("AccessToken: want new-access, got %q", got)
+	}
+	if form.Get("grant_type") != "refresh_token" || form.Get("refresh_token") != "stale-refresh" {
+		t.Errorf("unexpected token request form: %v", form)
+	}
+
+	info := tm.TokenInfo()
+	if d := info.AccessTokenExpiry.Sub(info.AccessTokenIssued); d != 900*time.Second {
+		t.Errorf("access token lifetime: want 15m from expires_in, got %v", d)
+	}
+
+	rec, err := storage.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if rec.AccessToken != "new-access" || rec.ExpiresIn != 900 || rec.Scope != "api readonly" {
+		t.Errorf("persisted record: %+v", rec)
+	}
+}
+
+func TestTokenManager_FreshAccessTokenSkipsRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("token endpoint should not be called")
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC()
+	tm, _ := newTestTokenManager(t, srv.URL, schwabdev.TokenRecord{
+		AccessTokenIssued:  now,
+		RefreshTokenIssued: now,
+		AccessToken:        testAccessToken,
+		RefreshToken:       "test-refresh-token",
+		ExpiresIn:          1800,
+	})
+
+	if got, err := tm.AccessToken(); err != nil || got != testAccessToken {
+		t.Fatalf("AccessToken: got %q, %v", got, err)
+	}
+}
+
+func TestTokenManager_RefreshFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":"invalid_grant","error_description":"refresh token revoked"}`)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC()
+	tm, _ := newTestTokenManager(t, srv.URL, schwabdev.TokenRecord{
+		AccessTokenIssued:  now.Add(-time.Hour),
+		RefreshTokenIssued: now,
+		AccessToken:        "old-access",
+		RefreshToken:       "revoked",
+		ExpiresIn:          1800,
+	})
+
+	_, err := tm.AccessToken()
+	var oauthErr *schwabdev.OAuthError
+	if !errors.As(err, &oauthErr) {
+		t.Fatalf("want *OAuthError from rejected refresh, got %v", err)
+	}
+	if oauthErr.StatusCode != http.StatusBadRequest || oauthErr.Code != "invalid_grant" ||
+		oauthErr.Description != "refresh token revoked" {
+		t.Errorf("unexpected OAuthError: %+v", oauthErr)
+	}
+}
+
+func TestTokenManager_RefreshFailure_OAuthErrorBodies(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode string
+	}{
+		{"invalid client", http.StatusUnauthorized, `{"error":"invalid_client"}`, "invalid_client"},
+		{"non-JSON body", http.StatusBadGateway, `<html>bad gateway</html>`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			now := time.Now().UTC()
+			tm, _ := newTestTokenManager(t, srv.URL, schwabdev.TokenRecord{
+				AccessTokenIssued:  now.Add(-time.Hour),
+				RefreshTokenIssued: now,
+				AccessToken:        "old-access",
+				RefreshToken:       "refresh",
+				ExpiresIn:          1800,
+			})
+
+			_, err := tm.AccessToken()
+			var oauthErr *schwabdev.OAuthError
+			if !errors.As(err, &oauthErr) {
+				t.Fatalf("want *OAuthError, got %v", err)
+			}
+			if oauthErr.StatusCode != tt.status || oauthErr.Code != tt.wantCode || string(oauthErr.Body) != tt.body {
+				t.Errorf("unexpected OAuthError: %+v", oauthErr)
+			}
+		})
+	}
+}
+
+func TestTokenManager_Stats(t *testing.T) {
+	var fail atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `{"access_token":"access","refresh_token":"refresh","expires_in":1800}`)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC()
+	storage := schwabdev.NewMemoryTokenStorage(&schwabdev.TokenRecord{
+		AccessTokenIssued:  now,
+		RefreshTokenIssued: now,
+		AccessToken:        testAccessToken,
+		RefreshToken:       "test-refresh-token",
+		ExpiresIn:          1800,
+	})
+	callOnAuth := func(string) (string, error) { return testCallbackURL + "/?code=auth-code", nil }
+	tm, err := schwabdev.NewTokenManager(testAppKey, testAppSecret, testCallbackURL, storage, "", nil, callOnAuth)
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	defer tm.Close()
+	tm.SetTokenURL(srv.URL)
+
+	for range 3 {
+		if _, err := tm.UpdateTokens(true, false); err != nil {
+			t.Fatalf("UpdateTokens(access): %v", err)
+		}
+	}
+	if _, err := tm.UpdateTokens(false, true); err != nil {
+		t.Fatalf("UpdateTokens(refresh): %v", err)
+	}
+	fail.Store(true)
+	tm.UpdateTokens(true, false)
+	tm.UpdateTokens(false, true)
+
+	want := schwabdev.TokenManagerStats{AccessTokenRefreshes: 3, RefreshTokenRefreshes: 1, RefreshFailures: 2}
+	if got := tm.Stats(); got != want {
+		t.Errorf("Stats: want %+v, got %+v", want, got)
+	}
+}
+
+// ── Authorization-code grant ──────────────────────────────────────────────────
+
+func TestTokenManager_InitializeFromCode(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok {
+			t.Error("missing basic auth")
+		}
+		r.ParseForm()
+		form = r.PostForm
+		io.WriteString(w, `{"access_token":"first-access","refresh_token":"first-refresh",`+
+			`"id_token":"id","token_type":"Bearer","scope":"api","expires_in":1800}`)
+	}))
+	defer srv.Close()
+
+	tm, storage := newTestTokenManager(t, srv.URL, schwabdev.TokenRecord{})
+
+	before := time.Now().UTC()
+	if err := tm.InitializeFromCode(context.Background(), "auth-code"); err != nil {
+		t.Fatalf("InitializeFromCode: %v", err)
+	}
+	if form.Get("grant_type") != "authorization_code" || form.Get("code") != "auth-code" ||
+		form.Get("redirect_uri") != testCallbackURL {
+		t.Errorf("unexpected token request form: %v", form)
+	}
+
+	info := tm.TokenInfo()
+	if info.AccessToken != "first-access" {
+		t.Errorf("AccessToken: want first-access, got %q", info.AccessToken)
+	}
+	if info.AccessTokenIssued.Before(before) || info.RefreshTokenIssued.Before(before) {
+		t.Errorf("issue times not set: %+v", info)
+	}
+
+	rec, err := storage.Load(context.Background())
+	if err != nil || rec == nil {
+		t.Fatalf("Load: %v, %v", rec, err)
+	}
+	if rec.AccessToken != "first-access" || rec.RefreshToken != "first-refresh" {
+		t.Errorf("persisted record: %+v", rec)
+	}
+}
+
+func TestTokenManager_InitializeFromCode_Empty(t *testing.T) {
+	tm, _ := newTestTokenManager(t, "http://127.0.0.1:0", schwabdev.TokenRecord{})
+	if err := tm.InitializeFromCode(context.Background(), ""); !errors.Is(err, schwabdev.ErrAuthCodeRequired) {
+		t.Fatalf("want ErrAuthCodeRequired, got %v", err)
+	}
+}
+
+func TestTokenManager_ApplyToken(t *testing.T) {
+	tm, storage := newTestTokenManager(t, "http://127.0.0.1:0", schwabdev.TokenRecord{})
+
+	before := time.Now().UTC()
+	err := tm.ApplyToken(&schwabdev.Token{
+		AccessToken:  "applied-access",
+		RefreshToken: "applied-refresh",
+		IDToken:      "applied-id",
+		TokenType:    "Bearer",
+		Scope:        "api",
+		ExpiresIn:    900,
+	})
+	if err != nil {
+		t.Fatalf("ApplyToken: %v", err)
+	}
+
+	at, err := tm.AccessToken()
+	if err != nil || at != "applied-access" {
+		t.Fatalf("AccessToken = %q, %v; want applied-access", at, err)
+	}
+	info := tm.TokenInfo()
+	if info.AccessTokenIssued.Before(before) || info.RefreshTokenIssued.Before(before) {
+		t.Errorf("issue times not set: %+v", info)
+	}
+	if got := info.AccessTokenExpiry.Sub(info.AccessTokenIssued); got != 900*time.Second {
+		t.Errorf("access token lifetime = %v, want 15m", got)
+	}
+
+	rec, err := storage.Load(context.Background())
+	if err != nil || rec == nil {
+		t.Fatalf("Load: %v, %v", rec, err)
+	}
+	if rec.RefreshToken != "applied-refresh" || rec.IDToken != "applied-id" || rec.ExpiresIn != 900 {
+		t.Errorf("persisted record: %+v", rec)
+	}
+
+	if err := tm.ApplyToken(&schwabdev.Token{}); !errors.Is(err, schwabdev.ErrAccessTokenRequired) {
+		t.Errorf("empty token: want ErrAccessTokenRequired, got %v", err)
+	}
+}
+
+func TestParseCallbackCode(t *testing.T) {
+	code, err := schwabdev.ParseCallbackCode("https://127.0.0.1/?code=C0.b2F1dGgy.abc%40&session=1b2c3d")
+	if err != nil {
+		t.Fatalf("ParseCallbackCode: %v", err)
+	}
+	if code != "C0.b2F1dGgy.abc@" {
+		t.Errorf("code = %q", code)
+	}
+
+	_, err = schwabdev.ParseCallbackCode("https://127.0.0.1/?error=access_denied&error_description=User+denied+access")
+	if !errors.Is(err, schwabdev.ErrAuthorizationDenied) || !strings.Contains(err.Error(), "User denied access") {
+		t.Errorf("denied: want ErrAuthorizationDenied with description, got %v", err)
+	}
+
+	if _, err := schwabdev.ParseCallbackCode("https://127.0.0.1/?session=1b2c3d"); !errors.Is(err, schwabdev.ErrAuthCodeRequired) {
+		t.Errorf("no code: want ErrAuthCodeRequired, got %v", err)
+	}
+}
+
+// ── Encryption at rest ────────────────────────────────────────────────────────
+
+func TestTokenManager_EncryptsTokensAtRest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"access_token":"secret-access","refresh_token":"secret-refresh",`+
+			`"id_token":"secret-id","scope":"api","expires_in":1800}`)
+	}))
+	defer srv.Close()
+
+	key, err := schwabdev.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	encoded := schwabdev.EncodeKey(key)
+	tokenPath := filepath.Join(t.TempDir(), "tokens.json")
+
+	tm, err := schwabdev.NewTokenManagerWithFilePath(testAppKey, testAppSecret, testCallbackURL, tokenPath, encoded, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenManagerWithFilePath: %v", err)
+	}
+	tm.SetTokenURL(srv.URL)
+	if err := tm.InitializeFromCode(context.Background(), "auth-code"); err != nil {
+		t.Fatalf("InitializeFromCode: %v", err)
+	}
+	tm.Close()
+
+	storage, err := schwabdev.NewFileTokenStorage(tokenPath)
+	if err != nil {
+		t.Fatalf("NewFileTokenStorage: %v", err)
+	}
+	rec, err := storage.Load(context.Background())
+	if err != nil || rec == nil {
+		t.Fatalf("Load: %v, %v", rec, err)
+	}
+	for name, v := range map[string]string{"access": rec.AccessToken, "refresh": rec.RefreshToken, "id": rec.IDToken} {
+		if !strings.HasPrefix(v, schwabdev.EncryptionPrefix) || strings.Contains(v, "secret") {
+			t.Errorf("%s token stored in plaintext: %q", name, v)
+		}
+	}
+	if rec.AccessTokenIssued.IsZero() || rec.Scope != "api" {
+		t.Errorf("metadata should stay readable: %+v", rec)
+	}
+
+	// Same key round-trips.
+	reopened, err := schwabdev.NewTokenManagerWithFilePath(testAppKey, testAppSecret, testCallbackURL, tokenPath, encoded, nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenManagerWithFilePath: %v", err)
+	}
+	defer reopened.Close()
+	if got, err := reopened.AccessToken(); err != nil || got != "secret-access" {
+		t.Fatalf("AccessToken with same key: got %q, %v", got, err)
+	}
+
+	// A different key fails cleanly.
+	other, _ := schwabdev.GenerateKey()
+	wrong, err := schwabdev.NewTokenManagerWithFilePath(testAppKey, testAppSecret, testCallbackURL, tokenPath, schwabdev.EncodeKey(other), nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenManagerWithFilePath: %v", err)
+	}
+	defer wrong.Close()
+	_, err = wrong.AccessToken()
+	if !errors.Is(err, schwabdev.ErrWrongEncryptionKey) {
+		t.Fatalf("want ErrWrongEncryptionKey, got %v", err)
+	}
+	if !errors.Is(err, schwabdev.ErrDecryptionFailed) {
+		t.Errorf("want ErrDecryptionFailed for existing callers, got %v", err)
+	}
+}
+
+// ── Pluggable storage ─────────────────────────────────────────────────────────
+
+func TestTokenManager_MemoryStoragePersistsRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"access_token":"refreshed","expires_in":1800}`)
+	}))
+	defer srv.Close()
+
+	now := time.Now().UTC()
+	storage := schwabdev.NewMemoryTokenStorage(&schwabdev.TokenRecord{
+		AccessTokenIssued:  now.Add(-time.Hour),
+		RefreshTokenIssued: now,
+		AccessToken:        "expired",
+		RefreshToken:       "test-refresh-token",
+		ExpiresIn:          1800,
+	})
+	tm, err := schwabdev.NewTokenManager(testAppKey, testAppSecret, testCallbackURL, storage, "", nil, nil)
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	defer tm.Close()
+	tm.SetTokenURL(srv.URL)
+
+	if got, err := tm.AccessToken(); err != nil || got != "refreshed" {
+		t.Fatalf("AccessToken: got %q, %v", got, err)
+	}
+
+	rec, err := storage.Load(context.Background())
+	if err != nil || rec == nil {
+		t.Fatalf("Load: %v, %v", rec, err)
+	}
+	if rec.AccessToken != "refreshed" || rec.RefreshToken != "test-refresh-token" {
+		t.Errorf("memory storage not updated: %+v", rec)
+	}
+	if !rec.AccessTokenIssued.After(now.Add(-time.Minute)) {
+		t.Errorf("AccessTokenIssued not bumped: %v", rec.AccessTokenIssued)
+	}
+}
+
+func TestMemoryTokenStorage_Empty(t *testing.T) {
+	storage := schwabdev.NewMemoryTokenStorage(nil)
+	if rec, err := storage.Load(context.Background()); rec != nil || err != nil {
+		t.Fatalf("want (nil, nil), got (%v, %v)", rec, err)
+	}
+}
@@ -3,6 +3,17 @@
 // https://github.com/tylerebowers/Schwab-API-Python
 package schwabdev
 
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
 // StreamFields contains mappings from stream types to their field names.
 // Each key represents a stream type, and the value contains the field definitions.
 var StreamFields = map[string]any{
@@ -106,3 +117,417 @@ var StreamFields = map[string]any{
 		"3":   "Message Data",
 	},
 }
+
+// FieldCatalog maps each service to its field-number → name table, derived
+// from StreamFields. The book services list their top-level fields 0-3 and
+// ACCT_ACTIVITY its fields 1-3; named entries such as "key" and the nested
+// book level layouts are not numbered fields and are left out. Use FieldsFor
+// to read it.
+var FieldCatalog = newFieldCatalog(StreamFields)
+
+func newFieldCatalog(defs map[string]any) map[string]map[int]string {
+	catalog := make(map[string]map[int]string, len(defs))
+	for service, def := range defs {
+		table := make(map[int]string)
+		switch def := def.(type) {
+		case []string:
+			for i, name := range def {
+				table[i] = name
+			}
+		case map[string]any:
+			for key, name := range def {
+				i, err := strconv.Atoi(key)
+				if name, ok := name.(string); ok && err == nil {
+					table[i] = name
+				}
+			}
+		}
+		catalog[service] = table
+	}
+	return catalog
+}
+
+// FieldsFor returns the field-number → name table of service, e.g.
+// FieldsFor("LEVELONE_EQUITIES")[3] == "Last Price". The service is matched
+// ignoring case. The returned map is a copy; ok is false for an unknown
+// service.
+func FieldsFor(service string) (fields map[int]string, ok bool) {
+	table, ok := FieldCatalog[strings.ToUpper(service)]
+	if !ok {
+		return nil, false
+	}
+	return maps.Clone(table), true
+}
+
+// Fields translates human-readable field names into the numeric field
+// indices the streamer expects for service, e.g.
+//
+//	fields, err := schwabdev.Fields("LEVELONE_EQUITIES", "lastPrice", "bidPrice")
+//	// fields == []string{"3", "1"}
+//
+// Names are matched against StreamFields ignoring case, spaces and
+// punctuation, so "lastPrice", "Last Price" and "last_price" are equivalent.
+// Unknown names, and services without a numbered field list such as the book
+// services, are rejected with ErrInvalidParameter.
+func Fields(service string, names ...string) ([]string, error) {
+	list, ok := StreamFields[strings.ToUpper(service)].([]string)
+	if !ok {
+		return nil, fmt.Errorf("%w: service %q has no numbered fields", ErrInvalidParameter, service)
+	}
+	fields := make([]string, len(names))
+	for i, name := range names {
+		key := fieldKey(name)
+		idx := slices.IndexFunc(list, func(f string) bool { return fieldKey(f) == key })
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: %s field %q", ErrInvalidParameter, strings.ToUpper(service), name)
+		}
+		fields[i] = strconv.Itoa(idx)
+	}
+	return fields, nil
+}
+
+// FieldsForEquity is Fields for LEVELONE_EQUITIES.
+func FieldsForEquity(names ...string) ([]string, error) {
+	return Fields("LEVELONE_EQUITIES", names...)
+}
+
+// FieldsForOption is Fields for LEVELONE_OPTIONS.
+func FieldsForOption(names ...string) ([]string, error) {
+	return Fields("LEVELONE_OPTIONS", names...)
+}
+
+// FieldsForFutures is Fields for LEVELONE_FUTURES.
+func FieldsForFutures(names ...string) ([]string, error) {
+	return Fields("LEVELONE_FUTURES", names...)
+}
+
+// FieldsForFuturesOption is Fields for LEVELONE_FUTURES_OPTIONS.
+func FieldsForFuturesOption(names ...string) ([]string, error) {
+	return Fields("LEVELONE_FUTURES_OPTIONS", names...)
+}
+
+// FieldsForForex is Fields for LEVELONE_FOREX.
+func FieldsForForex(names ...string) ([]string, error) {
+	return Fields("LEVELONE_FOREX", names...)
+}
+
+// fieldKey reduces a field name to lower-case letters and digits.
+func fieldKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return -1
+	}, name)
+}
+
+// DecodeLevelOneEquity converts one LEVELONE_EQUITIES content entry (keyed by
+// field index) into a LevelOneEquity.
+func DecodeLevelOneEquity(content map[string]any) (*LevelOneEquity, error) {
+	var q LevelOneEquity
+	if err := decodeStreamFields("LEVELONE_EQUITIES", content, &q); err != nil {
+		return nil, fmt.Errorf("decode LEVELONE_EQUITIES: %w", err)
+	}
+	return &q, nil
+}
+
+// DecodeLevelOneFutures converts one LEVELONE_FUTURES content entry into a
+// LevelOneFutures.
+func DecodeLevelOneFutures(content map[string]any) (*LevelOneFutures, error) {
+	var q LevelOneFutures
+	if err := decodeStreamFields("LEVELONE_FUTURES", content, &q); err != nil {
+		return nil, fmt.Errorf("decode LEVELONE_FUTURES: %w", err)
+	}
+	return &q, nil
+}
+
+// DecodeLevelOneFuturesOption converts one LEVELONE_FUTURES_OPTIONS content
+// entry into a LevelOneFuturesOption.
+func DecodeLevelOneFuturesOption(content map[string]any) (*LevelOneFuturesOption, error) {
+	var q LevelOneFuturesOption
+	if err := decodeStreamFields("LEVELONE_FUTURES_OPTIONS", content, &q); err != nil {
+		return nil, fmt.Errorf("decode LEVELONE_FUTURES_OPTIONS: %w", err)
+	}
+	return &q, nil
+}
+
+// DecodeLevelOneForex converts one LEVELONE_FOREX content entry into a
+// LevelOneForex.
+func DecodeLevelOneForex(content map[string]any) (*LevelOneForex, error) {
+	var q LevelOneForex
+	if err := decodeStreamFields("LEVELONE_FOREX", content, &q); err != nil {
+		return nil, fmt.Errorf("decode LEVELONE_FOREX: %w", err)
+	}
+	return &q, nil
+}
+
+// DecodeOrderBook converts one NYSE_BOOK, NASDAQ_BOOK or OPTIONS_BOOK content
+// entry into an OrderBook, with bids sorted by descending and asks by
+// ascending price.
+func DecodeOrderBook(content map[string]any) (*OrderBook, error) {
+	var b OrderBook
+	// The three book services share their top-level field names.
+	if err := decodeStreamFields("NYSE_BOOK", content, &b); err != nil {
+		return nil, fmt.Errorf("decode book: %w", err)
+	}
+	var err error
+	if b.Bids, err = decodeBookLevels(content["2"]); err != nil {
+		return nil, fmt.Errorf("decode book bids: %w", err)
+	}
+	if b.Asks, err = decodeBookLevels(content["3"]); err != nil {
+		return nil, fmt.Errorf("decode book asks: %w", err)
+	}
+	slices.SortStableFunc(b.Bids, func(x, y BookLevel) int { return cmp.Compare(y.Price, x.Price) })
+	slices.SortStableFunc(b.Asks, func(x, y BookLevel) int { return cmp.Compare(x.Price, y.Price) })
+	return &b, nil
+}
+
+func decodeBookLevels(raw any) ([]BookLevel, error) {
+	entries, err := streamEntries(raw)
+	if err != nil {
+		return nil, err
+	}
+	levels := make([]BookLevel, len(entries))
+	for i, entry := range entries {
+		if err := decodeStreamFields("", entry, &levels[i]); err != nil {
+			return nil, fmt.Errorf("level %d: %w", i, err)
+		}
+		mms, err := streamEntries(entry["3"])
+		if err != nil {
+			return nil, fmt.Errorf("level %d market makers: %w", i, err)
+		}
+		for j, mm := range mms {
+			var m BookMarketMaker
+			if err := decodeStreamFields("", mm, &m); err != nil {
+				return nil, fmt.Errorf("level %d market maker %d: %w", i, j, err)
+			}
+			levels[i].MarketMakers = append(levels[i].MarketMakers, m)
+		}
+	}
+	return levels, nil
+}
+
+// streamEntries asserts that raw is an array of field-indexed objects. A
+// missing field yields no entries.
+func streamEntries(raw any) ([]map[string]any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	arr, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("want array, got %T", raw)
+	}
+	entries := make([]map[string]any, len(arr))
+	for i, v := range arr {
+		if entries[i], ok = v.(map[string]any); !ok {
+			return nil, fmt.Errorf("entry %d: want object, got %T", i, v)
+		}
+	}
+	return entries, nil
+}
+
+// chartEquityFields and chartFuturesFields give the field indices of the
+// chart services, which differ between equities and futures.
+type chartEquityFields struct {
+	Sequence int64   `stream:"1"`
+	Open     float64 `stream:"2"`
+	High     float64 `stream:"3"`
+	Low      float64 `stream:"4"`
+	Close    float64 `stream:"5"`
+	Volume   int64   `stream:"6"`
+	Time     int64   `stream:"7"`
+}
+
+type chartFuturesFields struct {
+	Time   int64   `stream:"1"`
+	Open   float64 `stream:"2"`
+	High   float64 `stream:"3"`
+	Low    float64 `stream:"4"`
+	Close  float64 `stream:"5"`
+	Volume int64   `stream:"6"`
+}
+
+// DecodeChartEquity converts one CHART_EQUITY content entry into a Candle.
+// The symbol is not part of Candle; it is content["key"].
+func DecodeChartEquity(content map[string]any) (*Candle, error) {
+	var f chartEquityFields
+	if err := decodeStreamFields("CHART_EQUITY", content, &f); err != nil {
+		return nil, fmt.Errorf("decode CHART_EQUITY: %w", err)
+	}
+	return &Candle{
+		Open:     f.Open,
+		High:     f.High,
+		Low:      f.Low,
+		Close:    f.Close,
+		Volume:   f.Volume,
+		Datetime: f.Time,
+		Sequence: f.Sequence,
+	}, nil
+}
+
+// DecodeChartFutures converts one CHART_FUTURES content entry into a Candle.
+// The symbol is not part of Candle; it is content["key"].
+func DecodeChartFutures(content map[string]any) (*Candle, error) {
+	var f chartFuturesFields
+	if err := decodeStreamFields("CHART_FUTURES", content, &f); err != nil {
+		return nil, fmt.Errorf("decode CHART_FUTURES: %w", err)
+	}
+	return &Candle{
+		Open:     f.Open,
+		High:     f.High,
+		Low:      f.Low,
+		Close:    f.Close,
+		Volume:   f.Volume,
+		Datetime: f.Time,
+	}, nil
+}
+
+// activityMessage is the JSON carried in field 3 of ACCT_ACTIVITY order
+// events.
+type activityMessage struct {
+	SchwabOrderID string
+	AccountNumber string
+	BaseEvent     struct {
+		EventType                                   string
+		OrderFillCompletedEventOrderLegQuantityInfo *struct {
+			LegId         string
+			ExecutionInfo struct {
+				ExecutionQuantity  activityDecimal
+				ExecutionPrice     activityDecimal
+				ExecutionTimeStamp struct{ DateTimeString string }
+			}
+		}
+		OrderUROutCompletedEvent *struct {
+			CancelQuantity activityDecimal
+			OutCancelType  string
+		}
+	}
+}
+
+// activityDecimal accepts the ways Schwab encodes quantities and prices in
+// account activity: a JSON number, a numeric string or {"lo": "<number>"}.
+type activityDecimal float64
+
+func (d *activityDecimal) UnmarshalJSON(b []byte) error {
+	var wrapped struct{ Lo json.Number }
+	if len(b) > 0 && b[0] == '{' {
+		if err := json.Unmarshal(b, &wrapped); err != nil {
+			return err
+		}
+		b = []byte(wrapped.Lo)
+	}
+	f, err := strconv.ParseFloat(strings.Trim(string(b), `"`), 64)
+	if err != nil {
+		return fmt.Errorf("activity decimal %s: %w", b, err)
+	}
+	*d = activityDecimal(f)
+	return nil
+}
+
+// DecodeAccountActivity converts one ACCT_ACTIVITY content entry into an
+// AccountActivity. Message data that is not a JSON order event (for example
+// the SUBSCRIBED confirmation) is kept in Data and leaves Order nil.
+func DecodeAccountActivity(content map[string]any) (*AccountActivity, error) {
+	var a AccountActivity
+	if err := decodeStreamFields("ACCT_ACTIVITY", content, &a); err != nil {
+		return nil, fmt.Errorf("decode ACCT_ACTIVITY: %w", err)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(a.Data), "{") {
+		return &a, nil
+	}
+
+	var msg activityMessage
+	if err := json.Unmarshal([]byte(a.Data), &msg); err != nil {
+		return nil, fmt.Errorf("decode ACCT_ACTIVITY message data: %w", err)
+	}
+	if msg.SchwabOrderID == "" {
+		return &a, nil
+	}
+	a.Order = &ActivityOrder{
+		OrderID:       msg.SchwabOrderID,
+		AccountNumber: msg.AccountNumber,
+		EventType:     msg.BaseEvent.EventType,
+	}
+	if fill := msg.BaseEvent.OrderFillCompletedEventOrderLegQuantityInfo; fill != nil {
+		a.Order.Fill = &ActivityFill{
+			LegID:    fill.LegId,
+			Quantity: float64(fill.ExecutionInfo.ExecutionQuantity),
+			Price:    float64(fill.ExecutionInfo.ExecutionPrice),
+			Time:     fill.ExecutionInfo.ExecutionTimeStamp.DateTimeString,
+		}
+	}
+	if out := msg.BaseEvent.OrderUROutCompletedEvent; out != nil {
+		a.Order.Cancel = &ActivityCancel{
+			Quantity: float64(out.CancelQuantity),
+			Type:     out.OutCancelType,
+		}
+	}
+	return &a, nil
+}
+
+// decodeStreamFields copies content values into the fields of dst (a pointer
+// to a struct) according to their `stream:"<index>"` tags. Numbers arrive as
+// float64 from encoding/json and are converted to the field's kind. service
+// names the FieldCatalog table used to describe a bad field in the error; it
+// is empty for nested entries such as book levels.
+func decodeStreamFields(service string, content map[string]any, dst any) error {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+	for i := range t.NumField() {
+		tag := t.Field(i).Tag.Get("stream")
+		raw, ok := content[tag]
+		if tag == "" || !ok || raw == nil {
+			continue
+		}
+		if err := setStreamField(v.Field(i), raw); err != nil {
+			return fmt.Errorf("field %s (%s): %w", t.Field(i).Name, fieldLabel(service, tag), err)
+		}
+	}
+	return nil
+}
+
+// fieldLabel describes field tag of service for error messages, adding its
+// FieldCatalog name when known, e.g. `3 "Last Price"`.
+func fieldLabel(service, tag string) string {
+	if i, err := strconv.Atoi(tag); err == nil {
+		if name, ok := FieldCatalog[service][i]; ok {
+			return fmt.Sprintf("%s %q", tag, name)
+		}
+	}
+	return tag
+}
+
+func setStreamField(f reflect.Value, raw any) error {
+	switch f.Kind() {
+	case reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("want string, got %T", raw)
+		}
+		f.SetString(s)
+	case reflect.Float64:
+		n, ok := raw.(float64)
+		if !ok {
+			return fmt.Errorf("want number, got %T", raw)
+		}
+		f.SetFloat(n)
+	case reflect.Int64:
+		n, ok := raw.(float64)
+		if !ok {
+			return fmt.Errorf("want number, got %T", raw)
+		}
+		f.SetInt(int64(n))
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("want bool, got %T", raw)
+		}
+		f.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field kind %s", f.Kind())
+	}
+	return nil
+}
@@ -0,0 +1,478 @@
+package schwabdev_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	schwabdev "github.com/citizenadam/go-schwabapi"
+)
+
+// ── Level One equities ────────────────────────────────────────────────────────
+
+func TestDecodeLevelOneEquity(t *testing.T) {
+	// Content entry as it arrives from the streamer (numbers decode as float64).
+	raw := `{"key":"AAPL","delayed":false,"1":190.12,"2":190.15,"3":190.14,"4":300,"5":200,
+		"8":51234567,"10":191.0,"11":188.5,"12":189.3,"14":true,"15":"Apple Inc",
+		"25":"NASDAQ","32":"Normal","33":190.13,"34":1715900000123,"42":0.44,"49":1}`
+	var content map[string]any
+	if err := json.Unmarshal([]byte(raw), &content); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	q, err := schwabdev.DecodeLevelOneEquity(content)
+	if err != nil {
+		t.Fatalf("DecodeLevelOneEquity: %v", err)
+	}
+
+	want := schwabdev.LevelOneEquity{
+		Symbol:           "AAPL",
+		BidPrice:         190.12,
+		AskPrice:         190.15,
+		LastPrice:        190.14,
+		BidSize:          300,
+		AskSize:          200,
+		TotalVolume:      51234567,
+		HighPrice:        191.0,
+		LowPrice:         188.5,
+		ClosePrice:       189.3,
+		Marginable:       true,
+		Description:      "Apple Inc",
+		ExchangeName:     "NASDAQ",
+		SecurityStatus:   "Normal",
+		MarkPrice:        190.13,
+		QuoteTime:        1715900000123,
+		NetPercentChange: 0.44,
+		Shortable:        1,
+	}
+	if *q != want {
+		t.Errorf("decoded:\n got %+v\nwant %+v", *q, want)
+	}
+}
+
+func TestDecodeLevelOneEquity_PartialUpdate(t *testing.T) {
+	q, err := schwabdev.DecodeLevelOneEquity(map[string]any{"key": "MSFT", "3": 420.5})
+	if err != nil {
+		t.Fatalf("DecodeLevelOneEquity: %v", err)
+	}
+	if q.Symbol != "MSFT" || q.LastPrice != 420.5 || q.BidPrice != 0 {
+		t.Errorf("unexpected partial decode: %+v", q)
+	}
+}
+
+func TestDecodeLevelOneEquity_WrongType(t *testing.T) {
+	if _, err := schwabdev.DecodeLevelOneEquity(map[string]any{"key": "AAPL", "3": "190.14"}); err == nil {
+		t.Fatal("want error for string LastPrice")
+	}
+}
+
+// ── Level One futures, futures options and forex ──────────────────────────────
+
+// streamContent decodes raw the way the streamer does, so numbers arrive as
+// float64.
+func streamContent(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var content map[string]any
+	if err := json.Unmarshal([]byte(raw), &content); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return content
+}
+
+func TestDecodeLevelOneFutures(t *testing.T) {
+	content := streamContent(t, `{"key":"/ESZ26","delayed":false,"1":5432.25,"2":5432.5,"3":5432.5,
+		"4":12,"5":9,"8":1250000,"10":1715900000123,"16":"E-mini S&P 500 Index Futures,Dec-2026,ETH",
+		"19":12.75,"20":0.0024,"21":"XCME","23":2512345,"24":5432.4,"25":0.25,"26":12.5,
+		"27":"/ES","30":true,"31":50,"32":true,"33":5419.75,"34":"/ESZ26","35":1797969600000,"39":true}`)
+
+	q, err := schwabdev.DecodeLevelOneFutures(content)
+	if err != nil {
+		t.Fatalf("DecodeLevelOneFutures: %v", err)
+	}
+	want := schwabdev.LevelOneFutures{
+		Symbol:              "/ESZ26",
+		BidPrice:            5432.25,
+		AskPrice:            5432.5,
+		LastPrice:           5432.5,
+		BidSize:             12,
+		AskSize:             9,
+		TotalVolume:         1250000,
+		QuoteTime:           1715900000123,
+		Description:         "E-mini S&P 500 Index Futures,Dec-2026,ETH",
+		NetChange:           12.75,
+		FuturePercentChange: 0.0024,
+		ExchangeName:        "XCME",
+		OpenInterest:        2512345,
+		Mark:                5432.4,
+		Tick:                0.25,
+		TickAmount:          12.5,
+		Product:             "/ES",
+		IsTradable:          true,
+		Multiplier:          50,
+		IsActive:            true,
+		SettlementPrice:     5419.75,
+		ActiveSymbol:        "/ESZ26",
+		ExpirationDate:      1797969600000,
+		QuotedInSession:     true,
+	}
+	if *q != want {
+		t.Errorf("decoded:\n got %+v\nwant %+v", *q, want)
+	}
+}
+
+func TestDecodeLevelOneFuturesOption(t *testing.T) {
+	content := streamContent(t, `{"key":"./OZCZ26C565","1":11.5,"2":11.75,"3":11.625,"8":320,
+		"16":"Corn Options Dec 26 565 Call","18":1480,"19":11.6,"22":50,"24":"/ZCZ26",
+		"25":565,"26":1795305600000,"27":"Standard","28":"C","30":"XCBT"}`)
+
+	q, err := schwabdev.DecodeLevelOneFuturesOption(content)
+	if err != nil {
+		t.Fatalf("DecodeLevelOneFuturesOption: %v", err)
+	}
+	want := schwabdev.LevelOneFuturesOption{
+		Symbol:           "./OZCZ26C565",
+		BidPrice:         11.5,
+		AskPrice:         11.75,
+		LastPrice:        11.625,
+		TotalVolume:      320,
+		Description:      "Corn Options Dec 26 565 Call",
+		OpenInterest:     1480,
+		Mark:             11.6,
+		Multiplier:       50,
+		UnderlyingSymbol: "/ZCZ26",
+		StrikePrice:      565,
+		ExpirationDate:   1795305600000,
+		ExpirationStyle:  "Standard",
+		ContractType:     "C",
+		Exchange:         "XCBT",
+	}
+	if *q != want {
+		t.Errorf("decoded:\n got %+v\nwant %+v", *q, want)
+	}
+}
+
+func TestDecodeLevelOneForex(t *testing.T) {
+	content := streamContent(t, `{"key":"EUR/USD","1":1.08512,"2":1.08527,"3":1.0852,"4":1000000,
+		"5":1000000,"8":1715900000123,"14":"Euro/USDollar Spot","17":0.0012,"18":"GFT","19":5,
+		"20":"Active","21":0.00001,"25":true,"27":1.1276,"28":1.0448,"29":1.0852}`)
+
+	q, err := schwabdev.DecodeLevelOneForex(content)
+	if err != nil {
+		t.Fatalf("DecodeLevelOneForex: %v", err)
+	}
+	want := schwabdev.LevelOneForex{
+		Symbol:         "EUR/USD",
+		BidPrice:       1.08512,
+		AskPrice:       1.08527,
+		LastPrice:      1.0852,
+		BidSize:        1000000,
+		AskSize:        1000000,
+		QuoteTime:      1715900000123,
+		Description:    "Euro/USDollar Spot",
+		PercentChange:  0.0012,
+		ExchangeName:   "GFT",
+		Digits:         5,
+		SecurityStatus: "Active",
+		Tick:           0.00001,
+		IsTradable:     true,
+		High52Week:     1.1276,
+		Low52Week:      1.0448,
+		Mark:           1.0852,
+	}
+	if *q != want {
+		t.Errorf("decoded:\n got %+v\nwant %+v", *q, want)
+	}
+
+	if _, err := schwabdev.DecodeLevelOneForex(map[string]any{"key": "EUR/USD", "25": "true"}); err == nil {
+		t.Error("want error for string IsTradable")
+	}
+}
+
+// ── Order books ───────────────────────────────────────────────────────────────
+
+func TestDecodeOrderBook(t *testing.T) {
+	// Levels arrive out of order to check that DecodeOrderBook sorts them.
+	content := streamContent(t, `{"key":"MSFT","1":1715900000123,
+		"2":[
+			{"0":420.1,"1":300,"2":1,"3":[{"0":"ARCX","1":300,"2":1715900000100}]},
+			{"0":420.12,"1":500,"2":2,"3":[{"0":"NSDQ","1":200,"2":1715900000101},{"0":"BATS","1":300,"2":1715900000102}]}
+		],
+		"3":[
+			{"0":420.2,"1":400,"2":1,"3":[{"0":"EDGX","1":400,"2":1715900000104}]},
+			{"0":420.15,"1":100,"2":1,"3":[{"0":"NSDQ","1":100,"2":1715900000103}]}
+		]}`)
+
+	book, err := schwabdev.DecodeOrderBook(content)
+	if err != nil {
+		t.Fatalf("DecodeOrderBook: %v", err)
+	}
+	if book.Symbol != "MSFT" || book.Time != 1715900000123 {
+		t.Errorf("header: symbol %q time %d", book.Symbol, book.Time)
+	}
+	if len(book.Bids) != 2 || len(book.Asks) != 2 {
+		t.Fatalf("want 2 bids and 2 asks, got %d and %d", len(book.Bids), len(book.Asks))
+	}
+
+	best := book.Bids[0]
+	if best.Price != 420.12 || best.Size != 500 || best.MarketMakerCount != 2 || len(best.MarketMakers) != 2 {
+		t.Errorf("best bid: %+v", best)
+	}
+	if mm := best.MarketMakers[1]; mm.ID != "BATS" || mm.Size != 300 || mm.QuoteTime != 1715900000102 {
+		t.Errorf("best bid market maker: %+v", mm)
+	}
+	if book.Bids[1].Price != 420.1 {
+		t.Errorf("bids not descending: %v then %v", book.Bids[0].Price, book.Bids[1].Price)
+	}
+	if book.Asks[0].Price != 420.15 || book.Asks[1].Price != 420.2 {
+		t.Errorf("asks not ascending: %v then %v", book.Asks[0].Price, book.Asks[1].Price)
+	}
+}
+
+func TestDecodeOrderBook_Malformed(t *testing.T) {
+	if _, err := schwabdev.DecodeOrderBook(map[string]any{"key": "MSFT", "2": "not levels"}); err == nil {
+		t.Error("want error for non-array bids")
+	}
+	if _, err := schwabdev.DecodeOrderBook(map[string]any{"key": "MSFT", "3": []any{map[string]any{"0": "420.1"}}}); err == nil {
+		t.Error("want error for string ask price")
+	}
+	book, err := schwabdev.DecodeOrderBook(map[string]any{"key": "MSFT"})
+	if err != nil || len(book.Bids) != 0 || len(book.Asks) != 0 {
+		t.Errorf("empty book: %+v, %v", book, err)
+	}
+}
+
+// ── Charts ────────────────────────────────────────────────────────────────────
+
+func TestDecodeChartEquity(t *testing.T) {
+	content := streamContent(t, `{"seq":412,"key":"AAPL","1":57,"2":190.12,"3":190.4,"4":189.98,
+		"5":190.31,"6":184532.0,"7":1715900040000,"8":19859}`)
+
+	c, err := schwabdev.DecodeChartEquity(content)
+	if err != nil {
+		t.Fatalf("DecodeChartEquity: %v", err)
+	}
+	want := schwabdev.Candle{
+		Open:     190.12,
+		High:     190.4,
+		Low:      189.98,
+		Close:    190.31,
+		Volume:   184532,
+		Datetime: 1715900040000,
+		Sequence: 57,
+	}
+	if *c != want {
+		t.Errorf("decoded:\n got %+v\nwant %+v", *c, want)
+	}
+	if got := c.Time(); !got.Equal(time.UnixMilli(1715900040000)) {
+		t.Errorf("Time() = %v", got)
+	}
+}
+
+func TestDecodeChartFutures(t *testing.T) {
+	content := streamContent(t, `{"seq":9,"key":"/ESZ26","1":1715900040000,"2":5432.25,"3":5433.0,
+		"4":5431.75,"5":5432.5,"6":1840.0}`)
+
+	c, err := schwabdev.DecodeChartFutures(content)
+	if err != nil {
+		t.Fatalf("DecodeChartFutures: %v", err)
+	}
+	want := schwabdev.Candle{
+		Open:     5432.25,
+		High:     5433.0,
+		Low:      5431.75,
+		Close:    5432.5,
+		Volume:   1840,
+		Datetime: 1715900040000,
+	}
+	if *c != want {
+		t.Errorf("decoded:\n got %+v\nwant %+v", *c, want)
+	}
+
+	if _, err := schwabdev.DecodeChartFutures(map[string]any{"key": "/ESZ26", "2": "5432.25"}); err == nil {
+		t.Error("want error for string open price")
+	}
+}
+
+// ── Account activity ──────────────────────────────────────────────────────────
+
+// activityContent builds an ACCT_ACTIVITY content entry carrying data as its
+// message data, the way Schwab nests a JSON document inside a string field.
+func activityContent(msgType, data string) map[string]any {
+	return map[string]any{"seq": float64(7), "key": "Account Activity", "1": "12345678", "2": msgType, "3": data}
+}
+
+func TestDecodeAccountActivity_Filled(t *testing.T) {
+	data := `{"SchwabOrderID":"1001234567","AccountNumber":"12345678","BaseEvent":{
+		"EventType":"OrderFillCompleted",
+		"OrderFillCompletedEventOrderLegQuantityInfo":{"LegId":"1","ExecutionInfo":{
+			"ExecutionQuantity":{"lo":"10"},"ExecutionPrice":"190.12",
+			"ExecutionTimeStamp":{"DateTimeString":"2026-10-15T14:30:00.123Z"}}}}}`
+
+	a, err := schwabdev.DecodeAccountActivity(activityContent("OrderFillCompleted", data))
+	if err != nil {
+		t.Fatalf("DecodeAccountActivity: %v", err)
+	}
+	if a.Sequence != 7 || a.Account != "12345678" || a.Type != schwabdev.AccountActivityOrderFilled {
+		t.Errorf("header: %+v", a)
+	}
+	if a.Order == nil || a.Order.OrderID != "1001234567" || a.Order.EventType != "OrderFillCompleted" {
+		t.Fatalf("order: %+v", a.Order)
+	}
+	want := schwabdev.ActivityFill{LegID: "1", Quantity: 10, Price: 190.12, Time: "2026-10-15T14:30:00.123Z"}
+	if a.Order.Fill == nil || *a.Order.Fill != want {
+		t.Errorf("fill = %+v, want %+v", a.Order.Fill, want)
+	}
+	if a.Order.Cancel != nil {
+		t.Errorf("unexpected cancel on a fill: %+v", a.Order.Cancel)
+	}
+}
+
+func TestDecodeAccountActivity_Cancelled(t *testing.T) {
+	data := `{"SchwabOrderID":"1001234568","AccountNumber":"12345678","BaseEvent":{
+		"EventType":"OrderUROutCompleted",
+		"OrderUROutCompletedEvent":{"CancelQuantity":{"lo":"5"},"OutCancelType":"FULL"}}}`
+
+	a, err := schwabdev.DecodeAccountActivity(activityContent("OrderUROutCompleted", data))
+	if err != nil {
+		t.Fatalf("DecodeAccountActivity: %v", err)
+	}
+	if a.Type != schwabdev.AccountActivityOrderCancelled || a.Order == nil {
+		t.Fatalf("decoded: %+v", a)
+	}
+	if c := a.Order.Cancel; c == nil || c.Quantity != 5 || c.Type != "FULL" {
+		t.Errorf("cancel = %+v", c)
+	}
+	if a.Order.Fill != nil {
+		t.Errorf("unexpected fill on a cancel: %+v", a.Order.Fill)
+	}
+}
+
+func TestDecodeAccountActivity_NonOrderData(t *testing.T) {
+	a, err := schwabdev.DecodeAccountActivity(activityContent("SUBSCRIBED", ""))
+	if err != nil {
+		t.Fatalf("DecodeAccountActivity: %v", err)
+	}
+	if a.Type != schwabdev.AccountActivitySubscribed || a.Order != nil {
+		t.Errorf("decoded: %+v", a)
+	}
+
+	if _, err := schwabdev.DecodeAccountActivity(activityContent("OrderCreated", `{"SchwabOrderID":`)); err == nil {
+		t.Error("want error for truncated message data")
+	}
+}
+
+// ── Field names ───────────────────────────────────────────────────────────────
+
+func TestFieldsForEquity(t *testing.T) {
+	fields, err := schwabdev.FieldsForEquity("symbol", "lastPrice", "Bid Price", "ask_price", "52 Week High", "markPrice")
+	if err != nil {
+		t.Fatalf("FieldsForEquity: %v", err)
+	}
+	if want := []string{"0", "3", "1", "2", "19", "33"}; !slices.Equal(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+
+	if _, err := schwabdev.FieldsForEquity("lastPrice", "bogus"); !errors.Is(err, schwabdev.ErrInvalidParameter) ||
+		!strings.Contains(err.Error(), "bogus") {
+		t.Errorf("want ErrInvalidParameter naming bogus, got %v", err)
+	}
+}
+
+func TestFields_PerService(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields func(...string) ([]string, error)
+		names  []string
+		want   []string
+	}{
+		{"option", schwabdev.FieldsForOption, []string{"delta", "openInterest"}, []string{"28", "9"}},
+		{"futures", schwabdev.FieldsForFutures, []string{"quoteTime", "futureSettlementPrice"}, []string{"10", "33"}},
+		{"futures option", schwabdev.FieldsForFuturesOption, []string{"underlyingSymbol"}, []string{"24"}},
+		{"forex", schwabdev.FieldsForForex, []string{"mark", "digits"}, []string{"29", "19"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.fields(tt.names...)
+			if err != nil || !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := schwabdev.Fields("NASDAQ_BOOK", "symbol"); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+		t.Errorf("book service: want ErrInvalidParameter, got %v", err)
+	}
+}
+
+func TestFieldsFor(t *testing.T) {
+	tests := []struct {
+		service string
+		index   int
+		want    string
+	}{
+		{"LEVELONE_EQUITIES", 0, "Symbol"},
+		{"LEVELONE_EQUITIES", 3, "Last Price"},
+		{"levelone_equities", 33, "Mark Price"},
+		{"CHART_EQUITY", 1, "Sequence"},
+		{"CHART_EQUITY", 5, "Close Price"},
+		{"CHART_EQUITY", 7, "Chart Time"},
+		{"NYSE_BOOK", 2, "Bid Side Levels"},
+		{"ACCT_ACTIVITY", 3, "Message Data"},
+	}
+	for _, tt := range tests {
+		fields, ok := schwabdev.FieldsFor(tt.service)
+		if !ok || fields[tt.index] != tt.want {
+			t.Errorf("FieldsFor(%s)[%d] = %q, %v; want %q", tt.service, tt.index, fields[tt.index], ok, tt.want)
+		}
+	}
+
+	fields, _ := schwabdev.FieldsFor("CHART_EQUITY")
+	if len(fields) != 9 {
+		t.Errorf("CHART_EQUITY has %d fields, want 9", len(fields))
+	}
+	fields[1] = "changed"
+	if again, _ := schwabdev.FieldsFor("CHART_EQUITY"); again[1] != "Sequence" {
+		t.Error("FieldsFor should return a copy")
+	}
+	if _, ok := schwabdev.FieldsFor("BOGUS"); ok {
+		t.Error("FieldsFor(BOGUS) should report ok=false")
+	}
+
+	_, err := schwabdev.DecodeLevelOneEquity(map[string]any{"3": "190.14"})
+	if err == nil || !strings.Contains(err.Error(), `"Last Price"`) {
+		t.Errorf("decode error should name the field, got %v", err)
+	}
+}
+
+// TestFieldsFor_CoversDecoderTags checks that every numbered stream tag on the
+// typed updates is a field in the catalog of its service.
+func TestFieldsFor_CoversDecoderTags(t *testing.T) {
+	for service, v := range map[string]any{
+		"LEVELONE_EQUITIES":        schwabdev.LevelOneEquity{},
+		"LEVELONE_FUTURES":         schwabdev.LevelOneFutures{},
+		"LEVELONE_FUTURES_OPTIONS": schwabdev.LevelOneFuturesOption{},
+		"LEVELONE_FOREX":           schwabdev.LevelOneForex{},
+		"NYSE_BOOK":                schwabdev.OrderBook{},
+		"ACCT_ACTIVITY":            schwabdev.AccountActivity{},
+	} {
+		fields, ok := schwabdev.FieldsFor(service)
+		if !ok {
+			t.Fatalf("no catalog for %s", service)
+		}
+		typ := reflect.TypeOf(v)
+		for i := range typ.NumField() {
+			index, err := strconv.Atoi(typ.Field(i).Tag.Get("stream"))
+			if err != nil {
+				continue
+			}
+			if _, ok := fields[index]; !ok {
+				t.Errorf("%s.%s: field %d not in the %s catalog", typ.Name(), typ.Field(i).Name, index, service)
+			}
+		}
+	}
+}
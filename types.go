@@ -1,5 +1,21 @@
 package schwabdev
 
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
 // ============================================================================
 // ACCOUNTS & TRADING API RESPONSE TYPES
 // ============================================================================
@@ -77,6 +93,12 @@ type CurrentBalances struct {
 	AccruedInterest                  float64 `json:"accruedInterest"`
 	CashBalance                      float64 `json:"cashBalance"`
 	CashReceipts                     float64 `json:"cashReceipts"`
+	CashAvailableForTrading          float64 `json:"cashAvailableForTrading"`
+	CashAvailableForWithdrawal       float64 `json:"cashAvailableForWithdrawal"`
+	CashCall                         float64 `json:"cashCall"`
+	CashDebitCallValue               float64 `json:"cashDebitCallValue"`
+	UnsettledCash                    float64 `json:"unsettledCash"`
+	TotalCash                        float64 `json:"totalCash"`
 	LongOptionMarketValue            float64 `json:"longOptionMarketValue"`
 	LiquidationValue                 float64 `json:"liquidationValue"`
 	LongMarketValue                  float64 `json:"longMarketValue"`
@@ -123,24 +145,54 @@ type AggregatedBalance struct {
 	LiquidationValue        float64 `json:"liquidationValue"`
 }
 
-// Position represents a position held in an account
+// Position represents a position held in an account, as returned when
+// account details are requested with fields=positions
 type Position struct {
-	ShortQuantity                float64 `json:"shortQuantity"`
-	AveragePrice                 float64 `json:"averagePrice"`
-	MarketValue                  float64 `json:"marketValue"`
-	LongQuantity                 float64 `json:"longQuantity"`
-	PreviousSessionLongQuantity  float64 `json:"previousSessionLongQuantity"`
-	PreviousSessionShortQuantity float64 `json:"previousSessionShortQuantity"`
-	ChangedSinceLastSession      bool    `json:"changedSinceLastSession"`
-	AssetType                    string  `json:"assetType"`
-	Cusip                        string  `json:"cusip"`
-	Symbol                       string  `json:"symbol"`
-	InstrumentID                 int64   `json:"instrumentId"`
+	ShortQuantity                  float64     `json:"shortQuantity"`
+	AveragePrice                   float64     `json:"averagePrice"`
+	CurrentDayProfitLoss           float64     `json:"currentDayProfitLoss"`
+	CurrentDayProfitLossPercentage float64     `json:"currentDayProfitLossPercentage"`
+	LongQuantity                   float64     `json:"longQuantity"`
+	SettledLongQuantity            float64     `json:"settledLongQuantity"`
+	SettledShortQuantity           float64     `json:"settledShortQuantity"`
+	AgedQuantity                   float64     `json:"agedQuantity"`
+	Instrument                     *Instrument `json:"instrument,omitempty"`
+	MarketValue                    float64     `json:"marketValue"`
+	MaintenanceRequirement         float64     `json:"maintenanceRequirement"`
+	AverageLongPrice               float64     `json:"averageLongPrice"`
+	AverageShortPrice              float64     `json:"averageShortPrice"`
+	TaxLotAverageLongPrice         float64     `json:"taxLotAverageLongPrice"`
+	TaxLotAverageShortPrice        float64     `json:"taxLotAverageShortPrice"`
+	LongOpenProfitLoss             float64     `json:"longOpenProfitLoss"`
+	ShortOpenProfitLoss            float64     `json:"shortOpenProfitLoss"`
+	PreviousSessionLongQuantity    float64     `json:"previousSessionLongQuantity"`
+	PreviousSessionShortQuantity   float64     `json:"previousSessionShortQuantity"`
+	CurrentDayCost                 float64     `json:"currentDayCost"`
+	ChangedSinceLastSession        bool        `json:"changedSinceLastSession"`
+
+	// Deprecated: Schwab nests these under Instrument; they are only set
+	// when a Position is built by hand.
+	AssetType    string `json:"assetType,omitempty"`
+	Cusip        string `json:"cusip,omitempty"`
+	Symbol       string `json:"symbol,omitempty"`
+	InstrumentID int64  `json:"instrumentId,omitempty"`
+}
+
+// OrderLister is implemented by the order list responses, so code that only
+// needs the orders can accept either AccountOrdersResponse or
+// AccountOrdersAllResponse.
+type OrderLister interface {
+	OrderList() []Order
 }
 
 // AccountOrdersResponse is the response for GET /trader/v1/accounts/{accountHash}/orders
 type AccountOrdersResponse []Order
 
+// OrderList returns the orders in the response.
+func (r AccountOrdersResponse) OrderList() []Order {
+	return r
+}
+
 // Order represents an order object
 type Order struct {
 	Session                  string           `json:"session"`
@@ -165,6 +217,7 @@ type Order struct {
 	Tag                      *string          `json:"tag,omitempty"`
 	AccountNumber            int64            `json:"accountNumber"`
 	OrderActivityCollection  []*OrderActivity `json:"orderActivityCollection,omitempty"`
+	ChildOrderStrategies     []*Order         `json:"childOrderStrategies,omitempty"`
 }
 
 // OrderLeg represents a leg of an order
@@ -179,10 +232,15 @@ type OrderLeg struct {
 
 // Instrument represents a financial instrument
 type Instrument struct {
-	AssetType    string `json:"assetType"`
-	Cusip        string `json:"cusip,omitempty"`
-	Symbol       string `json:"symbol"`
-	InstrumentID int64  `json:"instrumentId,omitempty"`
+	AssetType        string  `json:"assetType"`
+	Cusip            string  `json:"cusip,omitempty"`
+	Symbol           string  `json:"symbol"`
+	Description      string  `json:"description,omitempty"`
+	InstrumentID     int64   `json:"instrumentId,omitempty"`
+	NetChange        float64 `json:"netChange,omitempty"`
+	Type             string  `json:"type,omitempty"`
+	PutCall          string  `json:"putCall,omitempty"`
+	UnderlyingSymbol string  `json:"underlyingSymbol,omitempty"`
 }
 
 // OrderActivity represents order execution activity
@@ -225,6 +283,11 @@ type ReplaceOrderResponse struct{}
 // AccountOrdersAllResponse is the response for GET /trader/v1/orders
 type AccountOrdersAllResponse []Order
 
+// OrderList returns the orders in the response.
+func (r AccountOrdersAllResponse) OrderList() []Order {
+	return r
+}
+
 // PreviewOrderResponse is the response for POST /trader/v1/accounts/{accountHash}/previewOrder
 type PreviewOrderResponse struct {
 	OrderID               int64                  `json:"orderId"`
@@ -334,6 +397,39 @@ type FeeValue struct {
 // TransactionsResponse is the response for GET /trader/v1/accounts/{accountHash}/transactions
 type TransactionsResponse []Transaction
 
+// ByType returns the transactions whose Type matches t, ignoring case, in
+// their original order.
+func (r TransactionsResponse) ByType(t string) []Transaction {
+	return r.filter(func(tx Transaction) bool { return strings.EqualFold(tx.Type, t) })
+}
+
+// BySymbol returns the transactions for symbol, ignoring case and
+// surrounding whitespace, in their original order.
+func (r TransactionsResponse) BySymbol(symbol string) []Transaction {
+	symbol = NormalizeSymbol(symbol)
+	return r.filter(func(tx Transaction) bool { return NormalizeSymbol(tx.Symbol) == symbol })
+}
+
+// TotalAmount returns the sum of NetAmount over all transactions: positive
+// for net cash received, negative for net cash paid.
+func (r TransactionsResponse) TotalAmount() float64 {
+	var total float64
+	for _, tx := range r {
+		total += tx.NetAmount
+	}
+	return total
+}
+
+func (r TransactionsResponse) filter(keep func(Transaction) bool) []Transaction {
+	var out []Transaction
+	for _, tx := range r {
+		if keep(tx) {
+			out = append(out, tx)
+		}
+	}
+	return out
+}
+
 // Transaction represents a transaction
 type Transaction struct {
 	TransactionID string  `json:"transactionId"`
@@ -355,58 +451,177 @@ type PreferencesResponse struct {
 
 // StreamerInfo represents streamer configuration
 type StreamerInfo struct {
-	StreamerURL            string `json:"streamerUrl"`
+	StreamerURL            string `json:"streamerSocketUrl"`
+	SchwabClientCustomerID string `json:"schwabClientCustomerId"`
 	SchwabClientCorrelID   string `json:"schwabClientCorrelId"`
 	SchwabClientChannel    string `json:"schwabClientChannel"`
 	SchwabClientFunctionID string `json:"schwabClientFunctionId"`
 }
 
+// ErrorResponse is the error body returned by the Schwab API on failure.
+// Trader endpoints populate Message; OAuth-style failures populate Code and
+// Description instead.
+type ErrorResponse struct {
+	Code        string `json:"error,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// Token is the response for POST /v1/oauth/token, for either grant type.
+// Pass one obtained outside the TokenManager to TokenManager.ApplyToken.
+type Token struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+	TokenType    string `json:"token_type"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
 // ============================================================================
 // MARKET DATA API RESPONSE TYPES
 // ============================================================================
 
 // QuotesResponse is the response for GET /marketdata/v1/quotes
+//
+// Symbols Schwab could not price are reported in a top-level "errors" object
+// or as an error entry under the symbol's key; both decode into a Quote whose
+// Error is set. Use Get to look up a symbol and its error together.
 type QuotesResponse map[string]Quote
 
+// QuoteErrors is the "errors" object of a quotes response.
+type QuoteErrors struct {
+	InvalidSymbols []string `json:"invalidSymbols,omitempty"`
+	InvalidCusips  []string `json:"invalidCusips,omitempty"`
+	InvalidSSIDs   []int64  `json:"invalidSSIDs,omitempty"`
+}
+
+// UnmarshalJSON decodes quotes keyed by symbol, turning the "errors" object
+// and per-symbol error entries into Quotes with Error set.
+func (r *QuotesResponse) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	out := make(QuotesResponse, len(raw))
+	for key, entry := range raw {
+		if key == "errors" {
+			var errs QuoteErrors
+			if err := json.Unmarshal(entry, &errs); err != nil {
+				return fmt.Errorf("decode quote errors: %w", err)
+			}
+			for _, sym := range errs.InvalidSymbols {
+				out[sym] = Quote{Symbol: sym, Error: &QuoteError{Symbol: sym, Reason: "invalid symbol"}}
+			}
+			for _, cusip := range errs.InvalidCusips {
+				out[cusip] = Quote{Symbol: cusip, Error: &QuoteError{Symbol: cusip, Reason: "invalid cusip"}}
+			}
+			for _, ssid := range errs.InvalidSSIDs {
+				id := fmt.Sprint(ssid)
+				out[id] = Quote{Symbol: id, Error: &QuoteError{Symbol: id, Reason: "invalid ssid"}}
+			}
+			continue
+		}
+
+		var q Quote
+		if err := json.Unmarshal(entry, &q); err != nil {
+			return fmt.Errorf("decode quote %s: %w", key, err)
+		}
+		if q.AssetMainType == "" && q.QuoteData == nil {
+			var e struct {
+				Message     string `json:"message"`
+				Description string `json:"description"`
+			}
+			if json.Unmarshal(entry, &e) == nil && (e.Message != "" || e.Description != "") {
+				q = Quote{Symbol: key, Error: &QuoteError{Symbol: key, Reason: cmp.Or(e.Message, e.Description)}}
+			}
+		}
+		out[key] = q
+	}
+	*r = out
+	return nil
+}
+
+// Get returns the quote for symbol, the symbol-specific error if Schwab could
+// not price it, or a *QuoteError if the symbol is absent from the response.
+func (r QuotesResponse) Get(symbol string) (*Quote, error) {
+	q, ok := r[symbol]
+	if !ok {
+		return nil, &QuoteError{Symbol: symbol, Reason: "not in response"}
+	}
+	if q.Error != nil {
+		return nil, q.Error
+	}
+	return &q, nil
+}
+
+// Errors returns the per-symbol errors in the response, keyed by symbol.
+func (r QuotesResponse) Errors() map[string]*QuoteError {
+	errs := make(map[string]*QuoteError)
+	for sym, q := range r {
+		if q.Error != nil {
+			errs[sym] = q.Error
+		}
+	}
+	return errs
+}
+
+// WriteCSV writes the quotes for symbols to w as CSV, in the given order,
+// under the header symbol,last,bid,ask,volume,change,percentChange. Symbols
+// that are absent, errored or have no quote data are skipped. A nil symbols
+// writes every quote in the response, sorted by symbol.
+func (r QuotesResponse) WriteCSV(w io.Writer, symbols []string) error {
+	if symbols == nil {
+		symbols = slices.Sorted(maps.Keys(r))
+	}
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"symbol", "last", "bid", "ask", "volume", "change", "percentChange"})
+	for _, sym := range symbols {
+		q, ok := r[sym]
+		if !ok || q.Error != nil || q.QuoteData == nil {
+			continue
+		}
+		d := q.QuoteData
+		cw.Write([]string{
+			sym,
+			formatFloat(d.LastPrice),
+			formatFloat(d.BidPrice),
+			formatFloat(d.AskPrice),
+			strconv.FormatInt(d.TotalVolume, 10),
+			formatFloat(d.NetChange),
+			formatFloat(d.NetPercentChange),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatFloat formats f with the fewest digits that round-trip, for CSV
+// export.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
 // QuoteResponse is the response for GET /marketdata/v1/{symbol_id}/quotes
 type QuoteResponse Quote
 
 // Quote represents a complete quote with all data sections
 type Quote struct {
-	AssetMainType           string
-	AssetSubType            string
-	QuoteType               string
-	RealTime                bool
-	SSID                    int `json:"ssid"`
-	Symbol                  string
-	Hi52                    float64 `json:"52WeekHigh"`
-	Lo52                    float64 `json:"52WeekLow"`
-	AskMICId                string
-	AskPrice                float64
-	AskSize                 int
-	AskTime                 int
-	BidMICId                string
-	BidPrice                float64
-	BidSize                 int
-	BidTime                 int
-	Close                   float64 `json:"closePrice"`
-	HiPrice                 float64 `json:"highPrice"`
-	LastMICId               string
-	LastPrice               float64
-	LastSize                int
-	LoPrice                 float64 `json:"lowPrice"`
-	Mark                    float64 `json:"mark"`
-	MarkChange              float64
-	MarkPercentChange       float64
-	NetChange               float64
-	NetPercentChange        float64
-	Open                    float64
-	PostMarketChange        float64
-	PostMarketPercentChange float64
-	QuoteTime               int
-	SecurityStatus          string
-	TotalVolume             int
-	TradeTime               int
+	AssetMainType string       `json:"assetMainType"`
+	AssetSubType  string       `json:"assetSubType,omitempty"`
+	QuoteType     string       `json:"quoteType,omitempty"`
+	Realtime      bool         `json:"realtime"`
+	Ssid          int64        `json:"ssid"`
+	Symbol        string       `json:"symbol"`
+	QuoteData     *QuoteData   `json:"quote,omitempty"`
+	Fundamental   *Fundamental `json:"fundamental,omitempty"`
+	Reference     *Reference   `json:"reference,omitempty"`
+	Regular       *Regular     `json:"regular,omitempty"`
+
+	// Error is set instead of the data sections when Schwab could not price
+	// the symbol in a multi-symbol quotes response.
+	Error *QuoteError `json:"-"`
 }
 
 // Fundamental represents fundamental data
@@ -501,6 +716,117 @@ type OptionChainsResponse struct {
 	PutExpDateMap     map[string]map[string][]OptionContract `json:"putExpDateMap"`
 }
 
+// Contracts flattens the expiration/strike maps into a single slice ordered
+// by expiration date, then strike, then calls before puts. putCall selects
+// "CALL" or "PUT" contracts; "ALL" or "" returns both.
+func (r *OptionChainsResponse) Contracts(putCall string) []*OptionContract {
+	var sides []map[string]map[string][]OptionContract
+	switch strings.ToUpper(putCall) {
+	case "CALL":
+		sides = append(sides, r.CallExpDateMap)
+	case "PUT":
+		sides = append(sides, r.PutExpDateMap)
+	case "", "ALL":
+		sides = append(sides, r.CallExpDateMap, r.PutExpDateMap)
+	}
+
+	type entry struct {
+		expiration string
+		contract   *OptionContract
+	}
+	var entries []entry
+	for _, side := range sides {
+		for exp, strikes := range side {
+			for _, contracts := range strikes {
+				for i := range contracts {
+					entries = append(entries, entry{expirationKeyDate(exp), &contracts[i]})
+				}
+			}
+		}
+	}
+	slices.SortFunc(entries, func(a, b entry) int {
+		return cmp.Or(
+			cmp.Compare(a.expiration, b.expiration),
+			cmp.Compare(a.contract.StrikePrice, b.contract.StrikePrice),
+			cmp.Compare(a.contract.PutCall, b.contract.PutCall),
+			cmp.Compare(a.contract.Symbol, b.contract.Symbol),
+		)
+	})
+
+	out := make([]*OptionContract, len(entries))
+	for i, e := range entries {
+		out[i] = e.contract
+	}
+	return out
+}
+
+// AtStrike returns the calls and puts of every expiration struck at strike,
+// in the order of Contracts.
+func (r *OptionChainsResponse) AtStrike(strike float64) []*OptionContract {
+	var out []*OptionContract
+	for _, c := range r.Contracts("ALL") {
+		if c.StrikePrice == strike {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// NearTheMoney returns the contracts on either side of the money: for each
+// expiration and side, the strikes where InTheMoney flips between adjacent
+// strikes, i.e. the nearest in-the-money and the nearest out-of-the-money
+// strike. A side whose contracts are all in or all out of the money
+// contributes nothing. Contracts are in the order of Contracts.
+func (r *OptionChainsResponse) NearTheMoney() []*OptionContract {
+	near := make(map[*OptionContract]bool)
+	for _, side := range []map[string]map[string][]OptionContract{r.CallExpDateMap, r.PutExpDateMap} {
+		for _, strikes := range side {
+			var cs []*OptionContract
+			for _, contracts := range strikes {
+				for i := range contracts {
+					cs = append(cs, &contracts[i])
+				}
+			}
+			slices.SortFunc(cs, func(a, b *OptionContract) int {
+				return cmp.Compare(a.StrikePrice, b.StrikePrice)
+			})
+			for i := 1; i < len(cs); i++ {
+				if cs[i].InTheMoney != cs[i-1].InTheMoney {
+					near[cs[i-1]], near[cs[i]] = true, true
+				}
+			}
+		}
+	}
+
+	var out []*OptionContract
+	for _, c := range r.Contracts("ALL") {
+		if near[c] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// ExpirationDates returns the distinct expiration dates (YYYY-MM-DD) present
+// in either map, in chronological order.
+func (r *OptionChainsResponse) ExpirationDates() []string {
+	var dates []string
+	for _, m := range []map[string]map[string][]OptionContract{r.CallExpDateMap, r.PutExpDateMap} {
+		for exp := range m {
+			dates = append(dates, expirationKeyDate(exp))
+		}
+	}
+	slices.Sort(dates)
+	return slices.Compact(dates)
+}
+
+// expirationKeyDate strips the ":<days to expiration>" suffix from an
+// expiration map key such as "2024-06-21:5".
+func expirationKeyDate(key string) string {
+	date, _, _ := strings.Cut(key, ":")
+	return date
+}
+
 // OptionContract represents an option contract
 type OptionContract struct {
 	PutCall                string               `json:"putCall"`
@@ -579,6 +905,49 @@ type ExpirationDate struct {
 	Standard         bool   `json:"standard"`
 }
 
+// BuildOptionSymbol formats an option symbol in the OSI layout Schwab uses
+// for orders, quotes and LEVELONE_OPTIONS keys: the root padded with spaces
+// to six characters, the expiry as YYMMDD, 'C' or 'P', and the strike in
+// thousandths as eight digits, e.g. BuildOptionSymbol("AAPL", expiry, 'C', 95)
+// returns "AAPL  240809C00095000". Only the date of expiry is used; callPut
+// is uppercased and strike is rounded to the nearest tenth of a cent.
+func BuildOptionSymbol(root string, expiry time.Time, callPut rune, strike float64) string {
+	return fmt.Sprintf("%-6s%s%c%08d",
+		strings.ToUpper(root), expiry.Format("060102"), unicode.ToUpper(callPut), int64(math.Round(strike*1000)))
+}
+
+// ParseOptionSymbol splits an OSI option symbol, as built by
+// BuildOptionSymbol, into its root, expiry (midnight UTC), 'C' or 'P', and
+// strike. The root padding is optional, so "AAPL240809C00095000" parses too.
+// A malformed symbol is rejected with an error wrapping ErrInvalidParameter.
+func ParseOptionSymbol(sym string) (root string, expiry time.Time, callPut rune, strike float64, err error) {
+	invalid := func(field string) error {
+		return fmt.Errorf("failed to parse option symbol: %w: %s in %q", ErrInvalidParameter, field, sym)
+	}
+	// The expiry, type and strike are a fixed 15 characters after the root.
+	n := len(sym) - 15
+	if n < 1 {
+		return "", time.Time{}, 0, 0, invalid("length")
+	}
+	root = strings.TrimRight(sym[:n], " ")
+	if root == "" || len(root) > 6 || strings.Contains(root, " ") {
+		return "", time.Time{}, 0, 0, invalid("root")
+	}
+	expiry, err = time.Parse("060102", sym[n:n+6])
+	if err != nil {
+		return "", time.Time{}, 0, 0, invalid("expiry")
+	}
+	callPut = rune(sym[n+6])
+	if callPut != 'C' && callPut != 'P' {
+		return "", time.Time{}, 0, 0, invalid("call/put")
+	}
+	thousandths, err := strconv.ParseUint(sym[n+7:], 10, 64)
+	if err != nil {
+		return "", time.Time{}, 0, 0, invalid("strike")
+	}
+	return root, expiry, callPut, float64(thousandths) / 1000, nil
+}
+
 // PriceHistoryResponse is the response for GET /marketdata/v1/pricehistory
 type PriceHistoryResponse struct {
 	Candles []*Candle `json:"candles"`
@@ -594,6 +963,92 @@ type Candle struct {
 	Close    float64 `json:"close"`
 	Volume   int64   `json:"volume"`
 	Datetime int64   `json:"datetime"`
+
+	// Sequence is the chart stream's bar sequence number. It is only set on
+	// candles decoded from CHART_EQUITY updates.
+	Sequence int64 `json:"sequence,omitempty"`
+}
+
+// Time returns the candle's Datetime (Unix epoch milliseconds) as a UTC time.
+func (c *Candle) Time() time.Time {
+	return time.UnixMilli(c.Datetime).UTC()
+}
+
+// TimeRange returns the times of the earliest and latest candles in the
+// response, skipping nil entries. Both are zero when there are no candles.
+func (p *PriceHistoryResponse) TimeRange() (start, end time.Time) {
+	first := true
+	for _, c := range p.Candles {
+		if c == nil {
+			continue
+		}
+		t := c.Time()
+		if first || t.Before(start) {
+			start = t
+		}
+		if first || t.After(end) {
+			end = t
+		}
+		first = false
+	}
+	return start, end
+}
+
+// SortedCandles returns copies of the candles ordered by ascending Datetime,
+// skipping nil entries. Candles with equal times keep their response order.
+func (p *PriceHistoryResponse) SortedCandles() []Candle {
+	candles := make([]Candle, 0, len(p.Candles))
+	for _, c := range p.Candles {
+		if c != nil {
+			candles = append(candles, *c)
+		}
+	}
+	slices.SortStableFunc(candles, func(a, b Candle) int { return cmp.Compare(a.Datetime, b.Datetime) })
+	return candles
+}
+
+// Gaps returns the start time of each run of missing bars, given the
+// expected spacing between consecutive candles (e.g. time.Minute for
+// one-minute bars). A gap is reported where the next candle starts more than
+// expected after the previous one, at the previous candle's time plus
+// expected. Market closures such as nights and weekends count as gaps. If
+// only gaps within sessions matter, filter the result one date at a time:
+// a MarketHour describes a single date, so check each gap with IsOpenAt on
+// the MarketHour for that gap's date. Gaps returns nil when expected is not
+// positive.
+func (p *PriceHistoryResponse) Gaps(expected time.Duration) []time.Time {
+	if expected <= 0 {
+		return nil
+	}
+	var gaps []time.Time
+	candles := p.SortedCandles()
+	for i := 1; i < len(candles); i++ {
+		prev := candles[i-1].Time()
+		if candles[i].Time().Sub(prev) > expected {
+			gaps = append(gaps, prev.Add(expected))
+		}
+	}
+	return gaps
+}
+
+// WriteCSV writes the candles to w as CSV in ascending time order, under the
+// header datetime,open,high,low,close,volume. datetime is formatted as
+// RFC 3339 in UTC.
+func (p *PriceHistoryResponse) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"datetime", "open", "high", "low", "close", "volume"})
+	for _, c := range p.SortedCandles() {
+		cw.Write([]string{
+			c.Time().Format(time.RFC3339),
+			formatFloat(c.Open),
+			formatFloat(c.High),
+			formatFloat(c.Low),
+			formatFloat(c.Close),
+			strconv.FormatInt(c.Volume, 10),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
 }
 
 // MoversResponse is the response for GET /marketdata/v1/movers/{symbol}
@@ -609,6 +1064,30 @@ type Mover struct {
 	Volume        int64   `json:"volume"`
 }
 
+// SortByPercentChange sorts the movers in place by PercentChange, largest
+// first when desc is true and smallest first otherwise. Movers with equal
+// changes keep their order.
+func (r MoversResponse) SortByPercentChange(desc bool) {
+	slices.SortStableFunc(r, func(a, b Mover) int {
+		if desc {
+			return cmp.Compare(b.PercentChange, a.PercentChange)
+		}
+		return cmp.Compare(a.PercentChange, b.PercentChange)
+	})
+}
+
+// TopN returns a copy of the first n movers, or of all of them if there are
+// fewer than n. Sort first to take the biggest gainers or losers:
+//
+//	movers.SortByPercentChange(true)
+//	gainers := movers.TopN(5)
+func (r MoversResponse) TopN(n int) []Mover {
+	if n <= 0 {
+		return nil
+	}
+	return slices.Clone(r[:min(n, len(r))])
+}
+
 // MarketHoursResponse is the response for GET /marketdata/v1/markets
 type MarketHoursResponse map[string]MarketHour
 
@@ -627,8 +1106,61 @@ type MarketHour struct {
 	SessionHours *SessionHours `json:"sessionHours,omitempty"`
 }
 
+// IsOpenAt reports whether t falls within one of the regular sessions
+// (start inclusive, end exclusive). It is always false when IsOpen is false.
+func (m MarketHour) IsOpenAt(t time.Time) bool {
+	return m.openAt(t, false)
+}
+
+// IsExtendedOpenAt is like IsOpenAt but also counts the pre- and post-market
+// sessions.
+func (m MarketHour) IsExtendedOpenAt(t time.Time) bool {
+	return m.openAt(t, true)
+}
+
+// NextOpen returns the start of the first regular session beginning after t.
+// The response covers a single date, so it returns the zero time when no
+// later session starts on that date (or the market is closed); fetch the
+// following date's hours in that case.
+func (m MarketHour) NextOpen(t time.Time) time.Time {
+	var next time.Time
+	for _, session := range m.sessions(false) {
+		start, _, ok := session.bounds(m.Date)
+		if ok && start.After(t) && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return next
+}
+
+func (m MarketHour) openAt(t time.Time, extended bool) bool {
+	for _, session := range m.sessions(extended) {
+		start, end, ok := session.bounds(m.Date)
+		if ok && !t.Before(start) && t.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// sessions returns the regular sessions, plus pre- and post-market when
+// extended is set. A closed market has none.
+func (m MarketHour) sessions(extended bool) []*StartEndTime {
+	if !m.IsOpen || m.SessionHours == nil {
+		return nil
+	}
+	h := m.SessionHours
+	if extended {
+		return slices.Concat(h.PreMarket, h.RegularMarket, h.PostMarket)
+	}
+	return h.RegularMarket
+}
+
 // SessionHours represents market session hours
 type SessionHours struct {
+	PreMarket       []*StartEndTime    `json:"preMarket,omitempty"`
+	RegularMarket   []*StartEndTime    `json:"regularMarket,omitempty"`
+	PostMarket      []*StartEndTime    `json:"postMarket,omitempty"`
 	SessionDuration []*SessionDuration `json:"sessionDuration,omitempty"`
 	StartEndTime    []*StartEndTime    `json:"startEndTime,omitempty"`
 }
@@ -645,16 +1177,56 @@ type StartEndTime struct {
 	End   string `json:"end"`
 }
 
+// bounds parses the session's start and end. Schwab sends RFC 3339 times
+// with an offset; bare "15:04:05" times are taken to be Eastern time on date.
+func (se *StartEndTime) bounds(date string) (start, end time.Time, ok bool) {
+	if se == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	start, err1 := parseSessionTime(se.Start, date)
+	end, err2 := parseSessionTime(se.End, date)
+	return start, end, err1 == nil && err2 == nil
+}
+
+// marketLocation is the time zone of bare session times.
+var marketLocation = sync.OnceValues(func() (*time.Location, error) {
+	return time.LoadLocation("America/New_York")
+})
+
+func parseSessionTime(value, date string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	loc, err := marketLocation()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.ParseInLocation(time.DateOnly+" "+time.TimeOnly, date+" "+value, loc)
+}
+
 // InstrumentsResponse is the response for GET /marketdata/v1/instruments
 type InstrumentsResponse []InstrumentSearch
 
 // InstrumentSearch represents an instrument search result
 type InstrumentSearch struct {
-	Symbol      string `json:"symbol"`
-	Description string `json:"description"`
-	AssetType   string `json:"assetType"`
-	Cusip       string `json:"cusip"`
-	Exchange    string `json:"exchange"`
+	Symbol      string                 `json:"symbol"`
+	Description string                 `json:"description"`
+	AssetType   string                 `json:"assetType"`
+	Cusip       string                 `json:"cusip"`
+	Exchange    string                 `json:"exchange"`
+	Fundamental *InstrumentFundamental `json:"fundamental,omitempty"` // only with ProjectionFundamental
+}
+
+// InstrumentFundamental is the fundamental data returned for an instrument
+// searched with ProjectionFundamental
+type InstrumentFundamental struct {
+	Symbol        string  `json:"symbol"`
+	PeRatio       float64 `json:"peRatio"`
+	Eps           float64 `json:"eps"`
+	DividendYield float64 `json:"dividendYield"`
+	MarketCap     float64 `json:"marketCap"`
+	High52        float64 `json:"high52"`
+	Low52         float64 `json:"low52"`
 }
 
 // InstrumentCUSIPResponse is the response for GET /marketdata/v1/instruments/{cusip_id}
@@ -676,16 +1248,21 @@ type InstrumentCUSIP struct {
 // ============================================================================
 
 // OrderRequest represents an order request for place_order and replace_order
+//
+// Composite orders set OrderStrategyType to "OCO" (the children cancel each
+// other; the parent carries no legs) or "TRIGGER" (the children are placed
+// once the parent fills) and list the child orders in ChildOrderStrategies.
 type OrderRequest struct {
-	OrderType                string             `json:"orderType"`
-	Session                  string             `json:"session"`
-	Duration                 string             `json:"duration"`
+	OrderType                string             `json:"orderType,omitempty"`
+	Session                  string             `json:"session,omitempty"`
+	Duration                 string             `json:"duration,omitempty"`
 	OrderStrategyType        string             `json:"orderStrategyType"`
 	Price                    string             `json:"price,omitempty"`
 	StopPrice                string             `json:"stopPrice,omitempty"`
-	OrderLegCollection       []*OrderLegRequest `json:"orderLegCollection"`
+	OrderLegCollection       []*OrderLegRequest `json:"orderLegCollection,omitempty"`
 	ComplexOrderStrategyType string             `json:"complexOrderStrategyType,omitempty"`
 	Quantity                 float64            `json:"quantity,omitempty"`
+	ChildOrderStrategies     []*OrderRequest    `json:"childOrderStrategies,omitempty"`
 }
 
 // OrderLegRequest represents a leg in an order request
@@ -703,3 +1280,358 @@ type InstrumentRequest struct {
 
 // PreviewOrderRequest represents a preview order request
 type PreviewOrderRequest OrderRequest
+
+// ============================================================================
+// STREAMER MESSAGE TYPES
+// ============================================================================
+
+// Subscription is a single streamer request as passed to
+// Streamer.SendBatch.
+type Subscription struct {
+	Service string   // e.g. "LEVELONE_EQUITIES"
+	Command string   // "ADD", "SUBS", "UNSUBS" or "VIEW"; empty means "ADD"
+	Keys    []string // symbols or other service keys
+	Fields  []string // field indices as strings ("0", "1", ...)
+}
+
+// StreamMessage is a single frame received from the Schwab streamer. Each
+// frame carries command acknowledgements (Response), keepalive notices
+// (Notify), or subscription updates (Data).
+type StreamMessage struct {
+	Response []StreamResponse `json:"response,omitempty"`
+	Notify   []StreamNotify   `json:"notify,omitempty"`
+	Data     []StreamData     `json:"data,omitempty"`
+}
+
+// StreamResponse acknowledges a request sent to the streamer.
+type StreamResponse struct {
+	Service              string                `json:"service"`
+	Command              string                `json:"command"`
+	RequestID            json.Number           `json:"requestid"`
+	SchwabClientCorrelID string                `json:"SchwabClientCorrelId,omitempty"`
+	Timestamp            int64                 `json:"timestamp"`
+	Content              StreamResponseContent `json:"content"`
+}
+
+// StreamResponseContent is the outcome of a streamer request. Code 0 means
+// success; Msg describes the result.
+type StreamResponseContent struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg,omitempty"`
+}
+
+// StreamNotify is a keepalive notice sent periodically by the streamer.
+type StreamNotify struct {
+	Heartbeat string `json:"heartbeat,omitempty"`
+}
+
+// StreamData carries subscription updates for one service. Content entries
+// are keyed by field index ("0", "1", …) plus "key" for the symbol.
+type StreamData struct {
+	Service   string           `json:"service"`
+	Timestamp int64            `json:"timestamp"`
+	Command   string           `json:"command"`
+	Content   []map[string]any `json:"content"`
+}
+
+// LevelOneEquity is a decoded LEVELONE_EQUITIES update. The stream tags give
+// each field's index in StreamFields["LEVELONE_EQUITIES"]. Schwab sends only
+// the fields that changed, so absent fields are left at their zero value.
+type LevelOneEquity struct {
+	Symbol                     string  `stream:"key"`
+	Delayed                    bool    `stream:"delayed"`
+	BidPrice                   float64 `stream:"1"`
+	AskPrice                   float64 `stream:"2"`
+	LastPrice                  float64 `stream:"3"`
+	BidSize                    int64   `stream:"4"`
+	AskSize                    int64   `stream:"5"`
+	AskID                      string  `stream:"6"`
+	BidID                      string  `stream:"7"`
+	TotalVolume                int64   `stream:"8"`
+	LastSize                   int64   `stream:"9"`
+	HighPrice                  float64 `stream:"10"`
+	LowPrice                   float64 `stream:"11"`
+	ClosePrice                 float64 `stream:"12"`
+	ExchangeID                 string  `stream:"13"`
+	Marginable                 bool    `stream:"14"`
+	Description                string  `stream:"15"`
+	LastID                     string  `stream:"16"`
+	OpenPrice                  float64 `stream:"17"`
+	NetChange                  float64 `stream:"18"`
+	High52Week                 float64 `stream:"19"`
+	Low52Week                  float64 `stream:"20"`
+	PERatio                    float64 `stream:"21"`
+	AnnualDividendAmount       float64 `stream:"22"`
+	DividendYield              float64 `stream:"23"`
+	NAV                        float64 `stream:"24"`
+	ExchangeName               string  `stream:"25"`
+	DividendDate               string  `stream:"26"`
+	RegularMarketQuote         bool    `stream:"27"`
+	RegularMarketTrade         bool    `stream:"28"`
+	RegularMarketLastPrice     float64 `stream:"29"`
+	RegularMarketLastSize      int64   `stream:"30"`
+	RegularMarketNetChange     float64 `stream:"31"`
+	SecurityStatus             string  `stream:"32"`
+	MarkPrice                  float64 `stream:"33"`
+	QuoteTime                  int64   `stream:"34"`
+	TradeTime                  int64   `stream:"35"`
+	RegularMarketTradeTime     int64   `stream:"36"`
+	BidTime                    int64   `stream:"37"`
+	AskTime                    int64   `stream:"38"`
+	AskMICID                   string  `stream:"39"`
+	BidMICID                   string  `stream:"40"`
+	LastMICID                  string  `stream:"41"`
+	NetPercentChange           float64 `stream:"42"`
+	RegularMarketPercentChange float64 `stream:"43"`
+	MarkPriceNetChange         float64 `stream:"44"`
+	MarkPricePercentChange     float64 `stream:"45"`
+	HardToBorrowQuantity       int64   `stream:"46"`
+	HardToBorrowRate           float64 `stream:"47"`
+	HardToBorrow               int64   `stream:"48"`
+	Shortable                  int64   `stream:"49"`
+	PostMarketNetChange        float64 `stream:"50"`
+	PostMarketPercentChange    float64 `stream:"51"`
+}
+
+// Quote converts the update to the Quote shape returned by the quotes
+// endpoints, so streamed and REST quotes can be handled alike. Fields with no
+// streamed counterpart, such as the CUSIP, are left empty.
+func (q *LevelOneEquity) Quote() *Quote {
+	return &Quote{
+		AssetMainType: "EQUITY",
+		Symbol:        q.Symbol,
+		Realtime:      !q.Delayed,
+		QuoteData: &QuoteData{
+			FiftyTwoWeekHigh:        q.High52Week,
+			FiftyTwoWeekLow:         q.Low52Week,
+			AskMICId:                q.AskMICID,
+			AskPrice:                q.AskPrice,
+			AskSize:                 int(q.AskSize),
+			AskTime:                 q.AskTime,
+			BidMICId:                q.BidMICID,
+			BidPrice:                q.BidPrice,
+			BidSize:                 int(q.BidSize),
+			BidTime:                 q.BidTime,
+			ClosePrice:              q.ClosePrice,
+			HighPrice:               q.HighPrice,
+			LastMICId:               q.LastMICID,
+			LastPrice:               q.LastPrice,
+			LastSize:                int(q.LastSize),
+			LowPrice:                q.LowPrice,
+			Mark:                    q.MarkPrice,
+			MarkChange:              q.MarkPriceNetChange,
+			MarkPercentChange:       q.MarkPricePercentChange,
+			NetChange:               q.NetChange,
+			NetPercentChange:        q.NetPercentChange,
+			OpenPrice:               q.OpenPrice,
+			PostMarketChange:        q.PostMarketNetChange,
+			PostMarketPercentChange: q.PostMarketPercentChange,
+			QuoteTime:               q.QuoteTime,
+			SecurityStatus:          q.SecurityStatus,
+			TotalVolume:             q.TotalVolume,
+			TradeTime:               q.TradeTime,
+		},
+		Fundamental: &Fundamental{
+			DivAmount: q.AnnualDividendAmount,
+			DivYield:  q.DividendYield,
+			PeRatio:   q.PERatio,
+		},
+		Reference: &Reference{
+			Description:    q.Description,
+			Exchange:       q.ExchangeID,
+			ExchangeName:   q.ExchangeName,
+			IsHardToBorrow: q.HardToBorrow == 1,
+			IsShortable:    q.Shortable == 1,
+			HtbQuantity:    q.HardToBorrowQuantity,
+			HtbRate:        q.HardToBorrowRate,
+		},
+		Regular: &Regular{
+			RegularMarketLastPrice:     q.RegularMarketLastPrice,
+			RegularMarketLastSize:      q.RegularMarketLastSize,
+			RegularMarketNetChange:     q.RegularMarketNetChange,
+			RegularMarketPercentChange: q.RegularMarketPercentChange,
+			RegularMarketTradeTime:     q.RegularMarketTradeTime,
+		},
+	}
+}
+
+// LevelOneFutures is a decoded LEVELONE_FUTURES update. The stream tags give
+// each field's index in StreamFields["LEVELONE_FUTURES"].
+type LevelOneFutures struct {
+	Symbol              string  `stream:"key"`
+	Delayed             bool    `stream:"delayed"`
+	BidPrice            float64 `stream:"1"`
+	AskPrice            float64 `stream:"2"`
+	LastPrice           float64 `stream:"3"`
+	BidSize             int64   `stream:"4"`
+	AskSize             int64   `stream:"5"`
+	BidID               string  `stream:"6"`
+	AskID               string  `stream:"7"`
+	TotalVolume         int64   `stream:"8"`
+	LastSize            int64   `stream:"9"`
+	QuoteTime           int64   `stream:"10"`
+	TradeTime           int64   `stream:"11"`
+	HighPrice           float64 `stream:"12"`
+	LowPrice            float64 `stream:"13"`
+	ClosePrice          float64 `stream:"14"`
+	ExchangeID          string  `stream:"15"`
+	Description         string  `stream:"16"`
+	LastID              string  `stream:"17"`
+	OpenPrice           float64 `stream:"18"`
+	NetChange           float64 `stream:"19"`
+	FuturePercentChange float64 `stream:"20"`
+	ExchangeName        string  `stream:"21"`
+	SecurityStatus      string  `stream:"22"`
+	OpenInterest        int64   `stream:"23"`
+	Mark                float64 `stream:"24"`
+	Tick                float64 `stream:"25"`
+	TickAmount          float64 `stream:"26"`
+	Product             string  `stream:"27"`
+	PriceFormat         string  `stream:"28"`
+	TradingHours        string  `stream:"29"`
+	IsTradable          bool    `stream:"30"`
+	Multiplier          float64 `stream:"31"`
+	IsActive            bool    `stream:"32"`
+	SettlementPrice     float64 `stream:"33"`
+	ActiveSymbol        string  `stream:"34"`
+	ExpirationDate      int64   `stream:"35"`
+	ExpirationStyle     string  `stream:"36"`
+	AskTime             int64   `stream:"37"`
+	BidTime             int64   `stream:"38"`
+	QuotedInSession     bool    `stream:"39"`
+	SettlementDate      int64   `stream:"40"`
+}
+
+// LevelOneFuturesOption is a decoded LEVELONE_FUTURES_OPTIONS update. The
+// stream tags give each field's index in
+// StreamFields["LEVELONE_FUTURES_OPTIONS"].
+type LevelOneFuturesOption struct {
+	Symbol           string  `stream:"key"`
+	Delayed          bool    `stream:"delayed"`
+	BidPrice         float64 `stream:"1"`
+	AskPrice         float64 `stream:"2"`
+	LastPrice        float64 `stream:"3"`
+	BidSize          int64   `stream:"4"`
+	AskSize          int64   `stream:"5"`
+	BidID            string  `stream:"6"`
+	AskID            string  `stream:"7"`
+	TotalVolume      int64   `stream:"8"`
+	LastSize         int64   `stream:"9"`
+	QuoteTime        int64   `stream:"10"`
+	TradeTime        int64   `stream:"11"`
+	HighPrice        float64 `stream:"12"`
+	LowPrice         float64 `stream:"13"`
+	ClosePrice       float64 `stream:"14"`
+	LastID           string  `stream:"15"`
+	Description      string  `stream:"16"`
+	OpenPrice        float64 `stream:"17"`
+	OpenInterest     int64   `stream:"18"`
+	Mark             float64 `stream:"19"`
+	Tick             float64 `stream:"20"`
+	TickAmount       float64 `stream:"21"`
+	Multiplier       float64 `stream:"22"`
+	SettlementPrice  float64 `stream:"23"`
+	UnderlyingSymbol string  `stream:"24"`
+	StrikePrice      float64 `stream:"25"`
+	ExpirationDate   int64   `stream:"26"`
+	ExpirationStyle  string  `stream:"27"`
+	ContractType     string  `stream:"28"`
+	SecurityStatus   string  `stream:"29"`
+	Exchange         string  `stream:"30"`
+	ExchangeName     string  `stream:"31"`
+}
+
+// LevelOneForex is a decoded LEVELONE_FOREX update. The stream tags give each
+// field's index in StreamFields["LEVELONE_FOREX"].
+type LevelOneForex struct {
+	Symbol         string  `stream:"key"`
+	Delayed        bool    `stream:"delayed"`
+	BidPrice       float64 `stream:"1"`
+	AskPrice       float64 `stream:"2"`
+	LastPrice      float64 `stream:"3"`
+	BidSize        int64   `stream:"4"`
+	AskSize        int64   `stream:"5"`
+	TotalVolume    int64   `stream:"6"`
+	LastSize       int64   `stream:"7"`
+	QuoteTime      int64   `stream:"8"`
+	TradeTime      int64   `stream:"9"`
+	HighPrice      float64 `stream:"10"`
+	LowPrice       float64 `stream:"11"`
+	ClosePrice     float64 `stream:"12"`
+	Exchange       string  `stream:"13"`
+	Description    string  `stream:"14"`
+	OpenPrice      float64 `stream:"15"`
+	NetChange      float64 `stream:"16"`
+	PercentChange  float64 `stream:"17"`
+	ExchangeName   string  `stream:"18"`
+	Digits         int64   `stream:"19"`
+	SecurityStatus string  `stream:"20"`
+	Tick           float64 `stream:"21"`
+	TickAmount     float64 `stream:"22"`
+	Product        string  `stream:"23"`
+	TradingHours   string  `stream:"24"`
+	IsTradable     bool    `stream:"25"`
+	MarketMaker    string  `stream:"26"`
+	High52Week     float64 `stream:"27"`
+	Low52Week      float64 `stream:"28"`
+	Mark           float64 `stream:"29"`
+}
+
+// OrderBook is a decoded NYSE_BOOK, NASDAQ_BOOK or OPTIONS_BOOK update. Bids
+// are ordered best (highest) price first and asks best (lowest) price first.
+type OrderBook struct {
+	Symbol string      `stream:"key"`
+	Time   int64       `stream:"1"` // market snapshot time, epoch ms
+	Bids   []BookLevel // stream field 2
+	Asks   []BookLevel // stream field 3
+}
+
+// BookLevel is one price level of an OrderBook.
+type BookLevel struct {
+	Price            float64           `stream:"0"`
+	Size             int64             `stream:"1"`
+	MarketMakerCount int64             `stream:"2"`
+	MarketMakers     []BookMarketMaker // stream field 3
+}
+
+// BookMarketMaker is one market maker's quote at a BookLevel.
+type BookMarketMaker struct {
+	ID        string `stream:"0"`
+	Size      int64  `stream:"1"`
+	QuoteTime int64  `stream:"2"`
+}
+
+// AccountActivity is a decoded ACCT_ACTIVITY update. Data holds the raw
+// message data; for order events it is also parsed into Order.
+type AccountActivity struct {
+	Sequence int64               `stream:"seq"`
+	Key      string              `stream:"key"`
+	Account  string              `stream:"1"`
+	Type     AccountActivityType `stream:"2"`
+	Data     string              `stream:"3"`
+	Order    *ActivityOrder      // nil unless Data is an order event
+}
+
+// ActivityOrder is the order detail of an ACCT_ACTIVITY order event. Fill is
+// set for fills and Cancel for cancellations.
+type ActivityOrder struct {
+	OrderID       string
+	AccountNumber string
+	EventType     string
+	Fill          *ActivityFill
+	Cancel        *ActivityCancel
+}
+
+// ActivityFill describes one execution of an order.
+type ActivityFill struct {
+	LegID    string
+	Quantity float64
+	Price    float64
+	Time     string // as sent by Schwab, e.g. "2026-10-15T14:30:00.123Z"
+}
+
+// ActivityCancel describes the quantity removed from an order.
+type ActivityCancel struct {
+	Quantity float64
+	Type     string // e.g. "FULL"
+}
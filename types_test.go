@@ -1,8 +1,14 @@
 package schwabdev_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"testing"
+	"time"
 
 	schwabdev "github.com/citizenadam/go-schwabapi"
 )
@@ -157,6 +163,95 @@ func TestAccountDetailsAllResponse_UnmarshalFromAPI(t *testing.T) {
 	}
 }
 
+func TestAccountDetailsResponse_DecodesPositionsAndBalances(t *testing.T) {
+	raw := `{
+		"securitiesAccount": {
+			"type": "MARGIN",
+			"accountNumber": "12345678",
+			"roundTrips": 0,
+			"isDayTrader": false,
+			"isClosingOnlyRestricted": false,
+			"pfcbFlag": false,
+			"positions": [{
+				"shortQuantity": 0,
+				"averagePrice": 171.2345,
+				"currentDayProfitLoss": 42.5,
+				"currentDayProfitLossPercentage": 0.25,
+				"longQuantity": 100,
+				"settledLongQuantity": 100,
+				"settledShortQuantity": 0,
+				"instrument": {
+					"assetType": "EQUITY",
+					"cusip": "037833100",
+					"symbol": "AAPL",
+					"netChange": 0.425
+				},
+				"marketValue": 17550.0,
+				"maintenanceRequirement": 5265.0,
+				"averageLongPrice": 171.2345,
+				"taxLotAverageLongPrice": 171.2345,
+				"longOpenProfitLoss": 426.55,
+				"previousSessionLongQuantity": 100,
+				"currentDayCost": 0
+			}, {
+				"shortQuantity": 0,
+				"averagePrice": 3.1,
+				"longQuantity": 2,
+				"instrument": {
+					"assetType": "OPTION",
+					"cusip": "0AAPL.FK40180000",
+					"symbol": "AAPL  240621C00180000",
+					"description": "APPLE INC 06/21/2024 $180 Call",
+					"type": "VANILLA",
+					"putCall": "CALL",
+					"underlyingSymbol": "AAPL"
+				},
+				"marketValue": 540.0
+			}],
+			"initialBalances": {
+				"cashBalance": 2500.0,
+				"buyingPower": 10000.0,
+				"accountValue": 20550.0
+			},
+			"currentBalances": {
+				"cashBalance": 2400.0,
+				"buyingPower": 9800.0,
+				"longMarketValue": 18090.0,
+				"equity": 20490.0,
+				"maintenanceRequirement": 5265.0
+			}
+		}
+	}`
+	got := mustUnmarshal[schwabdev.AccountDetailsResponse](t, raw)
+	acct := got.SecuritiesAccount
+	if acct == nil || len(acct.Positions) != 2 {
+		t.Fatalf("want 2 positions, got %+v", acct)
+	}
+
+	stock := acct.Positions[0]
+	if stock.Instrument == nil || stock.Instrument.Symbol != "AAPL" || stock.Instrument.Cusip != "037833100" {
+		t.Errorf("stock instrument: %+v", stock.Instrument)
+	}
+	if stock.LongQuantity != 100 || stock.AveragePrice != 171.2345 || stock.MarketValue != 17550 {
+		t.Errorf("stock quantities: %+v", stock)
+	}
+	if stock.LongOpenProfitLoss != 426.55 || stock.CurrentDayProfitLoss != 42.5 || stock.MaintenanceRequirement != 5265 {
+		t.Errorf("stock P/L: %+v", stock)
+	}
+
+	option := acct.Positions[1].Instrument
+	if option == nil || option.PutCall != "CALL" || option.UnderlyingSymbol != "AAPL" {
+		t.Errorf("option instrument: %+v", option)
+	}
+
+	if acct.InitialBalances == nil || acct.InitialBalances.AccountValue != 20550 {
+		t.Errorf("InitialBalances: %+v", acct.InitialBalances)
+	}
+	if acct.CurrentBalances == nil || acct.CurrentBalances.BuyingPower != 9800 || acct.CurrentBalances.LongMarketValue != 18090 {
+		t.Errorf("CurrentBalances: %+v", acct.CurrentBalances)
+	}
+}
+
 func TestAccountDetailsAllResponse_NilOptionals(t *testing.T) {
 	// Ensure optional pointer fields decode as nil when absent.
 	raw := `{"securitiesAccount": {"type": "CASH", "accountNumber": "X", "roundTrips": 0}}`
@@ -314,6 +409,29 @@ func TestAccountOrdersResponse_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestOrderLister(t *testing.T) {
+	single := mustUnmarshal[schwabdev.AccountOrdersResponse](t, `[{"orderId":1,"status":"WORKING"},{"orderId":2,"status":"FILLED"}]`)
+	all := mustUnmarshal[schwabdev.AccountOrdersAllResponse](t, `[{"orderId":3,"status":"QUEUED"}]`)
+
+	for _, tt := range []struct {
+		name string
+		list schwabdev.OrderLister
+		want []int64
+	}{
+		{"AccountOrdersResponse", single, []int64{1, 2}},
+		{"AccountOrdersAllResponse", all, []int64{3}},
+		{"pointer", &single, []int64{1, 2}},
+	} {
+		var ids []int64
+		for _, o := range tt.list.OrderList() {
+			ids = append(ids, o.OrderID)
+		}
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("%s: OrderList IDs = %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
+
 // ── Transactions ──────────────────────────────────────────────────────────────
 
 func TestTransaction_RoundTrip(t *testing.T) {
@@ -352,6 +470,42 @@ func TestTransactionsResponse_UnmarshalFromAPI(t *testing.T) {
 	}
 }
 
+func TestTransactionsResponse_Filters(t *testing.T) {
+	raw := `[
+		{"transactionId": "T1", "type": "TRADE", "symbol": "NVDA", "netAmount": -5001.50},
+		{"transactionId": "T2", "type": "DIVIDEND_OR_INTEREST", "symbol": "MSFT", "netAmount": 25.00},
+		{"transactionId": "T3", "type": "TRADE", "symbol": "msft", "netAmount": 4100.25},
+		{"transactionId": "T4", "type": "ACH_RECEIPT", "netAmount": 1000}
+	]`
+	txs := mustUnmarshal[schwabdev.TransactionsResponse](t, raw)
+
+	ids := func(txs []schwabdev.Transaction) []string {
+		var out []string
+		for _, tx := range txs {
+			out = append(out, tx.TransactionID)
+		}
+		return out
+	}
+	if got := ids(txs.ByType("trade")); !slices.Equal(got, []string{"T1", "T3"}) {
+		t.Errorf("ByType(trade) = %v", got)
+	}
+	if got := ids(txs.BySymbol(" MSFT")); !slices.Equal(got, []string{"T2", "T3"}) {
+		t.Errorf("BySymbol(MSFT) = %v", got)
+	}
+	if got := txs.ByType("JOURNAL"); got != nil {
+		t.Errorf("ByType(JOURNAL) = %v, want nil", got)
+	}
+	if got := txs.TotalAmount(); math.Abs(got-123.75) > 1e-9 {
+		t.Errorf("TotalAmount = %v, want 123.75", got)
+	}
+
+	// A malformed amount is rejected when the response is decoded.
+	var bad schwabdev.TransactionsResponse
+	if err := json.Unmarshal([]byte(`[{"transactionId": "T5", "netAmount": "12,50"}]`), &bad); err == nil {
+		t.Error("want an error decoding a malformed netAmount")
+	}
+}
+
 // ── Quotes ────────────────────────────────────────────────────────────────────
 
 func TestQuote_RoundTrip(t *testing.T) {
@@ -495,6 +649,70 @@ func TestQuoteData_52WeekFieldNames(t *testing.T) {
 	}
 }
 
+func TestQuotesResponse_Get_MixedErrors(t *testing.T) {
+	raw := `{
+		"AAPL": {"assetMainType":"EQUITY","symbol":"AAPL","quote":{"lastPrice":190.14}},
+		"BOGUS": {"description":"Symbol not found"},
+		"errors": {"invalidSymbols":["XYZ1"]}
+	}`
+	got := mustUnmarshal[schwabdev.QuotesResponse](t, raw)
+
+	q, err := got.Get("AAPL")
+	if err != nil {
+		t.Fatalf("Get(AAPL): %v", err)
+	}
+	if q.QuoteData == nil || q.QuoteData.LastPrice != 190.14 {
+		t.Errorf("AAPL quote: %+v", q)
+	}
+
+	for _, sym := range []string{"BOGUS", "XYZ1", "MISSING"} {
+		_, err := got.Get(sym)
+		var qErr *schwabdev.QuoteError
+		if !errors.As(err, &qErr) || qErr.Symbol != sym {
+			t.Errorf("Get(%s): want *QuoteError, got %v", sym, err)
+		}
+	}
+
+	errs := got.Errors()
+	if len(errs) != 2 || errs["BOGUS"].Reason != "Symbol not found" || errs["XYZ1"] == nil {
+		t.Errorf("Errors: %v", errs)
+	}
+	if _, ok := got["errors"]; ok {
+		t.Error(`"errors" should not decode as a quote`)
+	}
+}
+
+func TestQuotesResponse_WriteCSV(t *testing.T) {
+	quotes := mustUnmarshal[schwabdev.QuotesResponse](t, `{
+		"MSFT": {"assetMainType":"EQUITY","symbol":"MSFT","quote":{"lastPrice":415.5,"bidPrice":415.49,"askPrice":415.52,"totalVolume":18234100,"netChange":-2.25,"netPercentChange":-0.5386}},
+		"AAPL": {"assetMainType":"EQUITY","symbol":"AAPL","quote":{"lastPrice":190.14,"bidPrice":190.1,"askPrice":190.15,"totalVolume":52011000,"netChange":1.2,"netPercentChange":0.635}},
+		"SPY":  {"assetMainType":"EQUITY","symbol":"SPY"},
+		"errors": {"invalidSymbols":["BOGUS"]}
+	}`)
+
+	var buf bytes.Buffer
+	if err := quotes.WriteCSV(&buf, []string{"MSFT", "BOGUS", "SPY", "MISSING", "AAPL"}); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	const golden = "symbol,last,bid,ask,volume,change,percentChange\n" +
+		"MSFT,415.5,415.49,415.52,18234100,-2.25,-0.5386\n" +
+		"AAPL,190.14,190.1,190.15,52011000,1.2,0.635\n"
+	if buf.String() != golden {
+		t.Errorf("WriteCSV =\n%s\nwant\n%s", buf.String(), golden)
+	}
+
+	buf.Reset()
+	if err := quotes.WriteCSV(&buf, nil); err != nil {
+		t.Fatalf("WriteCSV(nil): %v", err)
+	}
+	const sorted = "symbol,last,bid,ask,volume,change,percentChange\n" +
+		"AAPL,190.14,190.1,190.15,52011000,1.2,0.635\n" +
+		"MSFT,415.5,415.49,415.52,18234100,-2.25,-0.5386\n"
+	if buf.String() != sorted {
+		t.Errorf("WriteCSV(nil) =\n%s\nwant\n%s", buf.String(), sorted)
+	}
+}
+
 // ── Option Chains ─────────────────────────────────────────────────────────────
 
 func TestOptionChainsResponse_RoundTrip(t *testing.T) {
@@ -636,6 +854,156 @@ func TestOptionExpirationChainResponse_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestOptionSymbol_RoundTrip(t *testing.T) {
+	tests := []struct {
+		root    string
+		expiry  time.Time
+		callPut rune
+		strike  float64
+		want    string
+	}{
+		{"AAPL", time.Date(2024, 8, 9, 0, 0, 0, 0, time.UTC), 'C', 95, "AAPL  240809C00095000"},
+		{"SPXW", time.Date(2025, 12, 19, 0, 0, 0, 0, time.UTC), 'P', 5432.5, "SPXW  251219P05432500"},
+		{"F", time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC), 'P', 0.5, "F     260116P00000500"},
+		{"GOOGL", time.Date(2030, 6, 21, 0, 0, 0, 0, time.UTC), 'C', 0.001, "GOOGL 300621C00000001"},
+		{"BRKB1", time.Date(2027, 3, 19, 0, 0, 0, 0, time.UTC), 'C', 99999.999, "BRKB1 270319C99999999"},
+		{"QQQQQQ", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), 'P', 12.125, "QQQQQQ240229P00012125"},
+	}
+	for _, tt := range tests {
+		got := schwabdev.BuildOptionSymbol(tt.root, tt.expiry, tt.callPut, tt.strike)
+		if got != tt.want {
+			t.Errorf("BuildOptionSymbol(%s, %v) = %q, want %q", tt.root, tt.strike, got, tt.want)
+		}
+		root, expiry, callPut, strike, err := schwabdev.ParseOptionSymbol(got)
+		if err != nil {
+			t.Fatalf("ParseOptionSymbol(%q): %v", got, err)
+		}
+		if root != tt.root || !expiry.Equal(tt.expiry) || callPut != tt.callPut || strike != tt.strike {
+			t.Errorf("ParseOptionSymbol(%q) = %s %v %c %v", got, root, expiry, callPut, strike)
+		}
+	}
+
+	// Lowercase input, a time of day and float noise are normalised.
+	expiry := time.Date(2024, 8, 9, 15, 30, 0, 0, time.UTC)
+	if got := schwabdev.BuildOptionSymbol("aapl", expiry, 'c', 0.1+0.2); got != "AAPL  240809C00000300" {
+		t.Errorf("BuildOptionSymbol normalising = %q", got)
+	}
+	if root, _, _, strike, err := schwabdev.ParseOptionSymbol("AAPL240809C00095000"); err != nil || root != "AAPL" || strike != 95 {
+		t.Errorf("unpadded symbol = %s %v %v", root, strike, err)
+	}
+}
+
+func TestParseOptionSymbol_Rejects(t *testing.T) {
+	for _, sym := range []string{
+		"",
+		"AAPL",
+		"240809C00095000",
+		"AAPL  241309C00095000",
+		"AAPL  240809X00095000",
+		"AAPL  240809C0009500A",
+		"AAPL  240809C+0095000",
+		"TOOLONG240809C00095000",
+		"AA PL 240809C00095000",
+	} {
+		if _, _, _, _, err := schwabdev.ParseOptionSymbol(sym); !errors.Is(err, schwabdev.ErrInvalidParameter) {
+			t.Errorf("ParseOptionSymbol(%q): want ErrInvalidParameter, got %v", sym, err)
+		}
+	}
+}
+
+func TestOptionChainsResponse_ContractsAndExpirations(t *testing.T) {
+	contract := func(putCall string, strike float64) schwabdev.OptionContract {
+		return schwabdev.OptionContract{PutCall: putCall, StrikePrice: strike}
+	}
+	resp := schwabdev.OptionChainsResponse{
+		CallExpDateMap: map[string]map[string][]schwabdev.OptionContract{
+			"2024-07-19:33": {
+				"190.0": {contract("CALL", 190)},
+				"180.0": {contract("CALL", 180)},
+			},
+			"2024-06-21:5": {
+				"200.0": {contract("CALL", 200)},
+				"95.0":  {contract("CALL", 95)},
+			},
+		},
+		PutExpDateMap: map[string]map[string][]schwabdev.OptionContract{
+			"2024-06-21:5": {
+				"95.0": {contract("PUT", 95)},
+			},
+		},
+	}
+
+	var got []string
+	for _, c := range resp.Contracts("ALL") {
+		got = append(got, fmt.Sprintf("%s %g", c.PutCall, c.StrikePrice))
+	}
+	want := []string{"CALL 95", "PUT 95", "CALL 200", "CALL 180", "CALL 190"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Contracts(ALL):\n got %v\nwant %v", got, want)
+	}
+
+	if puts := resp.Contracts("put"); len(puts) != 1 || puts[0].PutCall != "PUT" {
+		t.Errorf("Contracts(put): %v", puts)
+	}
+	if calls := resp.Contracts("CALL"); len(calls) != 4 {
+		t.Errorf("Contracts(CALL): want 4, got %d", len(calls))
+	}
+
+	if dates := resp.ExpirationDates(); !slices.Equal(dates, []string{"2024-06-21", "2024-07-19"}) {
+		t.Errorf("ExpirationDates: %v", dates)
+	}
+}
+
+func TestOptionChainsResponse_NearTheMoneyAndAtStrike(t *testing.T) {
+	contract := func(putCall string, strike float64, itm bool) schwabdev.OptionContract {
+		return schwabdev.OptionContract{PutCall: putCall, StrikePrice: strike, InTheMoney: itm}
+	}
+	// Underlying around 187: calls below and puts above are in the money.
+	resp := schwabdev.OptionChainsResponse{
+		UnderlyingPrice: 187,
+		CallExpDateMap: map[string]map[string][]schwabdev.OptionContract{
+			"2024-06-21:5": {
+				"180.0": {contract("CALL", 180, true)},
+				"185.0": {contract("CALL", 185, true)},
+				"190.0": {contract("CALL", 190, false)},
+				"195.0": {contract("CALL", 195, false)},
+			},
+			"2024-07-19:33": {
+				"150.0": {contract("CALL", 150, true)},
+				"160.0": {contract("CALL", 160, true)},
+			},
+		},
+		PutExpDateMap: map[string]map[string][]schwabdev.OptionContract{
+			"2024-06-21:5": {
+				"180.0": {contract("PUT", 180, false)},
+				"185.0": {contract("PUT", 185, false)},
+				"190.0": {contract("PUT", 190, true)},
+				"195.0": {contract("PUT", 195, true)},
+			},
+		},
+	}
+
+	describe := func(cs []*schwabdev.OptionContract) []string {
+		var out []string
+		for _, c := range cs {
+			out = append(out, fmt.Sprintf("%s %g", c.PutCall, c.StrikePrice))
+		}
+		return out
+	}
+
+	want := []string{"CALL 185", "PUT 185", "CALL 190", "PUT 190"}
+	if got := describe(resp.NearTheMoney()); !slices.Equal(got, want) {
+		t.Errorf("NearTheMoney:\n got %v\nwant %v", got, want)
+	}
+
+	if got := describe(resp.AtStrike(180)); !slices.Equal(got, []string{"CALL 180", "PUT 180"}) {
+		t.Errorf("AtStrike(180): %v", got)
+	}
+	if got := resp.AtStrike(187.5); len(got) != 0 {
+		t.Errorf("AtStrike(187.5): want none, got %v", describe(got))
+	}
+}
+
 // ── Price History ─────────────────────────────────────────────────────────────
 
 func TestPriceHistoryResponse_RoundTrip(t *testing.T) {
@@ -697,6 +1065,106 @@ func TestPriceHistoryResponse_Empty(t *testing.T) {
 	}
 }
 
+func TestCandle_Time(t *testing.T) {
+	c := schwabdev.Candle{Datetime: 1705622400123}
+	want := time.Date(2024, 1, 19, 0, 0, 0, 123*int(time.Millisecond), time.UTC)
+	if got := c.Time(); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Time: want %v, got %v", want, got)
+	}
+}
+
+func TestPriceHistoryResponse_TimeRange(t *testing.T) {
+	resp := mustUnmarshal[schwabdev.PriceHistoryResponse](t,
+		`{"candles":[null,{"datetime":1705708800000},{"datetime":1705622400000},null,{"datetime":1705795200000}]}`)
+	start, end := resp.TimeRange()
+	if want := time.UnixMilli(1705622400000).UTC(); !start.Equal(want) {
+		t.Errorf("start: want %v, got %v", want, start)
+	}
+	if want := time.UnixMilli(1705795200000).UTC(); !end.Equal(want) {
+		t.Errorf("end: want %v, got %v", want, end)
+	}
+}
+
+func TestPriceHistoryResponse_TimeRange_Empty(t *testing.T) {
+	for _, resp := range []schwabdev.PriceHistoryResponse{
+		{},
+		{Candles: []*schwabdev.Candle{nil, nil}},
+	} {
+		start, end := resp.TimeRange()
+		if !start.IsZero() || !end.IsZero() {
+			t.Errorf("want zero times, got %v / %v", start, end)
+		}
+	}
+}
+
+func TestPriceHistoryResponse_SortedCandles(t *testing.T) {
+	resp := schwabdev.PriceHistoryResponse{
+		Candles: []*schwabdev.Candle{
+			{Datetime: 1705708800000, Close: 2},
+			{Datetime: 1705622400000, Close: 1},
+			nil,
+			{Datetime: 1705795200000, Close: 3},
+		},
+	}
+	got := resp.SortedCandles()
+	if len(got) != 3 {
+		t.Fatalf("want 3 candles, got %d", len(got))
+	}
+	for i, c := range got {
+		if c.Close != float64(i+1) {
+			t.Errorf("candle %d: want close %d, got %+v", i, i+1, c)
+		}
+	}
+	if resp.Candles[0].Close != 2 {
+		t.Error("SortedCandles reordered the response")
+	}
+}
+
+func TestPriceHistoryResponse_Gaps(t *testing.T) {
+	base := time.Date(2026, 10, 15, 13, 30, 0, 0, time.UTC)
+	candle := func(minute int) *schwabdev.Candle {
+		return &schwabdev.Candle{Datetime: base.Add(time.Duration(minute) * time.Minute).UnixMilli()}
+	}
+	// Minutes 0-2, then 5 (missing 3-4), then 6, then 9 (missing 7-8), out of order.
+	resp := schwabdev.PriceHistoryResponse{
+		Candles: []*schwabdev.Candle{candle(5), candle(0), candle(9), candle(1), candle(2), candle(6)},
+	}
+
+	gaps := resp.Gaps(time.Minute)
+	want := []time.Time{base.Add(3 * time.Minute), base.Add(7 * time.Minute)}
+	if !slices.EqualFunc(gaps, want, time.Time.Equal) {
+		t.Errorf("Gaps = %v, want %v", gaps, want)
+	}
+	if gaps := resp.Gaps(5 * time.Minute); len(gaps) != 0 {
+		t.Errorf("no gaps wider than 5m expected, got %v", gaps)
+	}
+	if gaps := resp.Gaps(0); gaps != nil {
+		t.Errorf("Gaps(0) = %v, want nil", gaps)
+	}
+}
+
+func TestPriceHistoryResponse_WriteCSV(t *testing.T) {
+	resp := schwabdev.PriceHistoryResponse{
+		Symbol: "AAPL",
+		Candles: []*schwabdev.Candle{
+			{Open: 190.5, High: 191, Low: 190.25, Close: 190.75, Volume: 12000, Datetime: 1729000860000},
+			nil,
+			{Open: 190, High: 190.6, Low: 189.9, Close: 190.5, Volume: 15500, Datetime: 1729000800000},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := resp.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+	const golden = "datetime,open,high,low,close,volume\n" +
+		"2024-10-15T14:00:00Z,190,190.6,189.9,190.5,15500\n" +
+		"2024-10-15T14:01:00Z,190.5,191,190.25,190.75,12000\n"
+	if buf.String() != golden {
+		t.Errorf("WriteCSV =\n%s\nwant\n%s", buf.String(), golden)
+	}
+}
+
 // ── Movers ────────────────────────────────────────────────────────────────────
 
 func TestMoversResponse_RoundTrip(t *testing.T) {
@@ -716,6 +1184,47 @@ func TestMoversResponse_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestMoversResponse_SortAndTopN(t *testing.T) {
+	movers := schwabdev.MoversResponse{
+		{Symbol: "AMD", PercentChange: -1.75},
+		{Symbol: "NVDA", PercentChange: 4.08},
+		{Symbol: "INTC", PercentChange: -3.1},
+		{Symbol: "AAPL", PercentChange: 0.5},
+		{Symbol: "MSFT", PercentChange: 0.5},
+	}
+	symbols := func(ms []schwabdev.Mover) []string {
+		var out []string
+		for _, m := range ms {
+			out = append(out, m.Symbol)
+		}
+		return out
+	}
+
+	movers.SortByPercentChange(true)
+	if got, want := symbols(movers), []string{"NVDA", "AAPL", "MSFT", "AMD", "INTC"}; !slices.Equal(got, want) {
+		t.Errorf("descending = %v, want %v", got, want)
+	}
+	top := movers.TopN(2)
+	if got, want := symbols(top), []string{"NVDA", "AAPL"}; !slices.Equal(got, want) {
+		t.Errorf("TopN(2) = %v, want %v", got, want)
+	}
+	top[0].Symbol = "CHANGED"
+	if movers[0].Symbol != "NVDA" {
+		t.Error("TopN should return a copy")
+	}
+
+	movers.SortByPercentChange(false)
+	if got, want := symbols(movers.TopN(3)), []string{"INTC", "AMD", "AAPL"}; !slices.Equal(got, want) {
+		t.Errorf("ascending TopN(3) = %v, want %v", got, want)
+	}
+	if got := movers.TopN(10); len(got) != 5 {
+		t.Errorf("TopN(10) returned %d movers, want all 5", len(got))
+	}
+	if got := movers.TopN(0); got != nil {
+		t.Errorf("TopN(0) = %v, want nil", got)
+	}
+}
+
 // ── Market Hours ──────────────────────────────────────────────────────────────
 
 func TestMarketHoursResponse_RoundTrip(t *testing.T) {
@@ -789,6 +1298,85 @@ func TestMarketHour_Closed(t *testing.T) {
 	}
 }
 
+func TestMarketHour_IsOpenAt(t *testing.T) {
+	raw := `{
+		"date": "2024-01-15",
+		"isOpen": true,
+		"sessionHours": {
+			"preMarket": [{"start": "2024-01-15T07:00:00-05:00", "end": "2024-01-15T09:30:00-05:00"}],
+			"regularMarket": [{"start": "2024-01-15T09:30:00-05:00", "end": "2024-01-15T16:00:00-05:00"}],
+			"postMarket": [{"start": "2024-01-15T16:00:00-05:00", "end": "2024-01-15T20:00:00-05:00"}]
+		}
+	}`
+	mh := mustUnmarshal[schwabdev.MarketHour](t, raw)
+	est := time.FixedZone("EST", -5*3600)
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 15, h, m, 0, 0, est) }
+
+	tests := []struct {
+		name           string
+		t              time.Time
+		open, extended bool
+	}{
+		{"pre-market", at(8, 0), false, true},
+		{"just before open", at(9, 29), false, true},
+		{"at open", at(9, 30), true, true},
+		{"midday in UTC", at(12, 0).UTC(), true, true},
+		{"just before close", at(15, 59), true, true},
+		{"at close", at(16, 0), false, true},
+		{"after post-market", at(20, 0), false, false},
+		{"previous day", at(12, 0).AddDate(0, 0, -1), false, false},
+	}
+	for _, tt := range tests {
+		if got := mh.IsOpenAt(tt.t); got != tt.open {
+			t.Errorf("%s: IsOpenAt = %v, want %v", tt.name, got, tt.open)
+		}
+		if got := mh.IsExtendedOpenAt(tt.t); got != tt.extended {
+			t.Errorf("%s: IsExtendedOpenAt = %v, want %v", tt.name, got, tt.extended)
+		}
+	}
+
+	if next := mh.NextOpen(at(8, 0)); !next.Equal(at(9, 30)) {
+		t.Errorf("NextOpen before open: got %v", next)
+	}
+	if next := mh.NextOpen(at(9, 30)); !next.IsZero() {
+		t.Errorf("NextOpen after open: want zero, got %v", next)
+	}
+}
+
+func TestMarketHour_IsOpenAtBareTimes(t *testing.T) {
+	mh := schwabdev.MarketHour{
+		Date:   "2024-07-15",
+		IsOpen: true,
+		SessionHours: &schwabdev.SessionHours{
+			RegularMarket: []*schwabdev.StartEndTime{{Start: "09:30:00", End: "16:00:00"}},
+		},
+	}
+	// 13:30 UTC is 09:30 EDT.
+	if !mh.IsOpenAt(time.Date(2024, 7, 15, 13, 30, 0, 0, time.UTC)) {
+		t.Error("want open at 09:30 Eastern")
+	}
+	if mh.IsOpenAt(time.Date(2024, 7, 15, 13, 29, 0, 0, time.UTC)) {
+		t.Error("want closed at 09:29 Eastern")
+	}
+}
+
+func TestMarketHour_ClosedMarketNeverOpen(t *testing.T) {
+	mh := schwabdev.MarketHour{
+		Date:   "2024-01-15",
+		IsOpen: false,
+		SessionHours: &schwabdev.SessionHours{
+			RegularMarket: []*schwabdev.StartEndTime{{Start: "2024-01-15T09:30:00-05:00", End: "2024-01-15T16:00:00-05:00"}},
+		},
+	}
+	noon := time.Date(2024, 1, 15, 17, 0, 0, 0, time.UTC)
+	if mh.IsOpenAt(noon) || mh.IsExtendedOpenAt(noon) {
+		t.Error("closed market reported open")
+	}
+	if next := mh.NextOpen(noon.Add(-6 * time.Hour)); !next.IsZero() {
+		t.Errorf("NextOpen on closed market: want zero, got %v", next)
+	}
+}
+
 // ── Instruments ───────────────────────────────────────────────────────────────
 
 func TestInstrumentsResponse_RoundTrip(t *testing.T) {
@@ -805,6 +1393,35 @@ func TestInstrumentsResponse_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestInstrumentsResponse_Fundamental(t *testing.T) {
+	got := mustUnmarshal[schwabdev.InstrumentsResponse](t, `[
+		{"symbol":"AAPL","description":"Apple Inc","assetType":"EQUITY","cusip":"037833100","exchange":"NASDAQ",
+		 "fundamental":{"symbol":"AAPL","high52":237.23,"low52":164.075,"dividendYield":0.44,"peRatio":34.52,
+		                "eps":6.57,"marketCap":3486891547960,"pegRatio":112.56}},
+		{"symbol":"MSFT","description":"Microsoft Corp","assetType":"EQUITY","cusip":"594918104","exchange":"NASDAQ"}
+	]`)
+	if len(got) != 2 {
+		t.Fatalf("want 2 instruments, got %d", len(got))
+	}
+	f := got[0].Fundamental
+	if f == nil {
+		t.Fatal("AAPL fundamental not decoded")
+	}
+	want := schwabdev.InstrumentFundamental{
+		Symbol: "AAPL", PeRatio: 34.52, Eps: 6.57, DividendYield: 0.44,
+		MarketCap: 3486891547960, High52: 237.23, Low52: 164.075,
+	}
+	if *f != want {
+		t.Errorf("fundamental = %+v, want %+v", *f, want)
+	}
+	if got[1].Fundamental != nil {
+		t.Errorf("MSFT without a fundamental block: got %+v", got[1].Fundamental)
+	}
+	if out, _ := json.Marshal(got[1]); bytes.Contains(out, []byte("fundamental")) {
+		t.Errorf("nil fundamental marshalled: %s", out)
+	}
+}
+
 func TestInstrumentCUSIPResponse_RoundTrip(t *testing.T) {
 	input := schwabdev.InstrumentCUSIPResponse{
 		Instruments: []*schwabdev.InstrumentCUSIP{
@@ -860,11 +1477,13 @@ func TestPreferencesResponse_UnmarshalFromAPI(t *testing.T) {
 			}
 		]
 	}`
-	// Note: streamerSocketUrl vs streamerUrl — verify which tag the struct uses.
 	got := mustUnmarshal[schwabdev.PreferencesResponse](t, raw)
 	if len(got.StreamerInfo) != 1 {
 		t.Fatalf("want 1, got %d", len(got.StreamerInfo))
 	}
+	if info := got.StreamerInfo[0]; info.StreamerURL != "wss://streamer.schwab.com/ws" || info.SchwabClientCustomerID != "customer-xyz" {
+		t.Errorf("StreamerInfo: %+v", info)
+	}
 }
 
 // ── Order Requests (marshalling out to API) ───────────────────────────────────